@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/infrastructure/blobstore"
 	"golang-patterns/internal/infrastructure/logger"
 	"golang-patterns/internal/infrastructure/middleware"
 	"golang-patterns/internal/infrastructure/repositories"
+	"golang-patterns/internal/infrastructure/scheduler"
 	"golang-patterns/internal/interfaces/handlers"
 	"golang-patterns/internal/usecases"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -19,23 +24,84 @@ func main() {
 		port = "8080"
 	}
 
+	// Tenants are resolved from subdomains of this domain, or from X-Tenant-ID
+	// when the host names none
+	tenantBaseDomain := os.Getenv("TENANT_BASE_DOMAIN")
+
+	// Avatar thumbnails are stored on the local filesystem under this directory
+	avatarDir := os.Getenv("AVATAR_DIR")
+	if avatarDir == "" {
+		avatarDir = "./data/blobs"
+	}
+
 	// Initialize dependencies following clean architecture
 	// Infrastructure layer
 	userRepo := repositories.NewMemoryUserRepository()
-	logger := logger.NewConsoleLogger()
+	auditRepo := repositories.NewMemoryAuditRepository()
+	unitOfWork := repositories.NewMemoryUnitOfWork()
+	// LOG_FORMAT=json|text and LOG_LEVEL=debug|info|warn|error configure logging
+	logConfig, err := logger.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure logger: %v", err)
+	}
+	appLogger := logger.NewSlogLogger(logConfig)
+	blobStore, err := blobstore.NewLocalBlobStore(avatarDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize blob store: %v", err)
+	}
 
 	// Use case layer
-	userUseCase := usecases.NewUserUseCase(userRepo, logger)
+	userUseCase := usecases.NewUserUseCase(userRepo, auditRepo, unitOfWork, blobStore, appLogger)
+	lifecycleUseCase := usecases.NewLifecycleUseCase(userRepo, auditRepo, blobStore, appLogger)
+	avatarUseCase := usecases.NewAvatarUseCase(userRepo, blobStore, appLogger)
+
+	// Lifecycle jobs; LIFECYCLE_DRY_RUN=true makes scheduled runs report only
+	jobScheduler := scheduler.NewScheduler(scheduler.SystemClock{}, appLogger)
+	lifecycleDryRun := os.Getenv("LIFECYCLE_DRY_RUN") == "true"
+	lifecycleJobs := []scheduler.Job{
+		{
+			Name:        "deactivate-inactive-users",
+			Description: "Deactivate users with no login in 90 days",
+			Schedule:    "0 3 * * *",
+			DryRun:      lifecycleDryRun,
+			Run: func(ctx context.Context, now time.Time, tenantID string, dryRun bool) (*models.JobReport, error) {
+				return lifecycleUseCase.DeactivateInactiveUsers(ctx, now, tenantID, 90*24*time.Hour, dryRun)
+			},
+		},
+		{
+			Name:        "purge-deleted-users",
+			Description: "Permanently remove users soft-deleted more than 30 days ago",
+			Schedule:    "30 3 * * *",
+			DryRun:      lifecycleDryRun,
+			Run: func(ctx context.Context, now time.Time, tenantID string, dryRun bool) (*models.JobReport, error) {
+				return lifecycleUseCase.PurgeDeletedUsers(ctx, now, tenantID, 30*24*time.Hour, dryRun)
+			},
+		},
+	}
+	for _, job := range lifecycleJobs {
+		if err := jobScheduler.Register(job); err != nil {
+			log.Fatalf("Failed to register job %s: %v", job.Name, err)
+		}
+	}
+	jobScheduler.Start(context.Background())
 
 	// Interface layer (handlers)
 	userHandler := handlers.NewUserHandler(userUseCase)
+	jobHandler := handlers.NewJobHandler(jobScheduler)
+	avatarHandler := handlers.NewAvatarHandler(avatarUseCase)
+	userTableHandler := handlers.NewUserTableHandler(userUseCase)
 
 	// Setup routes
 	router := mux.NewRouter()
 
 	// Apply middleware
+	router.Use(middleware.RequestIDMiddleware)
 	router.Use(middleware.CORSMiddleware)
-	router.Use(middleware.LoggingMiddleware)
+	router.Use(middleware.LoggingMiddleware(appLogger))
+	router.Use(middleware.TenantMiddleware(tenantBaseDomain))
+
+	// Batch sub-requests are dispatched through the router, middleware included
+	batchHandler := handlers.NewBatchHandler(router, handlers.DefaultBatchConcurrency)
 
 	// API routes
 	api := router.PathPrefix("/api").Subrouter()
@@ -61,6 +127,10 @@ func main() {
 	api.HandleFunc("/users/stats/departments", userHandler.GetDepartmentStats).Methods("GET")
 	api.HandleFunc("/users/recent-signups", userHandler.GetRecentSignups).Methods("GET")
 
+	// === Duplicate detection and merge ===
+	api.HandleFunc("/users/duplicates", userHandler.FindDuplicateUsers).Methods("GET")
+	api.HandleFunc("/users/merge", userHandler.MergeUsers).Methods("POST")
+
 	// === Form processing - Bulk operations ===
 	api.HandleFunc("/users/bulk", userHandler.CreateUsersInBulk).Methods("POST")
 	api.HandleFunc("/users/bulk", userHandler.UpdateUsersInBulk).Methods("PUT")
@@ -72,6 +142,14 @@ func main() {
 	api.HandleFunc("/users/{id}/login", userHandler.UpdateLastLogin).Methods("POST")
 	api.HandleFunc("/users/{id}/summary", userHandler.GetUserSummary).Methods("GET")
 
+	// === Personal data requests ===
+	api.HandleFunc("/users/{id}/export", userHandler.ExportUserData).Methods("GET")
+	api.HandleFunc("/users/{id}/erase", userHandler.EraseUser).Methods("POST")
+
+	// === Avatars ===
+	api.HandleFunc("/users/{id}/avatar", avatarHandler.UploadAvatar).Methods("PUT")
+	api.HandleFunc("/users/{id}/avatar", avatarHandler.GetAvatar).Methods("GET")
+
 	// === Basic CRUD operations (generic {id} routes MUST be LAST) ===
 	api.HandleFunc("/users", userHandler.CreateUser).Methods("POST")
 	api.HandleFunc("/users", userHandler.GetAllUsers).Methods("GET")
@@ -79,19 +157,31 @@ func main() {
 	api.HandleFunc("/users/{id}", userHandler.UpdateUser).Methods("PUT")
 	api.HandleFunc("/users/{id}", userHandler.DeleteUser).Methods("DELETE")
 
+	// === Lifecycle jobs ===
+	api.HandleFunc("/jobs", jobHandler.ListJobs).Methods("GET")
+	api.HandleFunc("/jobs/runs", jobHandler.GetJobRuns).Methods("GET")
+	api.HandleFunc("/jobs/{name}/run", jobHandler.RunJob).Methods("POST")
+
+	// === Batch requests ===
+	api.HandleFunc("/batch", batchHandler.ExecuteBatch).Methods("POST")
+
+	// HTML view of the users, paged, sorted and filtered via the query string
+	router.Handle("/users", userTableHandler).Methods("GET")
+
 	// Health check endpoint
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		handlers.WriteJSONResponse(w, http.StatusOK, map[string]string{"status": "ok", "version": "enhanced"})
 	}).Methods("GET")
 
 	log.Printf("Enhanced server starting on port %s", port)
+	log.Printf("Tenant resolution: subdomain of %q, else %s header", tenantBaseDomain, middleware.TenantHeader)
 	log.Printf("Available endpoints:")
 	log.Printf("  Basic CRUD:")
 	log.Printf("    POST   /api/users                    - Create user")
 	log.Printf("    GET    /api/users                    - Get all users")
 	log.Printf("    GET    /api/users/{id}               - Get user by ID")
 	log.Printf("    PUT    /api/users/{id}               - Update user")
-	log.Printf("    DELETE /api/users/{id}               - Delete user")
+	log.Printf("    DELETE /api/users/{id}               - Soft-delete user (hidden now, purged after 30 days)")
 	log.Printf("  Target Specification:")
 	log.Printf("    GET    /api/users/email/{email}      - Get user by email")
 	log.Printf("    GET    /api/users/department/{dept}  - Get users by department")
@@ -107,15 +197,32 @@ func main() {
 	log.Printf("    GET    /api/users/stats              - User statistics")
 	log.Printf("    GET    /api/users/stats/departments  - Department statistics")
 	log.Printf("    GET    /api/users/recent-signups     - Recent signups")
+	log.Printf("  Duplicates:")
+	log.Printf("    GET    /api/users/duplicates         - Duplicate candidates")
+	log.Printf("    POST   /api/users/merge              - Merge duplicate users")
 	log.Printf("  Form Processing:")
 	log.Printf("    POST   /api/users/bulk               - Bulk create users")
 	log.Printf("    PUT    /api/users/bulk               - Bulk update users")
-	log.Printf("    DELETE /api/users/bulk               - Bulk delete users")
+	log.Printf("    DELETE /api/users/bulk               - Bulk soft-delete users (purged after 30 days)")
 	log.Printf("  Progressive Enhancement:")
 	log.Printf("    POST   /api/users/{id}/activate      - Activate user")
 	log.Printf("    POST   /api/users/{id}/deactivate    - Deactivate user")
 	log.Printf("    POST   /api/users/{id}/login         - Update last login")
 	log.Printf("    GET    /api/users/{id}/summary       - User summary")
+	log.Printf("  Personal Data:")
+	log.Printf("    GET    /api/users/{id}/export        - Export personal data")
+	log.Printf("    POST   /api/users/{id}/erase         - Erase personal data")
+	log.Printf("  Avatars:")
+	log.Printf("    PUT    /api/users/{id}/avatar        - Upload avatar (multipart field \"avatar\")")
+	log.Printf("    GET    /api/users/{id}/avatar        - Avatar thumbnail (?size=32|64|128|256)")
+	log.Printf("  Lifecycle Jobs:")
+	log.Printf("    GET    /api/jobs                     - List jobs and next runs")
+	log.Printf("    GET    /api/jobs/runs                - Job run history")
+	log.Printf("    POST   /api/jobs/{name}/run          - Trigger job (dry_run=true by default)")
+	log.Printf("  HTML:")
+	log.Printf("    GET    /users                        - User table (sort, filter, page)")
+	log.Printf("  Batch:")
+	log.Printf("    POST   /api/batch                    - Run up to %d API calls in one request", handlers.MaxBatchRequests)
 
 	log.Fatal(http.ListenAndServe(":"+port, router))
 }
\ No newline at end of file
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"golang-patterns/pkg/userclient"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// csvColumns are the columns understood by the CSV importer. Only name and
+// email are required; the header row may list columns in any order.
+var csvColumns = []string{"name", "email", "age", "department", "position"}
+
+// readImportFile reads user create requests from a CSV or JSON file.
+// "-" reads JSON from stdin.
+func readImportFile(path string, stdin io.Reader) ([]*userclient.UserCreateRequest, error) {
+	data, err := readFileOrStdin(path, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return parseCSV(data)
+	}
+
+	var reqs []*userclient.UserCreateRequest
+	if err := json.Unmarshal(data, &reqs); err != nil {
+		return nil, fmt.Errorf("invalid JSON import file: %w", err)
+	}
+	return reqs, nil
+}
+
+// parseCSV converts CSV rows into create requests using the header row
+func parseCSV(data []byte) ([]*userclient.UserCreateRequest, error) {
+	// Spreadsheet exports often start with a UTF-8 byte order mark
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV import file: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV import file is empty")
+	}
+
+	index := map[string]int{}
+	for i, header := range records[0] {
+		column := strings.ToLower(strings.TrimSpace(header))
+		known := false
+		for _, c := range csvColumns {
+			known = known || c == column
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown CSV column %q (expected %s)", header, strings.Join(csvColumns, ", "))
+		}
+		index[column] = i
+	}
+	for _, required := range []string{"name", "email"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", required)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		if i, ok := index[column]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	reqs := make([]*userclient.UserCreateRequest, 0, len(records)-1)
+	for line, record := range records[1:] {
+		req := &userclient.UserCreateRequest{
+			Name:       field(record, "name"),
+			Email:      field(record, "email"),
+			Department: field(record, "department"),
+			Position:   field(record, "position"),
+		}
+		if age := field(record, "age"); age != "" {
+			n, err := strconv.Atoi(age)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid age %q", line+2, age)
+			}
+			req.Age = n
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// readFileOrStdin reads path, or stdin when path is "-"
+func readFileOrStdin(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
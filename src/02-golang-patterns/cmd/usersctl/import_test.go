@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestParseCSV(t *testing.T) {
+	data := "\ufeffEmail, Name, age,department\n" +
+		"alice@example.com, Alice, 30, Engineering\n" +
+		"\"bob@example.com\",\"Bob, Jr.\",,Sales\n"
+
+	reqs, err := parseCSV([]byte(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(reqs) != 2 {
+		t.Fatalf("got %d requests, want 2", len(reqs))
+	}
+	if reqs[0].Name != "Alice" || reqs[0].Email != "alice@example.com" || reqs[0].Age != 30 || reqs[0].Department != "Engineering" {
+		t.Errorf("unexpected first request: %+v", reqs[0])
+	}
+	if reqs[1].Name != "Bob, Jr." || reqs[1].Age != 0 {
+		t.Errorf("unexpected second request: %+v", reqs[1])
+	}
+
+	errorCases := map[string]string{
+		"missing email column": "name\nAlice\n",
+		"unknown column":       "name,email,salary\nAlice,a@example.com,1\n",
+		"invalid age":          "name,email,age\nAlice,a@example.com,thirty\n",
+		"empty file":           "",
+	}
+	for name, input := range errorCases {
+		if _, err := parseCSV([]byte(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
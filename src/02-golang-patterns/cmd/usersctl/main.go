@@ -0,0 +1,394 @@
+// Command usersctl is an admin CLI for the users API built on pkg/userclient.
+//
+//	usersctl [global flags] <command> [flags] [args]
+//
+// Run "usersctl help" for the list of commands.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"golang-patterns/pkg/userclient"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// command is a usersctl subcommand
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, app *app, args []string) error
+}
+
+// app holds state shared by all commands
+type app struct {
+	client *userclient.Client
+	stdout io.Writer
+	stdin  io.Reader
+}
+
+// errUsage marks errors caused by invalid command-line arguments
+var errUsage = errors.New("usage error")
+
+var commands = []command{
+	{name: "list", usage: "list [-format table|json] [-name s] [-email s] [-sort field] [-order asc|desc]", summary: "List users", run: runList},
+	{name: "get", usage: "get <id>", summary: "Show one user", run: runGet},
+	{name: "search", usage: "search [-format table|json] <query>", summary: "Search users by name or email", run: runSearch},
+	{name: "import", usage: "import [-batch n] [-dry-run] <file.csv|file.json|->", summary: "Create users from CSV or JSON", run: runImport},
+	{name: "bulk-update", usage: "bulk-update <updates.json|->", summary: "Apply updates keyed by user ID", run: runBulkUpdate},
+	{name: "bulk-delete", usage: "bulk-delete [-file ids.txt] [id...]", summary: "Soft-delete users (purged after 30 days)", run: runBulkDelete},
+	{name: "activate", usage: "activate <id>...", summary: "Activate users", run: runSetActive(true)},
+	{name: "deactivate", usage: "deactivate <id>...", summary: "Deactivate users", run: runSetActive(false)},
+	{name: "stats", usage: "stats", summary: "Show user statistics", run: runStats},
+	{name: "jobs", usage: "jobs", summary: "List lifecycle jobs", run: runJobs},
+	{name: "run-job", usage: "run-job [-dry-run=false] <name>", summary: "Trigger a lifecycle job", run: runJob},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run parses global flags, dispatches to a command and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("usersctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	server := global.String("server", envOr("USERSCTL_SERVER", "http://localhost:8080"), "API base URL (env USERSCTL_SERVER)")
+	tenant := global.String("tenant", os.Getenv("USERSCTL_TENANT"), "tenant ID (env USERSCTL_TENANT)")
+	timeout := global.Duration("timeout", 30*time.Second, "overall timeout")
+	retries := global.Int("retries", 3, "retries for failed requests")
+	global.Usage = func() { printUsage(stderr, global) }
+
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	if global.NArg() == 0 || global.Arg(0) == "help" {
+		printUsage(stderr, global)
+		return 2
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == global.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "usersctl: unknown command %q\n", global.Arg(0))
+		printUsage(stderr, global)
+		return 2
+	}
+
+	opts := []userclient.Option{userclient.WithRetry(*retries, 200*time.Millisecond, 5*time.Second)}
+	if *tenant != "" {
+		opts = append(opts, userclient.WithTenant(*tenant))
+	}
+	client, err := userclient.New(*server, opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "usersctl: %v\n", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	a := &app{client: client, stdout: stdout, stdin: stdin}
+	if err := cmd.run(ctx, a, global.Args()[1:]); err != nil {
+		fmt.Fprintf(stderr, "usersctl %s: %v\n", cmd.name, err)
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(stderr, "usage: usersctl %s\n", cmd.usage)
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// printUsage lists global flags and commands
+func printUsage(w io.Writer, global *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: usersctl [global flags] <command> [flags] [args]")
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nGlobal flags:")
+	global.PrintDefaults()
+}
+
+// newFlagSet creates a command flag set whose errors are reported as usage errors
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses command flags, wrapping failures in errUsage
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return nil
+}
+
+// === Commands ===
+
+func runList(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("list")
+	format := fs.String("format", "table", "output format: table or json")
+	name := fs.String("name", "", "filter by name")
+	email := fs.String("email", "", "filter by email")
+	sortField := fs.String("sort", "", "sort field: id, name, email, created_at, updated_at")
+	sortOrder := fs.String("order", "", "sort order: asc or desc")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	opts := userclient.ListOptions{PageSize: 100, Name: *name, Email: *email, SortField: *sortField, SortOrder: *sortOrder}
+	users, err := collect(a.client.Users(ctx, opts))
+	if err != nil {
+		return err
+	}
+	return printUsers(a.stdout, *format, users)
+}
+
+func runGet(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: expected one user ID", errUsage)
+	}
+	user, err := a.client.GetUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printJSON(a.stdout, user)
+}
+
+func runSearch(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("search")
+	format := fs.String("format", "table", "output format: table or json")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: expected a query", errUsage)
+	}
+
+	users, err := collect(a.client.SearchAll(ctx, strings.Join(fs.Args(), " "), 100))
+	if err != nil {
+		return err
+	}
+	return printUsers(a.stdout, *format, users)
+}
+
+func runImport(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("import")
+	batch := fs.Int("batch", 50, "users per bulk request")
+	dryRun := fs.Bool("dry-run", false, "validate the file without creating users")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *batch < 1 {
+		return fmt.Errorf("%w: expected one file and a positive batch size", errUsage)
+	}
+
+	reqs, err := readImportFile(fs.Arg(0), a.stdin)
+	if err != nil {
+		return err
+	}
+	for i, req := range reqs {
+		if err := req.Validate(); err != nil {
+			return fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+	if *dryRun {
+		fmt.Fprintf(a.stdout, "%d users are valid\n", len(reqs))
+		return nil
+	}
+
+	created := 0
+	for start := 0; start < len(reqs); start += *batch {
+		end := min(start+*batch, len(reqs))
+		users, err := a.client.CreateUsersInBulk(ctx, reqs[start:end])
+		if err != nil {
+			return fmt.Errorf("records %d-%d (%d created before): %w", start+1, end, created, err)
+		}
+		created += len(users)
+	}
+	fmt.Fprintf(a.stdout, "%d users created\n", created)
+	return nil
+}
+
+func runBulkUpdate(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: expected one file", errUsage)
+	}
+
+	data, err := readFileOrStdin(args[0], a.stdin)
+	if err != nil {
+		return err
+	}
+	var updates map[string]*userclient.UserUpdateRequest
+	if err := json.Unmarshal(data, &updates); err != nil {
+		return fmt.Errorf("invalid updates file: %w", err)
+	}
+
+	users, err := a.client.UpdateUsersInBulk(ctx, updates)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "%d users updated\n", len(users))
+	return nil
+}
+
+func runBulkDelete(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("bulk-delete")
+	file := fs.String("file", "", "file with one user ID per line (- for stdin)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ids := fs.Args()
+	if *file != "" {
+		data, err := readFileOrStdin(*file, a.stdin)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, strings.Fields(string(data))...)
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: expected user IDs", errUsage)
+	}
+
+	if err := a.client.DeleteUsersInBulk(ctx, ids); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "%d users deleted\n", len(ids))
+	return nil
+}
+
+// runSetActive activates or deactivates each user, continuing past failures
+func runSetActive(active bool) func(ctx context.Context, a *app, args []string) error {
+	return func(ctx context.Context, a *app, args []string) error {
+		if len(args) == 0 {
+			return fmt.Errorf("%w: expected user IDs", errUsage)
+		}
+
+		var failed []error
+		for _, id := range args {
+			var err error
+			if active {
+				_, err = a.client.ActivateUser(ctx, id)
+			} else {
+				_, err = a.client.DeactivateUser(ctx, id)
+			}
+			if err != nil {
+				failed = append(failed, fmt.Errorf("%s: %w", id, err))
+				continue
+			}
+			fmt.Fprintln(a.stdout, id)
+		}
+		return errors.Join(failed...)
+	}
+}
+
+func runStats(ctx context.Context, a *app, args []string) error {
+	stats, err := a.client.GetUserStats(ctx)
+	if err != nil {
+		return err
+	}
+	return printJSON(a.stdout, stats)
+}
+
+func runJobs(ctx context.Context, a *app, args []string) error {
+	jobs, err := a.client.ListJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSCHEDULE\tDRY RUN\tRUNNING\tNEXT RUN")
+	for _, job := range jobs {
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%t\t%s\n", job.Name, job.Schedule, job.DryRun, job.Running, job.NextRun.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func runJob(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("run-job")
+	dryRun := fs.Bool("dry-run", true, "report affected users without changing them")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("%w: expected a job name", errUsage)
+	}
+
+	run, err := a.client.RunJob(ctx, fs.Arg(0), *dryRun)
+	if run != nil {
+		if printErr := printJSON(a.stdout, run); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+// === Output helpers ===
+
+// collect drains a user iterator
+func collect(seq func(yield func(*userclient.User, error) bool)) ([]*userclient.User, error) {
+	var users []*userclient.User
+	for user, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// printUsers writes users as an aligned table or as JSON
+func printUsers(w io.Writer, format string, users []*userclient.User) error {
+	switch format {
+	case "json":
+		if users == nil {
+			users = []*userclient.User{}
+		}
+		return printJSON(w, users)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tEMAIL\tDEPARTMENT\tACTIVE")
+		for _, u := range users {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", u.ID, u.Name, u.Email, u.Department, strconv.FormatBool(u.IsActive))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("%w: unknown format %q", errUsage, format)
+	}
+}
+
+// printJSON writes v as indented JSON
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// envOr returns the environment variable or a fallback
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// AuditAction identifies the kind of event recorded in the audit trail
+type AuditAction string
+
+// Audit actions recorded for users
+const (
+	AuditActionUserCreated  AuditAction = "user.created"
+	AuditActionUserUpdated  AuditAction = "user.updated"
+	AuditActionUserDeleted  AuditAction = "user.deleted"
+	AuditActionUserLogin    AuditAction = "user.login"
+	AuditActionUserExported AuditAction = "user.exported"
+	AuditActionUserErased   AuditAction = "user.erased"
+	AuditActionUserMerged   AuditAction = "user.merged"
+	AuditActionUserPurged   AuditAction = "user.purged"
+)
+
+// AuditEntry represents a single event in a user's audit trail.
+// Details must never contain personal data so that entries can be kept
+// after the user has been erased.
+type AuditEntry struct {
+	ID         string            `json:"id"`
+	TenantID   string            `json:"tenant_id,omitempty"`
+	UserID     string            `json:"user_id"`
+	Action     AuditAction       `json:"action"`
+	Details    map[string]string `json:"details,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// NewAuditEntry creates an audit entry for the given user
+func NewAuditEntry(userID string, action AuditAction, details map[string]string) *AuditEntry {
+	return &AuditEntry{
+		UserID:     userID,
+		Action:     action,
+		Details:    details,
+		OccurredAt: time.Now(),
+	}
+}
+
+// PersonalDataExportVersion is the format version of PersonalDataExport
+const PersonalDataExportVersion = "1"
+
+// PersonalDataExport is the machine-readable bundle returned for a data
+// subject access request
+type PersonalDataExport struct {
+	Version      string        `json:"version"`
+	ExportedAt   time.Time     `json:"exported_at"`
+	User         *User         `json:"user"`
+	LoginHistory []time.Time   `json:"login_history"`
+	AuditTrail   []*AuditEntry `json:"audit_trail"`
+}
+
+// IsErased reports whether the user's personal data has been erased
+func (u *User) IsErased() bool {
+	return u.ErasedAt != nil
+}
+
+// Pseudonymize irreversibly replaces the user's name and email with random
+// values and drops the profile image. Fields used by aggregate statistics
+// (department, position, age, activity and timestamps) are kept intact.
+func (u *User) Pseudonymize() error {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(buf)
+
+	now := time.Now()
+	u.Name = "Erased User " + strings.ToUpper(token[:8])
+	u.Email = "erased-" + token + "@erased.invalid"
+	u.Avatar = nil
+	u.ErasedAt = &now
+	u.UpdatedAt = now
+	return nil
+}
+
+// ChangedFields lists the fields an update request touches, without values
+func (req *UserUpdateRequest) ChangedFields() string {
+	var fields []string
+	if req.Name != nil {
+		fields = append(fields, "name")
+	}
+	if req.Email != nil {
+		fields = append(fields, "email")
+	}
+	if req.Age != nil {
+		fields = append(fields, "age")
+	}
+	if req.Department != nil {
+		fields = append(fields, "department")
+	}
+	if req.Position != nil {
+		fields = append(fields, "position")
+	}
+	if req.IsActive != nil {
+		fields = append(fields, "is_active")
+	}
+	return strings.Join(fields, ",")
+}
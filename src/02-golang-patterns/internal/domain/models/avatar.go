@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Avatar upload limits
+const (
+	MaxAvatarUploadBytes = 5 << 20
+	MaxAvatarDimension   = 4096
+	DefaultAvatarSize    = 128
+)
+
+// AvatarSizes lists the square thumbnail sizes generated for every upload
+var AvatarSizes = []int{32, 64, 128, 256}
+
+// Avatar errors
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	ErrAvatarNotFound   = errors.New("avatar not found")
+)
+
+// Avatar describes the processed profile image of a user.
+// Version is derived from the uploaded content and changes on every new image.
+type Avatar struct {
+	Version     string    `json:"version"`
+	ContentType string    `json:"content_type"`
+	Sizes       []int     `json:"sizes"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// AvatarImage is a single stored thumbnail ready to be served
+type AvatarImage struct {
+	Data        []byte
+	ContentType string
+	Version     string
+	ETag        string
+	UpdatedAt   time.Time
+}
+
+// HasSize reports whether a thumbnail of the given size was generated
+func (a *Avatar) HasSize(size int) bool {
+	for _, s := range a.Sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// Extension returns the file extension of the stored thumbnails
+func (a *Avatar) Extension() string {
+	if a.ContentType == "image/jpeg" {
+		return "jpg"
+	}
+	return "png"
+}
+
+// BlobKey returns the blob store key of one thumbnail
+func (a *Avatar) BlobKey(tenantID, userID string, size int) string {
+	return fmt.Sprintf("avatars/%s/%s/%s/%d.%s", tenantID, userID, a.Version, size, a.Extension())
+}
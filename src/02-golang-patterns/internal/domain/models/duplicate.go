@@ -0,0 +1,342 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Weights used to score duplicate candidates. A pair needs either a
+// matching normalized email or a near-identical name in the same
+// department to reach DefaultDuplicateThreshold.
+const (
+	duplicateEmailWeight      = 0.5
+	duplicateNameWeight       = 0.4
+	duplicateDepartmentWeight = 0.1
+
+	// DefaultDuplicateThreshold is the minimum score reported as a candidate
+	DefaultDuplicateThreshold = 0.5
+
+	// minNameSimilarity is the similarity below which names count as different
+	minNameSimilarity = 0.85
+)
+
+// DuplicateCandidate is a pair of users that probably describe the same person
+type DuplicateCandidate struct {
+	UserA   *User    `json:"user_a"`
+	UserB   *User    `json:"user_b"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// MergeUsersRequest represents a request to merge a duplicate into a survivor.
+// Fields maps a field name (name, email, age, department, position) to the
+// side whose value survives: "survivor" or "loser".
+type MergeUsersRequest struct {
+	SurvivorID string            `json:"survivor_id"`
+	LoserID    string            `json:"loser_id"`
+	Fields     map[string]string `json:"fields,omitempty"`
+}
+
+// Validate validates the merge request
+func (req *MergeUsersRequest) Validate() error {
+	if req.SurvivorID == "" || req.LoserID == "" {
+		return NewValidationError("survivor_id and loser_id are required")
+	}
+	if req.SurvivorID == req.LoserID {
+		return NewValidationError("a user cannot be merged into itself")
+	}
+	for field, side := range req.Fields {
+		switch field {
+		case "name", "email", "age", "department", "position":
+		default:
+			return NewFieldValidationError("fields", "unsupported field: "+field)
+		}
+		if side != "survivor" && side != "loser" {
+			return NewFieldValidationError("fields", "side must be 'survivor' or 'loser' for field "+field)
+		}
+	}
+	return nil
+}
+
+// MergeUsers returns the survivor with field values resolved against the loser.
+// Explicit choices in req.Fields win; otherwise the survivor's value is kept
+// unless it is empty. Activity and timestamps are combined.
+func MergeUsers(survivor, loser *User, req *MergeUsersRequest) *User {
+	merged := *survivor
+	useLoser := func(field string, survivorEmpty bool) bool {
+		if side, ok := req.Fields[field]; ok {
+			return side == "loser"
+		}
+		return survivorEmpty
+	}
+
+	if useLoser("name", strings.TrimSpace(survivor.Name) == "") {
+		merged.Name = loser.Name
+	}
+	if useLoser("email", strings.TrimSpace(survivor.Email) == "") {
+		merged.Email = loser.Email
+	}
+	if useLoser("age", survivor.Age == 0) {
+		merged.Age = loser.Age
+	}
+	if useLoser("department", survivor.Department == "") {
+		merged.Department = loser.Department
+	}
+	if useLoser("position", survivor.Position == "") {
+		merged.Position = loser.Position
+	}
+
+	merged.IsActive = survivor.IsActive || loser.IsActive
+	if loser.CreatedAt.Before(merged.CreatedAt) {
+		merged.CreatedAt = loser.CreatedAt
+	}
+	if loser.LastLoginAt != nil && (merged.LastLoginAt == nil || loser.LastLoginAt.After(*merged.LastLoginAt)) {
+		lastLogin := *loser.LastLoginAt
+		merged.LastLoginAt = &lastLogin
+	}
+
+	return &merged
+}
+
+// ScoreDuplicate scores how likely two users describe the same person.
+// It returns a score between 0 and 1 and the reasons contributing to it.
+func ScoreDuplicate(a, b *User) (float64, []string) {
+	var score float64
+	reasons := []string{}
+
+	if NormalizeEmail(a.Email) == NormalizeEmail(b.Email) {
+		score += duplicateEmailWeight
+		reasons = append(reasons, "normalized_email_match")
+	}
+
+	if similarity := NameSimilarity(a.Name, b.Name); similarity >= minNameSimilarity {
+		score += duplicateNameWeight * similarity
+		reasons = append(reasons, "similar_name")
+	}
+
+	if a.Department != "" && strings.EqualFold(strings.TrimSpace(a.Department), strings.TrimSpace(b.Department)) {
+		score += duplicateDepartmentWeight
+		reasons = append(reasons, "same_department")
+	}
+
+	return score, reasons
+}
+
+// NormalizeEmail lowercases an email, strips "+tag" suffixes and removes
+// the dots Gmail ignores in the local part
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(foldWidth(email)))
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+
+	local, domain := email[:at], email[at+1:]
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+
+	return local + "@" + domain
+}
+
+// NormalizeName converts a name into sorted, romanized, lowercase tokens so
+// that "Tanaka Taro", "taro tanaka" and "田中 太郎" normalize identically
+func NormalizeName(name string) string {
+	name = strings.ToLower(foldWidth(name))
+
+	var tokens []string
+	for _, token := range strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '・' || r == ',' || r == '.'
+	}) {
+		for _, part := range romanize(token) {
+			tokens = append(tokens, normalizeRomaji(part))
+		}
+	}
+
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// NameSimilarity compares two names after normalization using Jaro-Winkler
+func NameSimilarity(a, b string) float64 {
+	return jaroWinkler(NormalizeName(a), NormalizeName(b))
+}
+
+// nameReadings maps common Japanese name kanji to their romanized readings
+var nameReadings = map[string]string{
+	// Surnames
+	"佐藤": "sato", "鈴木": "suzuki", "高橋": "takahashi", "田中": "tanaka", "伊藤": "ito",
+	"渡辺": "watanabe", "山本": "yamamoto", "中村": "nakamura", "小林": "kobayashi", "加藤": "kato",
+	"吉田": "yoshida", "山田": "yamada", "佐々木": "sasaki", "山口": "yamaguchi", "松本": "matsumoto",
+	"井上": "inoue", "木村": "kimura", "林": "hayashi", "斎藤": "saito", "清水": "shimizu",
+	// Given names
+	"太郎": "taro", "次郎": "jiro", "一郎": "ichiro", "花子": "hanako", "健太": "kenta",
+	"翔太": "shota", "大輔": "daisuke", "美咲": "misaki", "陽菜": "hina", "直樹": "naoki",
+}
+
+// romanize replaces known kanji readings in a token, splitting unspaced
+// names such as "田中太郎" by longest dictionary match
+func romanize(token string) []string {
+	runes := []rune(token)
+	var parts []string
+	var pending []rune
+
+	for i := 0; i < len(runes); {
+		matched := false
+		for j := len(runes); j > i; j-- {
+			if reading, ok := nameReadings[string(runes[i:j])]; ok {
+				if len(pending) > 0 {
+					parts = append(parts, string(pending))
+					pending = nil
+				}
+				parts = append(parts, reading)
+				i = j
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			pending = append(pending, runes[i])
+			i++
+		}
+	}
+	if len(pending) > 0 {
+		parts = append(parts, string(pending))
+	}
+
+	return parts
+}
+
+// longVowels are the romaji spellings of long vowels and their short vowel
+var longVowels = []struct{ spelling, vowel string }{
+	{"ou", "o"}, {"oo", "o"}, {"oh", "o"}, {"uu", "u"},
+}
+
+// normalizeRomaji folds common long-vowel spellings ("Satou", "Satō",
+// "Ohno"). Spellings are only folded where they end a syllable, i.e. at
+// the end of the token or before the next syllable, so that names such
+// as "John" or "Louis" are left alone.
+func normalizeRomaji(s string) string {
+	s = strings.NewReplacer("ō", "o", "ô", "o", "ū", "u", "û", "u").Replace(s)
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		folded := false
+		for _, lv := range longVowels {
+			if strings.HasPrefix(s[i:], lv.spelling) && endsSyllable(s[i+len(lv.spelling):]) {
+				b.WriteString(lv.vowel)
+				i += len(lv.spelling)
+				folded = true
+				break
+			}
+		}
+		if !folded {
+			b.WriteByte(s[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// endsSyllable reports whether a long vowel followed by rest ends its
+// syllable: rest is empty or starts with a consonant, optionally followed
+// by h, s or y ("sh", "ts", "ky"), and a vowel
+func endsSyllable(rest string) bool {
+	if rest == "" {
+		return true
+	}
+	if len(rest) < 2 || !isConsonant(rest[0]) {
+		return false
+	}
+	next := rest[1]
+	if (next == 'h' || next == 's' || next == 'y') && len(rest) > 2 {
+		next = rest[2]
+	}
+	return isVowel(next)
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
+
+func isConsonant(c byte) bool {
+	return c >= 'a' && c <= 'z' && !isVowel(c)
+}
+
+// foldWidth converts full-width ASCII characters and spaces to half-width
+func foldWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '　':
+			return ' '
+		case r >= '！' && r <= '～':
+			return r - 0xFEE0
+		}
+		return r
+	}, s)
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings (0..1)
+func jaroWinkler(a, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 && len(s2) == 0 {
+		return 1
+	}
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	matchDistance := max(len(s1), len(s2))/2 - 1
+	if matchDistance < 0 {
+		matchDistance = 0
+	}
+
+	s1Matches := make([]bool, len(s1))
+	s2Matches := make([]bool, len(s2))
+	matches := 0
+	for i := range s1 {
+		start := max(0, i-matchDistance)
+		end := min(len(s2), i+matchDistance+1)
+		for j := start; j < end; j++ {
+			if s2Matches[j] || s1[i] != s2[j] {
+				continue
+			}
+			s1Matches[i], s2Matches[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	k := 0
+	for i := range s1 {
+		if !s1Matches[i] {
+			continue
+		}
+		for !s2Matches[k] {
+			k++
+		}
+		if s1[i] != s2[k] {
+			transpositions++
+		}
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for i := 0; i < min(4, len(s1), len(s2)) && s1[i] == s2[i]; i++ {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
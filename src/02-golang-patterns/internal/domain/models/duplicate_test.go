@@ -0,0 +1,98 @@
+package models
+
+import "testing"
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"Taro.Tanaka@Gmail.com", "tarotanaka@gmail.com"},
+		{"t.a.r.o.tanaka+work@googlemail.com", "tarotanaka@gmail.com"},
+		{"taro.tanaka+news@example.com", "taro.tanaka@example.com"},
+		{"ＴＡＲＯ＠example.com", "taro@example.com"},
+		{"not-an-email", "not-an-email"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeEmail(tt.email); got != tt.want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		similar bool
+	}{
+		{"Tanaka Taro", "田中 太郎", true},
+		{"Tanaka Taro", "田中太郎", true},
+		{"Taro Tanaka", "tanaka　taro", true},
+		{"Sato Hanako", "Satou Hanako", true},
+		{"Ohno Kouhei", "Ono Kohei", true},
+		{"Satoh Yuuki", "Sato Yuki", true},
+		{"Sato Hanako", "Suzuki Ichiro", false},
+		{"Tanaka Taro", "Tanaka Hanako", false},
+	}
+
+	for _, tt := range tests {
+		similarity := NameSimilarity(tt.a, tt.b)
+		if got := similarity >= minNameSimilarity; got != tt.similar {
+			t.Errorf("NameSimilarity(%q, %q) = %.2f, similar=%v want %v", tt.a, tt.b, similarity, got, tt.similar)
+		}
+	}
+}
+
+func TestNormalizeName_ForeignNamesKeepTheirVowels(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"John Smith", "john smith"},
+		{"Louis Cohn", "cohn louis"},
+		{"Douglas Brown", "brown douglas"},
+		{"Satou Ohta", "ota sato"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScoreDuplicate(t *testing.T) {
+	romaji := &User{Name: "Tanaka Taro", Email: "taro.tanaka@gmail.com", Department: "Engineering"}
+	kanji := &User{Name: "田中 太郎", Email: "tarotanaka+jp@gmail.com", Department: "engineering"}
+	other := &User{Name: "Suzuki Ichiro", Email: "suzuki@example.com", Department: "Engineering"}
+
+	score, reasons := ScoreDuplicate(romaji, kanji)
+	if score < 0.99 || len(reasons) != 3 {
+		t.Errorf("romaji/kanji pair scored %.2f %v, want ~1.0 with 3 reasons", score, reasons)
+	}
+
+	if score, _ := ScoreDuplicate(romaji, other); score >= DefaultDuplicateThreshold {
+		t.Errorf("unrelated pair scored %.2f", score)
+	}
+}
+
+func TestMergeUsers(t *testing.T) {
+	survivor := &User{ID: "user_1", Name: "Tanaka Taro", Email: "taro@example.com", IsActive: false}
+	loser := &User{ID: "user_2", Name: "田中 太郎", Email: "tanaka@example.com", Age: 30, Department: "Sales", IsActive: true}
+
+	merged := MergeUsers(survivor, loser, &MergeUsersRequest{Fields: map[string]string{"email": "loser"}})
+
+	if merged.ID != "user_1" || merged.Name != "Tanaka Taro" {
+		t.Errorf("survivor identity not kept: %+v", merged)
+	}
+	if merged.Email != "tanaka@example.com" {
+		t.Errorf("explicit field choice ignored: email=%s", merged.Email)
+	}
+	if merged.Age != 30 || merged.Department != "Sales" {
+		t.Errorf("empty survivor fields not filled from loser: %+v", merged)
+	}
+	if !merged.IsActive {
+		t.Error("merged user should be active when either side is active")
+	}
+}
@@ -7,6 +7,9 @@ var (
 	ErrUserNotFound     = errors.New("user not found")
 	ErrInvalidUserName  = errors.New("invalid user name")
 	ErrInvalidUserEmail = errors.New("invalid user email")
+	ErrUserErased       = errors.New("user has been erased")
+	ErrJobRunning       = errors.New("job is already running")
+	ErrConcurrentUpdate = errors.New("data was changed concurrently")
 )
 
 // ValidationError represents a validation error
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Job run triggers
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// AffectedUser describes a user acted upon (or that would be, in a dry run)
+// by a lifecycle job
+type AffectedUser struct {
+	TenantID string `json:"tenant_id"`
+	UserID   string `json:"user_id"`
+	Action   string `json:"action"`
+	Reason   string `json:"reason"`
+}
+
+// JobReport is the outcome of a single lifecycle policy execution
+type JobReport struct {
+	DryRun   bool           `json:"dry_run"`
+	Affected []AffectedUser `json:"affected"`
+}
+
+// JobRun records one execution of a scheduled job. Manual runs are
+// limited to the tenant in TenantID; scheduled runs cover every tenant and
+// leave it empty.
+type JobRun struct {
+	ID         string     `json:"id"`
+	Job        string     `json:"job"`
+	Trigger    string     `json:"trigger"`
+	TenantID   string     `json:"tenant_id,omitempty"`
+	DryRun     bool       `json:"dry_run"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at"`
+	Report     *JobReport `json:"report,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// JobInfo describes a registered job
+type JobInfo struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Schedule    string    `json:"schedule"`
+	DryRun      bool      `json:"dry_run"`
+	NextRun     time.Time `json:"next_run"`
+	Running     bool      `json:"running"`
+}
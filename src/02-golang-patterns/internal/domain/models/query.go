@@ -35,6 +35,9 @@ func (f *UserFilter) Matches(user *User) bool {
 	if !f.CreatedAt.IsZero() && user.CreatedAt.Before(f.CreatedAt) {
 		return false
 	}
+	if f.IsActive != nil && user.IsActive != *f.IsActive {
+		return false
+	}
 	return true
 }
 
@@ -45,11 +48,18 @@ type PaginationParams struct {
 	Offset   int `json:"offset"`
 }
 
+// MaxPage is the highest page that can be requested, so that the offset
+// of a page cannot overflow
+const MaxPage = 1000000
+
 // NewPaginationParams creates pagination parameters with validation
 func NewPaginationParams(page, pageSize int) *PaginationParams {
 	if page < 1 {
 		page = 1
 	}
+	if page > MaxPage {
+		page = MaxPage
+	}
 	if pageSize < 1 || pageSize > 100 {
 		pageSize = 10
 	}
@@ -66,9 +76,15 @@ func (p *PaginationParams) Validate() error {
 	if p.Page < 1 {
 		return NewValidationError("page must be greater than 0")
 	}
+	if p.Page > MaxPage {
+		return NewValidationError(fmt.Sprintf("page must not be greater than %d", MaxPage))
+	}
 	if p.PageSize < 1 || p.PageSize > 100 {
 		return NewValidationError("page_size must be between 1 and 100")
 	}
+	if p.Offset < 0 {
+		return NewValidationError("offset must not be negative")
+	}
 	return nil
 }
 
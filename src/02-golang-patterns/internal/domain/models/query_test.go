@@ -0,0 +1,42 @@
+package models
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewPaginationParams(t *testing.T) {
+	tests := []struct {
+		page, pageSize int
+		want           PaginationParams
+	}{
+		{2, 20, PaginationParams{Page: 2, PageSize: 20, Offset: 20}},
+		{0, 0, PaginationParams{Page: 1, PageSize: 10, Offset: 0}},
+		{math.MaxInt, 100, PaginationParams{Page: MaxPage, PageSize: 100, Offset: (MaxPage - 1) * 100}},
+	}
+
+	for _, tt := range tests {
+		got := NewPaginationParams(tt.page, tt.pageSize)
+		if *got != tt.want {
+			t.Errorf("NewPaginationParams(%d, %d) = %+v, want %+v", tt.page, tt.pageSize, *got, tt.want)
+		}
+		if err := got.Validate(); err != nil {
+			t.Errorf("NewPaginationParams(%d, %d) invalid: %v", tt.page, tt.pageSize, err)
+		}
+	}
+}
+
+func TestPaginationParams_Validate(t *testing.T) {
+	invalid := []PaginationParams{
+		{Page: 0, PageSize: 10},
+		{Page: MaxPage + 1, PageSize: 10},
+		{Page: 1, PageSize: 101},
+		{Page: 1, PageSize: 10, Offset: -10},
+	}
+
+	for _, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%+v passed validation", p)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"context"
+	"regexp"
+)
+
+// DefaultTenantID is used when a request does not identify a tenant
+const DefaultTenantID = "default"
+
+// tenantIDPattern restricts tenant IDs to DNS-label-like values so they can
+// be used as subdomains as well as header values
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type tenantContextKey struct{}
+
+// ValidateTenantID validates a tenant identifier
+func ValidateTenantID(tenantID string) error {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return NewFieldValidationError("tenant_id", "must be 1-63 lowercase letters, digits or hyphens")
+	}
+	return nil
+}
+
+// WithTenant returns a copy of ctx carrying the given tenant ID
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ID carried by ctx, falling back to
+// DefaultTenantID so single-tenant deployments keep working unchanged
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenantID
+}
@@ -6,18 +6,25 @@ import (
 	"time"
 )
 
-// User represents a user in the system
+// User represents a user in the system. Version counts the writes of the
+// stored user; writing back a user read before the last write fails with
+// ErrConcurrentUpdate.
 type User struct {
 	ID          string     `json:"id"`
+	TenantID    string     `json:"tenant_id,omitempty"`
 	Name        string     `json:"name"`
 	Email       string     `json:"email"`
 	Age         int        `json:"age,omitempty"`
 	Department  string     `json:"department,omitempty"`
 	Position    string     `json:"position,omitempty"`
 	IsActive    bool       `json:"is_active"`
+	Avatar      *Avatar    `json:"avatar,omitempty"`
 	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	ErasedAt    *time.Time `json:"erased_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+	Version     int64      `json:"version"`
 }
 
 // UserCreateRequest represents a request to create a user
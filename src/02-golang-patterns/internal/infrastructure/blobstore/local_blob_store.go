@@ -0,0 +1,99 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-patterns/internal/interfaces/repositories"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalBlobStore implements BlobStore on the local filesystem.
+// The content type is derived from the key's file extension.
+type LocalBlobStore struct {
+	baseDir string
+}
+
+// NewLocalBlobStore creates a blob store rooted at baseDir
+func NewLocalBlobStore(baseDir string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &LocalBlobStore{baseDir: baseDir}, nil
+}
+
+// Put writes a blob atomically by renaming a temporary file into place
+func (s *LocalBlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	filePath, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// Get reads a blob
+func (s *LocalBlobStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	filePath, err := s.resolve(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", repositories.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return data, contentType, nil
+}
+
+// Delete removes a blob; deleting a missing blob is not an error
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	filePath, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
+
+// resolve maps a key to a path inside baseDir, rejecting traversal
+func (s *LocalBlobStore) resolve(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.baseDir, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,44 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"golang-patterns/internal/interfaces/repositories"
+	"testing"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	store, err := NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "avatars/acme/user_1/abc/64.png", []byte("png-bytes"), "image/png"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	data, contentType, err := store.Get(ctx, "avatars/acme/user_1/abc/64.png")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if string(data) != "png-bytes" || contentType != "image/png" {
+		t.Errorf("got %q (%s)", data, contentType)
+	}
+
+	if err := store.Delete(ctx, "avatars/acme/user_1/abc/64.png"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, _, err := store.Get(ctx, "avatars/acme/user_1/abc/64.png"); !errors.Is(err, repositories.ErrBlobNotFound) {
+		t.Errorf("err = %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Delete(ctx, "avatars/acme/user_1/abc/64.png"); err != nil {
+		t.Errorf("deleting a missing blob should succeed: %v", err)
+	}
+
+	for _, key := range []string{"", "/", "../outside.png", "avatars/../../outside.png"} {
+		if err := store.Put(ctx, key, []byte("x"), "image/png"); err == nil {
+			t.Errorf("key %q should be rejected", key)
+		}
+	}
+}
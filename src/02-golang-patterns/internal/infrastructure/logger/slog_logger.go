@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Log output formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// redactedValue replaces the value of sensitive fields
+const redactedValue = "[REDACTED]"
+
+// defaultRedactKeys are always masked. Email fields keep their domain so
+// logs stay useful for debugging without identifying the user.
+var defaultRedactKeys = []string{"email", "password", "token", "secret", "authorization"}
+
+// emailPattern finds email addresses embedded in free-form values such as errors
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// Config configures SlogLogger
+type Config struct {
+	Format     string
+	Level      slog.Level
+	Output     io.Writer
+	RedactKeys []string
+}
+
+// ConfigFromEnv reads LOG_FORMAT (json|text, default json) and LOG_LEVEL
+// (debug|info|warn|error, default info)
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{Format: FormatJSON, Level: slog.LevelInfo, Output: os.Stdout}
+
+	if format := strings.ToLower(os.Getenv("LOG_FORMAT")); format != "" {
+		if format != FormatJSON && format != FormatText {
+			return cfg, fmt.Errorf("invalid LOG_FORMAT %q: must be json or text", format)
+		}
+		cfg.Format = format
+	}
+
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		if err := cfg.Level.UnmarshalText([]byte(level)); err != nil {
+			return cfg, fmt.Errorf("invalid LOG_LEVEL %q: %w", level, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// SlogLogger implements Logger on top of log/slog
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a structured logger. Every record logged with a
+// context carrying a request ID gets a request_id attribute.
+func NewSlogLogger(cfg Config) *SlogLogger {
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+
+	redactKeys := make(map[string]bool)
+	for _, key := range append(defaultRedactKeys, cfg.RedactKeys...) {
+		redactKeys[strings.ToLower(key)] = true
+	}
+
+	opts := &slog.HandlerOptions{
+		Level: cfg.Level,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			return redactAttr(redactKeys, attr)
+		},
+	}
+
+	var handler slog.Handler
+	if cfg.Format == FormatText {
+		handler = slog.NewTextHandler(cfg.Output, opts)
+	} else {
+		handler = slog.NewJSONHandler(cfg.Output, opts)
+	}
+
+	return &SlogLogger{logger: slog.New(contextHandler{handler})}
+}
+
+// Info logs an info message
+func (l *SlogLogger) Info(msg string, fields ...interface{}) {
+	l.logger.Info(msg, fields...)
+}
+
+// Error logs an error message
+func (l *SlogLogger) Error(msg string, fields ...interface{}) {
+	l.logger.Error(msg, fields...)
+}
+
+// Debug logs a debug message
+func (l *SlogLogger) Debug(msg string, fields ...interface{}) {
+	l.logger.Debug(msg, fields...)
+}
+
+// InfoContext logs an info message with request-scoped attributes from ctx
+func (l *SlogLogger) InfoContext(ctx context.Context, msg string, fields ...interface{}) {
+	l.logger.InfoContext(ctx, msg, fields...)
+}
+
+// ErrorContext logs an error message with request-scoped attributes from ctx
+func (l *SlogLogger) ErrorContext(ctx context.Context, msg string, fields ...interface{}) {
+	l.logger.ErrorContext(ctx, msg, fields...)
+}
+
+// DebugContext logs a debug message with request-scoped attributes from ctx
+func (l *SlogLogger) DebugContext(ctx context.Context, msg string, fields ...interface{}) {
+	l.logger.DebugContext(ctx, msg, fields...)
+}
+
+// contextHandler adds the request ID carried by the context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// redactAttr masks sensitive keys and email addresses inside string and error values
+func redactAttr(redactKeys map[string]bool, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	if redactKeys[key] {
+		if key == "email" {
+			return slog.String(attr.Key, maskEmail(attr.Value.String()))
+		}
+		return slog.String(attr.Key, redactedValue)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		if s := attr.Value.String(); strings.Contains(s, "@") {
+			return slog.String(attr.Key, emailPattern.ReplaceAllStringFunc(s, maskEmail))
+		}
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, emailPattern.ReplaceAllStringFunc(err.Error(), maskEmail))
+		}
+	}
+	return attr
+}
+
+// maskEmail keeps the first character and the domain: "alice@example.com" -> "a***@example.com"
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return redactedValue
+	}
+	return email[:1] + "***" + email[at:]
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSlogLogger_RequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewSlogLogger(Config{Format: FormatJSON, Output: &buf})
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	log.InfoContext(ctx, "Creating user", "name", "Alice")
+	log.Info("Background work")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if records[0]["request_id"] != "req-123" || records[0]["name"] != "Alice" || records[0]["msg"] != "Creating user" {
+		t.Errorf("unexpected record: %v", records[0])
+	}
+	if _, ok := records[1]["request_id"]; ok {
+		t.Errorf("record without request context should have no request_id: %v", records[1])
+	}
+}
+
+func TestSlogLogger_Level(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewSlogLogger(Config{Format: FormatText, Level: slog.LevelError, Output: &buf})
+
+	log.Debug("debug")
+	log.InfoContext(context.Background(), "info")
+	log.Error("failure", "code", 42)
+
+	out := buf.String()
+	if strings.Contains(out, "debug") || strings.Contains(out, "msg=info") {
+		t.Errorf("messages below the level were logged: %s", out)
+	}
+	if !strings.Contains(out, "msg=failure") || !strings.Contains(out, "code=42") {
+		t.Errorf("text output is missing the error record: %s", out)
+	}
+}
+
+func TestSlogLogger_Redaction(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewSlogLogger(Config{Output: &buf, RedactKeys: []string{"api_key"}})
+
+	log.Error("Failed to create user",
+		"email", "alice@example.com",
+		"password", "hunter2",
+		"api_key", "k-123",
+		"error", errors.New("user with email bob@example.org already exists"),
+		"query", "carol@example.net",
+		"id", "user_1",
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"alice@", "hunter2", "k-123", "bob@", "carol@"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("log output leaks %q: %s", secret, out)
+		}
+	}
+
+	record := decodeLines(t, &buf)[0]
+	if record["email"] != "a***@example.com" {
+		t.Errorf("email = %v, want masked local part", record["email"])
+	}
+	if record["password"] != redactedValue || record["api_key"] != redactedValue {
+		t.Errorf("secrets not redacted: %v", record)
+	}
+	if record["error"] != "user with email b***@example.org already exists" {
+		t.Errorf("error = %v", record["error"])
+	}
+	if record["id"] != "user_1" {
+		t.Errorf("unrelated fields must be kept: %v", record)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "TEXT")
+	t.Setenv("LOG_LEVEL", "debug")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if cfg.Format != FormatText || cfg.Level != slog.LevelDebug {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	t.Setenv("LOG_LEVEL", "verbose")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("invalid level should be rejected")
+	}
+}
@@ -1,27 +1,36 @@
 package middleware
 
 import (
-	"log"
+	"golang-patterns/internal/interfaces/repositories"
 	"net/http"
 	"time"
 )
 
-// LoggingMiddleware logs HTTP requests
-func LoggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		
-		// Custom ResponseWriter to capture status code
-		lw := &loggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
-		
-		next.ServeHTTP(lw, r)
-		
-		log.Printf("%s %s %d %v", 
-			r.Method, 
-			r.URL.Path, 
-			lw.statusCode, 
-			time.Since(start))
-	})
+// LoggingMiddleware logs HTTP requests. Install it after RequestIDMiddleware
+// so the access log shares the request ID with the logs of the use cases.
+func LoggingMiddleware(log repositories.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			// Custom ResponseWriter to capture status code
+			lw := &loggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+			next.ServeHTTP(lw, r)
+
+			fields := []interface{}{
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", lw.statusCode,
+				"duration_ms", time.Since(start).Milliseconds(),
+			}
+			if lw.statusCode >= http.StatusInternalServerError {
+				log.ErrorContext(r.Context(), "HTTP request", fields...)
+				return
+			}
+			log.InfoContext(r.Context(), "HTTP request", fields...)
+		})
+	}
 }
 
 type loggingResponseWriter struct {
@@ -32,4 +41,4 @@ type loggingResponseWriter struct {
 func (lw *loggingResponseWriter) WriteHeader(code int) {
 	lw.statusCode = code
 	lw.ResponseWriter.WriteHeader(code)
-}
\ No newline at end of file
+}
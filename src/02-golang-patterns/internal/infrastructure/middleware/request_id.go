@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"golang-patterns/internal/infrastructure/logger"
+	"net/http"
+	"regexp"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern limits accepted client-supplied IDs so they are safe to log
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware assigns every request an ID, reusing a well-formed
+// X-Request-ID from the client (e.g. a proxy) and generating one otherwise.
+// The ID is echoed in the response and attached to all context-aware logs.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = logger.NewRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(logger.WithRequestID(r.Context(), requestID)))
+	})
+}
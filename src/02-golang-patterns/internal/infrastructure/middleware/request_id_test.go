@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"golang-patterns/internal/infrastructure/logger"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewSlogLogger(logger.Config{Output: &buf})
+
+	handler := RequestIDMiddleware(LoggingMiddleware(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.InfoContext(r.Context(), "Handling request")
+	})))
+
+	tests := []struct {
+		name     string
+		incoming string
+		wantSame bool
+	}{
+		{name: "generated", incoming: ""},
+		{name: "propagated", incoming: "edge-7f3a.1", wantSame: true},
+		{name: "malformed is replaced", incoming: "bad id\nforged=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest("GET", "/api/users", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			requestID := rr.Header().Get(RequestIDHeader)
+			if requestID == "" {
+				t.Fatal("response has no request ID")
+			}
+			if (requestID == tt.incoming) != tt.wantSame {
+				t.Errorf("request ID = %q, incoming %q", requestID, tt.incoming)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("got %d log lines, want handler and access log", len(lines))
+			}
+			for _, line := range lines {
+				var record map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("invalid log line: %v", err)
+				}
+				if record["request_id"] != requestID {
+					t.Errorf("log line %s has request_id %v, want %s", record["msg"], record["request_id"], requestID)
+				}
+			}
+		})
+	}
+}
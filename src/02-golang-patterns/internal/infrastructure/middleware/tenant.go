@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TenantHeader is the request header that explicitly selects a tenant
+const TenantHeader = "X-Tenant-ID"
+
+// TenantMiddleware resolves the tenant of each request and stores it in the
+// request context. The subdomain of baseDomain names the tenant (e.g.
+// "acme.example.com" -> "acme"); the X-Tenant-ID header is used only when no
+// subdomain does, and a header contradicting the subdomain is rejected.
+// Requests that identify no tenant are served as models.DefaultTenantID.
+func TenantMiddleware(baseDomain string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID, err := resolveTenant(r, baseDomain)
+			if err != nil {
+				writeTenantError(w, err)
+				return
+			}
+			if tenantID == "" {
+				tenantID = models.DefaultTenantID
+			}
+
+			if err := models.ValidateTenantID(tenantID); err != nil {
+				writeTenantError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(models.WithTenant(r.Context(), tenantID)))
+		})
+	}
+}
+
+// writeTenantError rejects a request naming an invalid or ambiguous tenant. The body has
+// the shape of the API's error responses, written here so that this layer
+// does not depend on the interface layer.
+func writeTenantError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error": map[string]string{
+			"code":    "INVALID_TENANT",
+			"message": err.Error(),
+		},
+	})
+}
+
+// resolveTenant extracts the tenant ID from the Host subdomain or, when no
+// subdomain names one, from the header. A header naming a different tenant
+// than the subdomain is an error rather than a way to switch tenants.
+func resolveTenant(r *http.Request, baseDomain string) (string, error) {
+	header := strings.ToLower(strings.TrimSpace(r.Header.Get(TenantHeader)))
+
+	subdomain := subdomainTenant(r.Host, baseDomain)
+	if subdomain == "" {
+		return header, nil
+	}
+	if header != "" && header != subdomain {
+		return "", fmt.Errorf("tenant header %q conflicts with subdomain %q", header, subdomain)
+	}
+	return subdomain, nil
+}
+
+// subdomainTenant returns the label of host directly below baseDomain, or ""
+// when baseDomain is not configured or host is not below it
+func subdomainTenant(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+
+	suffix := "." + strings.ToLower(baseDomain)
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+
+	subdomain := strings.TrimSuffix(host, suffix)
+	// Only the label directly below the base domain identifies the tenant
+	if i := strings.LastIndex(subdomain, "."); i >= 0 {
+		subdomain = subdomain[i+1:]
+	}
+	return subdomain
+}
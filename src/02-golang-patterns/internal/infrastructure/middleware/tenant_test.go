@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"golang-patterns/internal/domain/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		header     string
+		baseDomain string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "header", host: "localhost:8080", header: "acme", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "header is normalized", host: "localhost", header: " ACME ", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "subdomain", host: "globex.example.com:8080", baseDomain: "example.com", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "nested subdomain", host: "api.globex.example.com", baseDomain: "example.com", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "header matching subdomain", host: "globex.example.com", header: "Globex", baseDomain: "example.com", wantStatus: http.StatusOK, wantTenant: "globex"},
+		{name: "header conflicting with subdomain", host: "globex.example.com", header: "acme", baseDomain: "example.com", wantStatus: http.StatusBadRequest},
+		{name: "header on bare base domain", host: "example.com", header: "acme", baseDomain: "example.com", wantStatus: http.StatusOK, wantTenant: "acme"},
+		{name: "bare base domain", host: "example.com", baseDomain: "example.com", wantStatus: http.StatusOK, wantTenant: models.DefaultTenantID},
+		{name: "no base domain configured", host: "globex.example.com", wantStatus: http.StatusOK, wantTenant: models.DefaultTenantID},
+		{name: "invalid header", host: "localhost", header: "acme/../globex", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTenant string
+			handler := TenantMiddleware(tt.baseDomain)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTenant = models.TenantFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/api/users", nil)
+			req.Host = tt.host
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && gotTenant != tt.wantTenant {
+				t.Errorf("tenant = %q, want %q", gotTenant, tt.wantTenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"sync"
+	"time"
+)
+
+// MemoryAuditRepository implements AuditRepository in memory.
+// Entries are append-only and partitioned per tenant.
+type MemoryAuditRepository struct {
+	entries   map[string][]*models.AuditEntry // tenantID -> entries in insertion order
+	mutex     sync.RWMutex
+	idCounter int64
+	order     uint64 // see memoryTxRepository.txOrder
+}
+
+// NewMemoryAuditRepository creates a new memory audit repository
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{
+		entries: make(map[string][]*models.AuditEntry),
+		order:   nextTxOrder(),
+	}
+}
+
+// Record appends an entry to the audit trail
+func (r *MemoryAuditRepository) Record(ctx context.Context, entry *models.AuditEntry) (*models.AuditEntry, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	tenantID := models.TenantFromContext(ctx)
+
+	r.idCounter++
+	stored := *entry
+	stored.ID = fmt.Sprintf("audit_%d", r.idCounter)
+	stored.TenantID = tenantID
+	if stored.OccurredAt.IsZero() {
+		stored.OccurredAt = time.Now()
+	}
+	stored.Details = copyDetails(entry.Details)
+
+	r.appendEntry(ctx, &stored)
+
+	result := stored
+	result.Details = copyDetails(stored.Details)
+	return &result, nil
+}
+
+// ListByUser returns the audit trail of a user, oldest first
+func (r *MemoryAuditRepository) ListByUser(ctx context.Context, userID string) ([]*models.AuditEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	entries := []*models.AuditEntry{}
+	for _, entry := range r.readEntries(ctx) {
+		if entry.UserID == userID {
+			entryCopy := *entry
+			entryCopy.Details = copyDetails(entry.Details)
+			entries = append(entries, &entryCopy)
+		}
+	}
+
+	return entries, nil
+}
+
+// ReassignUser moves all entries of one user to another, e.g. after a merge.
+// It returns the number of entries reassigned.
+func (r *MemoryAuditRepository) ReassignUser(ctx context.Context, fromUserID, toUserID string) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for _, entry := range r.writeEntries(ctx) {
+		if entry.UserID == fromUserID {
+			entry.UserID = toUserID
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// readEntries returns the entries of the context's tenant, or the copy
+// staged by the context's unit of work. The caller must hold at least the
+// read lock.
+func (r *MemoryAuditRepository) readEntries(ctx context.Context) []*models.AuditEntry {
+	tenantID := models.TenantFromContext(ctx)
+	if tx := memoryTxFromContext(ctx); tx != nil {
+		if staged, ok := tx.lookup(auditTxKey{repo: r, tenantID: tenantID}).(*stagedAudit); ok {
+			return staged.work
+		}
+	}
+	return r.entries[tenantID]
+}
+
+// writeEntries returns the entries of the context's tenant for changing in
+// place, or within a unit of work the copy staged for the unit. The caller
+// must hold the write lock.
+func (r *MemoryAuditRepository) writeEntries(ctx context.Context) []*models.AuditEntry {
+	if staged := r.stage(ctx); staged != nil {
+		return staged.work
+	}
+	return r.entries[models.TenantFromContext(ctx)]
+}
+
+// appendEntry adds an entry to the context's tenant, or within a unit of
+// work to the copy staged for the unit. The caller must hold the write lock.
+func (r *MemoryAuditRepository) appendEntry(ctx context.Context, entry *models.AuditEntry) {
+	if staged := r.stage(ctx); staged != nil {
+		staged.work = append(staged.work, entry)
+		return
+	}
+	tenantID := models.TenantFromContext(ctx)
+	r.entries[tenantID] = append(r.entries[tenantID], entry)
+}
+
+// stage returns the entries of the context's tenant staged by its unit of
+// work, staging them on first use, or nil outside a unit of work
+func (r *MemoryAuditRepository) stage(ctx context.Context) *stagedAudit {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return nil
+	}
+
+	tenantID := models.TenantFromContext(ctx)
+	staged := tx.stage(r, auditTxKey{repo: r, tenantID: tenantID}, func() interface{} {
+		staged := &stagedAudit{base: make(map[string]string, len(r.entries[tenantID]))}
+		for _, entry := range r.entries[tenantID] {
+			entryCopy := *entry
+			staged.base[entry.ID] = entry.UserID
+			staged.work = append(staged.work, &entryCopy)
+		}
+		return staged
+	})
+	return staged.(*stagedAudit)
+}
+
+// auditTxKey identifies a tenant's entries within a unit of work
+type auditTxKey struct {
+	repo     *MemoryAuditRepository
+	tenantID string
+}
+
+// stagedAudit is a tenant's entries staged by a unit of work: the user of
+// each entry as it was staged, and the entries with the changes of the unit
+type stagedAudit struct {
+	base map[string]string // entry ID -> user ID
+	work []*models.AuditEntry
+}
+
+func (r *MemoryAuditRepository) txOrder() uint64 { return r.order }
+func (r *MemoryAuditRepository) lock()           { r.mutex.Lock() }
+func (r *MemoryAuditRepository) unlock()         { r.mutex.Unlock() }
+
+// validate checks that the entries reassigned by tx still belong to the
+// user they belonged to when staged. The caller must hold the write lock.
+func (r *MemoryAuditRepository) validate(tx *memoryTx) error {
+	var err error
+	r.eachStaged(tx, func(tenantID string, staged *stagedAudit) {
+		live := make(map[string]string, len(r.entries[tenantID]))
+		for _, entry := range r.entries[tenantID] {
+			live[entry.ID] = entry.UserID
+		}
+		for _, entry := range staged.work {
+			if userID, exists := staged.base[entry.ID]; exists && userID != entry.UserID && live[entry.ID] != userID {
+				err = models.ErrConcurrentUpdate
+			}
+		}
+	})
+	return err
+}
+
+// apply appends the entries recorded by tx and reassigns the entries it
+// reassigned. The caller must hold the write lock.
+func (r *MemoryAuditRepository) apply(tx *memoryTx) {
+	r.eachStaged(tx, func(tenantID string, staged *stagedAudit) {
+		reassigned := make(map[string]string)
+		for _, entry := range staged.work {
+			userID, exists := staged.base[entry.ID]
+			switch {
+			case !exists:
+				entryCopy := *entry
+				r.entries[tenantID] = append(r.entries[tenantID], &entryCopy)
+			case userID != entry.UserID:
+				reassigned[entry.ID] = entry.UserID
+			}
+		}
+		for _, entry := range r.entries[tenantID] {
+			if userID, ok := reassigned[entry.ID]; ok {
+				entry.UserID = userID
+			}
+		}
+	})
+}
+
+// eachStaged calls fn for the entries of r staged by tx
+func (r *MemoryAuditRepository) eachStaged(tx *memoryTx, fn func(tenantID string, staged *stagedAudit)) {
+	tx.each(func(key, staged interface{}) {
+		if k, ok := key.(auditTxKey); ok && k.repo == r {
+			fn(k.tenantID, staged.(*stagedAudit))
+		}
+	})
+}
+
+// copyDetails copies an audit details map so stored entries stay immutable
+func copyDetails(details map[string]string) map[string]string {
+	if details == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(details))
+	for k, v := range details {
+		copied[k] = v
+	}
+	return copied
+}
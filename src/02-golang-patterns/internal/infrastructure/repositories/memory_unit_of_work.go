@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// MemoryUnitOfWork implements UnitOfWork for the memory repositories.
+// A repository stages a copy of a tenant partition the first time a unit of
+// work writes to it, and the unit reads and writes that copy from then on,
+// so nobody else sees its changes before they are committed. Committing
+// applies only the records the unit changed, in every repository at once,
+// and fails with models.ErrConcurrentUpdate if one of them was changed by
+// someone else after it was staged. Rolling back discards the copies.
+// Units of work run concurrently.
+type MemoryUnitOfWork struct{}
+
+// NewMemoryUnitOfWork creates a new memory unit of work
+func NewMemoryUnitOfWork() *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{}
+}
+
+type memoryTxContextKey struct{}
+
+// memoryTx holds the partitions staged by one unit of work
+type memoryTx struct {
+	mutex  sync.Mutex
+	staged map[interface{}]interface{}
+	repos  []memoryTxRepository
+}
+
+// memoryTxRepository is a memory repository taking part in units of work.
+// A commit holds the locks of all repositories the unit of work wrote to
+// while it validates and applies their changes.
+type memoryTxRepository interface {
+	// txOrder orders the locks taken by a commit, so that concurrent
+	// commits cannot deadlock
+	txOrder() uint64
+	lock()
+	unlock()
+	// validate reports models.ErrConcurrentUpdate if a record staged by tx
+	// has changed since
+	validate(tx *memoryTx) error
+	// apply writes the records changed by tx
+	apply(tx *memoryTx)
+}
+
+// memoryRepositoryCount numbers the memory repositories for txOrder
+var memoryRepositoryCount uint64
+
+func nextTxOrder() uint64 {
+	return atomic.AddUint64(&memoryRepositoryCount, 1)
+}
+
+// Do runs fn in a unit of work
+func (u *MemoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if memoryTxFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	// Returning early, or panicking, leaves the staged partitions unapplied
+	tx := &memoryTx{staged: make(map[interface{}]interface{})}
+	if err := fn(context.WithValue(ctx, memoryTxContextKey{}, tx)); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return tx.commit()
+}
+
+// memoryTxFromContext returns the unit of work carried by ctx, if any
+func memoryTxFromContext(ctx context.Context) *memoryTx {
+	tx, _ := ctx.Value(memoryTxContextKey{}).(*memoryTx)
+	return tx
+}
+
+// stage returns the partition staged under key, staging the one returned
+// by create on first use. The repository lock must be held so the staged
+// copy is consistent.
+func (tx *memoryTx) stage(repo memoryTxRepository, key interface{}, create func() interface{}) interface{} {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+
+	if staged, exists := tx.staged[key]; exists {
+		return staged
+	}
+	staged := create()
+	tx.staged[key] = staged
+
+	for _, r := range tx.repos {
+		if r == repo {
+			return staged
+		}
+	}
+	tx.repos = append(tx.repos, repo)
+	return staged
+}
+
+// lookup returns the partition staged under key, or nil
+func (tx *memoryTx) lookup(key interface{}) interface{} {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	return tx.staged[key]
+}
+
+// each calls fn for every staged partition
+func (tx *memoryTx) each(fn func(key, staged interface{})) {
+	tx.mutex.Lock()
+	defer tx.mutex.Unlock()
+	for key, staged := range tx.staged {
+		fn(key, staged)
+	}
+}
+
+// commit validates the changes of every repository before applying any,
+// so the unit of work is applied entirely or not at all
+func (tx *memoryTx) commit() error {
+	tx.mutex.Lock()
+	repos := append([]memoryTxRepository(nil), tx.repos...)
+	tx.mutex.Unlock()
+
+	sort.Slice(repos, func(i, j int) bool { return repos[i].txOrder() < repos[j].txOrder() })
+	for _, repo := range repos {
+		repo.lock()
+		defer repo.unlock()
+	}
+
+	for _, repo := range repos {
+		if err := repo.validate(tx); err != nil {
+			return err
+		}
+	}
+	for _, repo := range repos {
+		repo.apply(tx)
+	}
+	return nil
+}
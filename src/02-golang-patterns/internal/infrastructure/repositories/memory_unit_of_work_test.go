@@ -0,0 +1,265 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"golang-patterns/internal/domain/models"
+	"testing"
+)
+
+func TestMemoryUnitOfWork(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	newRepos := func(t *testing.T) (*MemoryUserRepository, *MemoryAuditRepository, *MemoryUnitOfWork, *models.User) {
+		t.Helper()
+		users := NewMemoryUserRepository()
+		existing, err := users.Create(context.Background(), newTestUser("Alice", "alice@example.com", "Engineering"))
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		return users, NewMemoryAuditRepository(), NewMemoryUnitOfWork(), existing
+	}
+
+	// write creates a user, renames the existing one and records an audit entry
+	write := func(ctx context.Context, users *MemoryUserRepository, audit *MemoryAuditRepository, existing *models.User) error {
+		if _, err := users.Create(ctx, newTestUser("Bob", "bob@example.com", "Sales")); err != nil {
+			return err
+		}
+		renamed := *existing
+		renamed.Name = "Alicia"
+		if _, err := users.Update(ctx, &renamed); err != nil {
+			return err
+		}
+		_, err := audit.Record(ctx, models.NewAuditEntry(existing.ID, models.AuditActionUserUpdated, nil))
+		return err
+	}
+
+	// deactivate changes a user outside of any unit of work
+	deactivate := func(users *MemoryUserRepository, id string) error {
+		user, err := users.GetByID(context.Background(), id)
+		if err != nil {
+			return err
+		}
+		user.IsActive = false
+		_, err = users.Update(context.Background(), user)
+		return err
+	}
+
+	assertRolledBack := func(t *testing.T, users *MemoryUserRepository, audit *MemoryAuditRepository, existing *models.User) {
+		t.Helper()
+		ctx := context.Background()
+		if count, _ := users.CountUsers(ctx); count != 1 {
+			t.Errorf("CountUsers = %d, want 1", count)
+		}
+		if got, _ := users.GetByID(ctx, existing.ID); got == nil || got.Name != "Alice" {
+			t.Errorf("update survived rollback: %+v", got)
+		}
+		if _, err := users.GetByEmail(ctx, "bob@example.com"); err == nil {
+			t.Error("email index still resolves the rolled-back user")
+		}
+		if entries, _ := audit.ListByUser(ctx, existing.ID); len(entries) != 0 {
+			t.Errorf("audit entries survived rollback: %d", len(entries))
+		}
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		users, audit, uow, existing := newRepos(t)
+		err := uow.Do(context.Background(), func(ctx context.Context) error {
+			return write(ctx, users, audit, existing)
+		})
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		if count, _ := users.CountUsers(context.Background()); count != 2 {
+			t.Errorf("CountUsers = %d, want 2", count)
+		}
+		if entries, _ := audit.ListByUser(context.Background(), existing.ID); len(entries) != 1 {
+			t.Errorf("audit entries = %d, want 1", len(entries))
+		}
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		users, audit, uow, existing := newRepos(t)
+		err := uow.Do(context.Background(), func(ctx context.Context) error {
+			if err := write(ctx, users, audit, existing); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		if !errors.Is(err, errBoom) {
+			t.Fatalf("Do error = %v, want %v", err, errBoom)
+		}
+		assertRolledBack(t, users, audit, existing)
+
+		// Like a database sequence, IDs issued to the unit are not reused
+		created, _ := users.Create(context.Background(), newTestUser("Carol", "carol@example.com", ""))
+		if created.ID != "user_3" {
+			t.Errorf("next ID = %s, want user_3", created.ID)
+		}
+	})
+
+	t.Run("rollback on cancellation", func(t *testing.T) {
+		users, audit, uow, existing := newRepos(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			err := write(ctx, users, audit, existing)
+			cancel()
+			return err
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Do error = %v, want context.Canceled", err)
+		}
+		assertRolledBack(t, users, audit, existing)
+	})
+
+	t.Run("rollback on panic", func(t *testing.T) {
+		users, audit, uow, existing := newRepos(t)
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("panic was swallowed")
+				}
+			}()
+			_ = uow.Do(context.Background(), func(ctx context.Context) error {
+				if err := write(ctx, users, audit, existing); err != nil {
+					return err
+				}
+				panic("boom")
+			})
+		}()
+		assertRolledBack(t, users, audit, existing)
+
+		// The unit of work is usable again after a panic
+		if err := uow.Do(context.Background(), func(ctx context.Context) error { return nil }); err != nil {
+			t.Errorf("Do after panic: %v", err)
+		}
+	})
+
+	t.Run("nested units join the outer one", func(t *testing.T) {
+		users, audit, uow, existing := newRepos(t)
+		err := uow.Do(context.Background(), func(ctx context.Context) error {
+			if err := uow.Do(ctx, func(ctx context.Context) error {
+				return write(ctx, users, audit, existing)
+			}); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		if !errors.Is(err, errBoom) {
+			t.Fatalf("Do error = %v, want %v", err, errBoom)
+		}
+		assertRolledBack(t, users, audit, existing)
+	})
+
+	t.Run("rollback removes tenants created in the unit", func(t *testing.T) {
+		users, _, uow, _ := newRepos(t)
+		globex := models.WithTenant(context.Background(), "globex")
+		_ = uow.Do(globex, func(ctx context.Context) error {
+			if _, err := users.Create(ctx, newTestUser("Gina", "gina@example.com", "")); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		tenants, _ := users.ListTenants(context.Background())
+		for _, tenant := range tenants {
+			if tenant == "globex" {
+				t.Errorf("tenant created in a rolled-back unit survived: %v", tenants)
+			}
+		}
+	})
+
+	t.Run("rollback keeps writes made outside the unit", func(t *testing.T) {
+		users, audit, uow, existing := newRepos(t)
+		other, _ := users.Create(context.Background(), newTestUser("Dave", "dave@example.com", ""))
+		err := uow.Do(context.Background(), func(ctx context.Context) error {
+			if err := write(ctx, users, audit, existing); err != nil {
+				return err
+			}
+			if err := deactivate(users, other.ID); err != nil {
+				return err
+			}
+			return errBoom
+		})
+		if !errors.Is(err, errBoom) {
+			t.Fatalf("Do error = %v, want %v", err, errBoom)
+		}
+		if got, _ := users.GetByID(context.Background(), other.ID); got == nil || got.IsActive {
+			t.Errorf("write outside the unit was rolled back: %+v", got)
+		}
+	})
+
+	t.Run("uncommitted writes are visible to the unit only", func(t *testing.T) {
+		users, audit, uow, existing := newRepos(t)
+		err := uow.Do(context.Background(), func(ctx context.Context) error {
+			if err := write(ctx, users, audit, existing); err != nil {
+				return err
+			}
+			if got, _ := users.GetByID(ctx, existing.ID); got == nil || got.Name != "Alicia" {
+				t.Errorf("unit does not see its update: %+v", got)
+			}
+			if entries, _ := audit.ListByUser(ctx, existing.ID); len(entries) != 1 {
+				t.Errorf("unit sees %d audit entries, want 1", len(entries))
+			}
+			assertRolledBack(t, users, audit, existing)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		if got, _ := users.GetByID(context.Background(), existing.ID); got == nil || got.Name != "Alicia" {
+			t.Errorf("update not committed: %+v", got)
+		}
+	})
+
+	t.Run("conflicting write fails the commit", func(t *testing.T) {
+		users, audit, uow, existing := newRepos(t)
+		err := uow.Do(context.Background(), func(ctx context.Context) error {
+			if err := write(ctx, users, audit, existing); err != nil {
+				return err
+			}
+			return deactivate(users, existing.ID)
+		})
+		if !errors.Is(err, models.ErrConcurrentUpdate) {
+			t.Fatalf("Do error = %v, want %v", err, models.ErrConcurrentUpdate)
+		}
+		if count, _ := users.CountUsers(context.Background()); count != 1 {
+			t.Errorf("CountUsers = %d, want 1", count)
+		}
+		if entries, _ := audit.ListByUser(context.Background(), existing.ID); len(entries) != 0 {
+			t.Errorf("audit entries of a failed commit: %d", len(entries))
+		}
+		if got, _ := users.GetByID(context.Background(), existing.ID); got == nil || got.IsActive || got.Name != "Alice" {
+			t.Errorf("user = %+v, want the concurrent deactivation only", got)
+		}
+	})
+
+	t.Run("units of work run concurrently", func(t *testing.T) {
+		users, _, uow, _ := newRepos(t)
+		acme := models.WithTenant(context.Background(), "acme")
+		globex := models.WithTenant(context.Background(), "globex")
+		started, done := make(chan struct{}), make(chan error)
+		go func() {
+			done <- uow.Do(acme, func(ctx context.Context) error {
+				close(started)
+				_, err := users.Create(ctx, newTestUser("Anna", "anna@example.com", ""))
+				<-done
+				return err
+			})
+		}()
+		<-started
+		err := uow.Do(globex, func(ctx context.Context) error {
+			_, err := users.Create(ctx, newTestUser("Gina", "gina@example.com", ""))
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		done <- nil
+		if err := <-done; err != nil {
+			t.Fatalf("Do: %v", err)
+		}
+		if tenants, _ := users.ListTenants(context.Background()); len(tenants) != 3 {
+			t.Errorf("tenants = %v, want acme, default and globex", tenants)
+		}
+	})
+}
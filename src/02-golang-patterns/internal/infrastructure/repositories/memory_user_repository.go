@@ -5,27 +5,45 @@ import (
 	"encoding/base64"
 	"fmt"
 	"golang-patterns/internal/domain/models"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 )
 
-// MemoryUserRepository implements UserRepository with advanced features
+// MemoryUserRepository implements UserRepository with advanced features.
+// Data is partitioned per tenant; every method operates only on the
+// partition of the tenant carried by its context.
 type MemoryUserRepository struct {
-	users       map[string]*models.User
-	emailIndex  map[string]string // email -> userID mapping
-	mutex       sync.RWMutex
-	idCounter   int64
+	tenants    map[string]*tenantUsers // tenantID -> partition
+	idCounters map[string]int64        // tenantID -> last user ID issued
+	mutex      sync.RWMutex
+	order      uint64 // see memoryTxRepository.txOrder
+}
+
+// tenantUsers holds the users of a single tenant
+type tenantUsers struct {
+	users      map[string]*models.User
+	deleted    map[string]*models.User // soft-deleted users awaiting purge
+	emailIndex map[string]string       // email -> userID mapping
+}
+
+func newTenantUsers() *tenantUsers {
+	return &tenantUsers{
+		users:      make(map[string]*models.User),
+		deleted:    make(map[string]*models.User),
+		emailIndex: make(map[string]string),
+	}
 }
 
 // NewMemoryUserRepository creates a new memory repository
 func NewMemoryUserRepository() *MemoryUserRepository {
 	return &MemoryUserRepository{
-		users:      make(map[string]*models.User),
-		emailIndex: make(map[string]string),
+		tenants:    make(map[string]*tenantUsers),
+		idCounters: make(map[string]int64),
 		mutex:      sync.RWMutex{},
-		idCounter:  0,
+		order:      nextTxOrder(),
 	}
 }
 
@@ -35,22 +53,24 @@ func NewMemoryUserRepository() *MemoryUserRepository {
 func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	s := r.writeStore(ctx)
 
 	// Check for duplicate email
-	if _, exists := r.emailIndex[user.Email]; exists {
+	if _, exists := s.emailIndex[user.Email]; exists {
 		return nil, models.NewValidationError("email already exists")
 	}
 
 	// Generate ID and set timestamps
-	r.idCounter++
-	user.ID = fmt.Sprintf("user_%d", r.idCounter)
+	user.ID = r.nextID(ctx)
+	user.TenantID = models.TenantFromContext(ctx)
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
+	user.Version = 1
 
 	// Save user and update index
-	r.users[user.ID] = user
-	r.emailIndex[user.Email] = user.ID
+	s.users[user.ID] = user
+	s.emailIndex[user.Email] = user.ID
 
 	return user, nil
 }
@@ -59,8 +79,9 @@ func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User) (*
 func (r *MemoryUserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
-	user, exists := r.users[id]
+	user, exists := s.users[id]
 	if !exists {
 		return nil, models.NotFoundError{Resource: "user", ID: id}
 	}
@@ -74,9 +95,10 @@ func (r *MemoryUserRepository) GetByID(ctx context.Context, id string) (*models.
 func (r *MemoryUserRepository) GetAll(ctx context.Context) ([]*models.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
-	users := make([]*models.User, 0, len(r.users))
-	for _, user := range r.users {
+	users := make([]*models.User, 0, len(s.users))
+	for _, user := range s.users {
 		userCopy := *user
 		users = append(users, &userCopy)
 	}
@@ -84,32 +106,39 @@ func (r *MemoryUserRepository) GetAll(ctx context.Context) ([]*models.User, erro
 	return users, nil
 }
 
-// Update updates an existing user
+// Update updates an existing user. It fails with models.ErrConcurrentUpdate
+// if the user was written since it was read.
 func (r *MemoryUserRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	s := r.writeStore(ctx)
 
-	existingUser, exists := r.users[user.ID]
+	existingUser, exists := s.users[user.ID]
 	if !exists {
 		return nil, models.NotFoundError{Resource: "user", ID: user.ID}
 	}
+	if user.Version != existingUser.Version {
+		return nil, models.ErrConcurrentUpdate
+	}
 
 	// Check for email conflict (if email is being changed)
 	if user.Email != existingUser.Email {
-		if _, emailExists := r.emailIndex[user.Email]; emailExists {
+		if _, emailExists := s.emailIndex[user.Email]; emailExists {
 			return nil, models.NewValidationError("email already exists")
 		}
 		// Update email index
-		delete(r.emailIndex, existingUser.Email)
-		r.emailIndex[user.Email] = user.ID
+		delete(s.emailIndex, existingUser.Email)
+		s.emailIndex[user.Email] = user.ID
 	}
 
 	// Update timestamps
 	user.UpdatedAt = time.Now()
 	user.CreatedAt = existingUser.CreatedAt // Preserve original creation time
+	user.TenantID = existingUser.TenantID
+	user.Version = existingUser.Version + 1
 
 	// Save updated user
-	r.users[user.ID] = user
+	s.users[user.ID] = user
 
 	userCopy := *user
 	return &userCopy, nil
@@ -119,15 +148,16 @@ func (r *MemoryUserRepository) Update(ctx context.Context, user *models.User) (*
 func (r *MemoryUserRepository) Delete(ctx context.Context, id string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	s := r.writeStore(ctx)
 
-	user, exists := r.users[id]
+	user, exists := s.users[id]
 	if !exists {
 		return models.NotFoundError{Resource: "user", ID: id}
 	}
 
 	// Remove from indexes
-	delete(r.emailIndex, user.Email)
-	delete(r.users, id)
+	delete(s.emailIndex, user.Email)
+	delete(s.users, id)
 
 	return nil
 }
@@ -149,13 +179,14 @@ func (r *MemoryUserRepository) Save(ctx context.Context, user *models.User) erro
 func (r *MemoryUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
-	userID, exists := r.emailIndex[email]
+	userID, exists := s.emailIndex[email]
 	if !exists {
 		return nil, models.NotFoundError{Resource: "user", ID: "email:" + email}
 	}
 
-	user := r.users[userID]
+	user := s.users[userID]
 	userCopy := *user
 	return &userCopy, nil
 }
@@ -164,9 +195,10 @@ func (r *MemoryUserRepository) GetByEmail(ctx context.Context, email string) (*m
 func (r *MemoryUserRepository) GetByDepartment(ctx context.Context, department string) ([]*models.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	var users []*models.User
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if user.Department == department {
 			userCopy := *user
 			users = append(users, &userCopy)
@@ -180,9 +212,10 @@ func (r *MemoryUserRepository) GetByDepartment(ctx context.Context, department s
 func (r *MemoryUserRepository) GetByPosition(ctx context.Context, position string) ([]*models.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	var users []*models.User
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if user.Position == position {
 			userCopy := *user
 			users = append(users, &userCopy)
@@ -196,9 +229,10 @@ func (r *MemoryUserRepository) GetByPosition(ctx context.Context, position strin
 func (r *MemoryUserRepository) GetActiveUsers(ctx context.Context) ([]*models.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	var users []*models.User
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if user.IsActive {
 			userCopy := *user
 			users = append(users, &userCopy)
@@ -212,9 +246,10 @@ func (r *MemoryUserRepository) GetActiveUsers(ctx context.Context) ([]*models.Us
 func (r *MemoryUserRepository) GetInactiveUsers(ctx context.Context) ([]*models.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	var users []*models.User
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if !user.IsActive {
 			userCopy := *user
 			users = append(users, &userCopy)
@@ -235,10 +270,11 @@ func (r *MemoryUserRepository) GetUsersWithQuery(ctx context.Context, params *mo
 func (r *MemoryUserRepository) GetUsersWithFilter(ctx context.Context, filter *models.UserFilter, pagination *models.PaginationParams, sort *models.SortParams) (*models.PaginatedResult, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	// Apply filtering
 	var filteredUsers []*models.User
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if filter == nil || filter.Matches(user) {
 			userCopy := *user
 			filteredUsers = append(filteredUsers, &userCopy)
@@ -270,17 +306,19 @@ func (r *MemoryUserRepository) GetUsersWithFilter(ctx context.Context, filter *m
 func (r *MemoryUserRepository) CountUsers(ctx context.Context) (int, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
-	return len(r.users), nil
+	return len(s.users), nil
 }
 
 // CountUsersWithFilter counts users matching filter
 func (r *MemoryUserRepository) CountUsersWithFilter(ctx context.Context, filter *models.UserFilter) (int, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	count := 0
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if filter == nil || filter.Matches(user) {
 			count++
 		}
@@ -295,10 +333,11 @@ func (r *MemoryUserRepository) CountUsersWithFilter(ctx context.Context, filter
 func (r *MemoryUserRepository) GetUsersBatch(ctx context.Context, params *models.ProgressiveLoadParams) (*models.ProgressiveResult, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	// Get all users and sort by ID for consistent cursor-based pagination
 	var allUsers []*models.User
-	for _, user := range r.users {
+	for _, user := range s.users {
 		userCopy := *user
 		allUsers = append(allUsers, &userCopy)
 	}
@@ -400,6 +439,7 @@ func (r *MemoryUserRepository) GetUsersBeforeCursor(ctx context.Context, cursor
 func (r *MemoryUserRepository) GetUserStats(ctx context.Context) (*models.UserStats, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	stats := &models.UserStats{
 		DepartmentStats: make(map[string]int),
@@ -410,7 +450,7 @@ func (r *MemoryUserRepository) GetUserStats(ctx context.Context) (*models.UserSt
 	oneWeekAgo := time.Now().AddDate(0, 0, -7)
 	oneWeekAgoRecentLogin := time.Now().AddDate(0, 0, -1)
 
-	for _, user := range r.users {
+	for _, user := range s.users {
 		stats.TotalUsers++
 
 		if user.IsActive {
@@ -451,9 +491,10 @@ func (r *MemoryUserRepository) GetUserStats(ctx context.Context) (*models.UserSt
 func (r *MemoryUserRepository) GetDepartmentStats(ctx context.Context) (map[string]int, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	stats := make(map[string]int)
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if user.Department != "" {
 			stats[user.Department]++
 		}
@@ -466,9 +507,10 @@ func (r *MemoryUserRepository) GetDepartmentStats(ctx context.Context) (map[stri
 func (r *MemoryUserRepository) GetPositionStats(ctx context.Context) (map[string]int, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	stats := make(map[string]int)
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if user.Position != "" {
 			stats[user.Position]++
 		}
@@ -481,11 +523,12 @@ func (r *MemoryUserRepository) GetPositionStats(ctx context.Context) (map[string
 func (r *MemoryUserRepository) GetRecentSignups(ctx context.Context, days int) ([]*models.User, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	cutoff := time.Now().AddDate(0, 0, -days)
 	var users []*models.User
 
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if user.CreatedAt.After(cutoff) {
 			userCopy := *user
 			users = append(users, &userCopy)
@@ -501,25 +544,27 @@ func (r *MemoryUserRepository) GetRecentSignups(ctx context.Context, days int) (
 func (r *MemoryUserRepository) BulkCreate(ctx context.Context, users []*models.User) ([]*models.User, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	s := r.writeStore(ctx)
 
 	var createdUsers []*models.User
 	now := time.Now()
 
 	for _, user := range users {
 		// Check for duplicate email
-		if _, exists := r.emailIndex[user.Email]; exists {
+		if _, exists := s.emailIndex[user.Email]; exists {
 			return nil, models.NewValidationError(fmt.Sprintf("email %s already exists", user.Email))
 		}
 
 		// Generate ID and set timestamps
-		r.idCounter++
-		user.ID = fmt.Sprintf("user_%d", r.idCounter)
+		user.ID = r.nextID(ctx)
+		user.TenantID = models.TenantFromContext(ctx)
 		user.CreatedAt = now
 		user.UpdatedAt = now
+		user.Version = 1
 
 		// Save user and update index
-		r.users[user.ID] = user
-		r.emailIndex[user.Email] = user.ID
+		s.users[user.ID] = user
+		s.emailIndex[user.Email] = user.ID
 
 		userCopy := *user
 		createdUsers = append(createdUsers, &userCopy)
@@ -528,36 +573,48 @@ func (r *MemoryUserRepository) BulkCreate(ctx context.Context, users []*models.U
 	return createdUsers, nil
 }
 
-// BulkUpdate updates multiple users
+// BulkUpdate updates multiple users. It fails with
+// models.ErrConcurrentUpdate, updating none, if any of them was written
+// since it was read.
 func (r *MemoryUserRepository) BulkUpdate(ctx context.Context, users []*models.User) ([]*models.User, error) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	s := r.writeStore(ctx)
+
+	// First check no user was written in the meantime
+	for _, user := range users {
+		if existingUser, exists := s.users[user.ID]; exists && user.Version != existingUser.Version {
+			return nil, models.ErrConcurrentUpdate
+		}
+	}
 
 	var updatedUsers []*models.User
 	now := time.Now()
 
 	for _, user := range users {
-		existingUser, exists := r.users[user.ID]
+		existingUser, exists := s.users[user.ID]
 		if !exists {
 			return nil, models.NotFoundError{Resource: "user", ID: user.ID}
 		}
 
 		// Check for email conflict
 		if user.Email != existingUser.Email {
-			if _, emailExists := r.emailIndex[user.Email]; emailExists {
+			if _, emailExists := s.emailIndex[user.Email]; emailExists {
 				return nil, models.NewValidationError(fmt.Sprintf("email %s already exists", user.Email))
 			}
 			// Update email index
-			delete(r.emailIndex, existingUser.Email)
-			r.emailIndex[user.Email] = user.ID
+			delete(s.emailIndex, existingUser.Email)
+			s.emailIndex[user.Email] = user.ID
 		}
 
 		// Update timestamps
 		user.UpdatedAt = now
 		user.CreatedAt = existingUser.CreatedAt
+		user.TenantID = existingUser.TenantID
+		user.Version = existingUser.Version + 1
 
 		// Save updated user
-		r.users[user.ID] = user
+		s.users[user.ID] = user
 
 		userCopy := *user
 		updatedUsers = append(updatedUsers, &userCopy)
@@ -570,35 +627,150 @@ func (r *MemoryUserRepository) BulkUpdate(ctx context.Context, users []*models.U
 func (r *MemoryUserRepository) BulkDelete(ctx context.Context, ids []string) error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
+	s := r.writeStore(ctx)
 
 	// First check all users exist
 	for _, id := range ids {
-		if _, exists := r.users[id]; !exists {
+		if _, exists := s.users[id]; !exists {
 			return models.NotFoundError{Resource: "user", ID: id}
 		}
 	}
 
 	// Delete all users
 	for _, id := range ids {
-		user := r.users[id]
-		delete(r.emailIndex, user.Email)
-		delete(r.users, id)
+		user := s.users[id]
+		delete(s.emailIndex, user.Email)
+		delete(s.users, id)
 	}
 
 	return nil
 }
 
+// === Soft Deletion and Lifecycle ===
+
+// SoftDelete marks users as deleted. Soft-deleted users are hidden from all
+// other queries and their emails become available again until purged.
+func (r *MemoryUserRepository) SoftDelete(ctx context.Context, ids []string, deletedAt time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s := r.writeStore(ctx)
+
+	// First check all users exist
+	for _, id := range ids {
+		if _, exists := s.users[id]; !exists {
+			return models.NotFoundError{Resource: "user", ID: id}
+		}
+	}
+
+	for _, id := range ids {
+		user := s.users[id]
+		at := deletedAt
+		user.DeletedAt = &at
+		user.Version++
+		delete(s.emailIndex, user.Email)
+		delete(s.users, id)
+		s.deleted[id] = user
+	}
+
+	return nil
+}
+
+// GetSoftDeletedUsers gets all soft-deleted users awaiting purge
+func (r *MemoryUserRepository) GetSoftDeletedUsers(ctx context.Context) ([]*models.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
+
+	users := make([]*models.User, 0, len(s.deleted))
+	for _, user := range s.deleted {
+		userCopy := *user
+		users = append(users, &userCopy)
+	}
+
+	return users, nil
+}
+
+// PurgeUser permanently removes a soft-deleted user
+func (r *MemoryUserRepository) PurgeUser(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s := r.writeStore(ctx)
+
+	if _, exists := s.deleted[id]; !exists {
+		return models.NotFoundError{Resource: "deleted user", ID: id}
+	}
+	delete(s.deleted, id)
+
+	return nil
+}
+
+// ListTenants lists the IDs of all tenants that have stored data
+func (r *MemoryUserRepository) ListTenants(ctx context.Context) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	tenantIDs := make([]string, 0, len(r.tenants))
+	for tenantID := range r.tenants {
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	sort.Strings(tenantIDs)
+
+	return tenantIDs, nil
+}
+
+// === Merge Operations ===
+
+// MergeUsers atomically saves the merged survivor and deletes the loser.
+// Either both changes are applied or neither is. It fails with
+// models.ErrConcurrentUpdate if the survivor was written since it was read.
+func (r *MemoryUserRepository) MergeUsers(ctx context.Context, survivor *models.User, loserID string) (*models.User, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s := r.writeStore(ctx)
+
+	existingSurvivor, exists := s.users[survivor.ID]
+	if !exists {
+		return nil, models.NotFoundError{Resource: "user", ID: survivor.ID}
+	}
+	loser, exists := s.users[loserID]
+	if !exists {
+		return nil, models.NotFoundError{Resource: "user", ID: loserID}
+	}
+	if survivor.Version != existingSurvivor.Version {
+		return nil, models.ErrConcurrentUpdate
+	}
+
+	// Validate everything before mutating so a failure leaves no trace
+	if ownerID, emailExists := s.emailIndex[survivor.Email]; emailExists && ownerID != survivor.ID && ownerID != loserID {
+		return nil, models.NewValidationError("email already exists")
+	}
+
+	delete(s.emailIndex, loser.Email)
+	delete(s.users, loserID)
+
+	delete(s.emailIndex, existingSurvivor.Email)
+	survivor.TenantID = existingSurvivor.TenantID
+	survivor.UpdatedAt = time.Now()
+	survivor.Version = existingSurvivor.Version + 1
+	s.users[survivor.ID] = survivor
+	s.emailIndex[survivor.Email] = survivor.ID
+
+	userCopy := *survivor
+	return &userCopy, nil
+}
+
 // === Search Operations ===
 
 // SearchUsers searches users by name or email
 func (r *MemoryUserRepository) SearchUsers(ctx context.Context, query string, pagination *models.PaginationParams) (*models.PaginatedResult, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	var matchedUsers []*models.User
 	lowerQuery := strings.ToLower(query)
 
-	for _, user := range r.users {
+	for _, user := range s.users {
 		if strings.Contains(strings.ToLower(user.Name), lowerQuery) ||
 			strings.Contains(strings.ToLower(user.Email), lowerQuery) ||
 			strings.Contains(strings.ToLower(user.Department), lowerQuery) ||
@@ -629,11 +801,12 @@ func (r *MemoryUserRepository) SearchUsers(ctx context.Context, query string, pa
 func (r *MemoryUserRepository) SearchUsersByField(ctx context.Context, field string, value string, pagination *models.PaginationParams) (*models.PaginatedResult, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
+	s := r.readStore(ctx)
 
 	var matchedUsers []*models.User
 	lowerValue := strings.ToLower(value)
 
-	for _, user := range r.users {
+	for _, user := range s.users {
 		var fieldValue string
 		switch field {
 		case "name":
@@ -673,6 +846,212 @@ func (r *MemoryUserRepository) SearchUsersByField(ctx context.Context, field str
 
 // === Helper Methods ===
 
+// readStore returns the partition of the context's tenant for reading,
+// or the copy staged by the context's unit of work. Unknown tenants get an
+// empty partition that is not registered. The caller must hold at least
+// the read lock.
+func (r *MemoryUserRepository) readStore(ctx context.Context) *tenantUsers {
+	tenantID := models.TenantFromContext(ctx)
+	if tx := memoryTxFromContext(ctx); tx != nil {
+		if staged, ok := tx.lookup(userTxKey{repo: r, tenantID: tenantID}).(*stagedUsers); ok {
+			return staged.work
+		}
+	}
+	if store, exists := r.tenants[tenantID]; exists {
+		return store
+	}
+	return newTenantUsers()
+}
+
+// writeStore returns the partition of the context's tenant, creating it on
+// first use. Within a unit of work it returns the copy staged for the unit
+// instead. The caller must hold the write lock.
+func (r *MemoryUserRepository) writeStore(ctx context.Context) *tenantUsers {
+	tenantID := models.TenantFromContext(ctx)
+	store, exists := r.tenants[tenantID]
+
+	if tx := memoryTxFromContext(ctx); tx != nil {
+		staged := tx.stage(r, userTxKey{repo: r, tenantID: tenantID}, func() interface{} {
+			if !exists {
+				return &stagedUsers{base: newTenantUsers(), work: newTenantUsers()}
+			}
+			return &stagedUsers{base: store.clone(), work: store.clone()}
+		})
+		return staged.(*stagedUsers).work
+	}
+
+	if !exists {
+		store = newTenantUsers()
+		r.tenants[tenantID] = store
+	}
+	return store
+}
+
+// nextID issues the next user ID of the context's tenant. IDs issued in a
+// unit of work that is rolled back are not reused. The caller must hold the
+// write lock.
+func (r *MemoryUserRepository) nextID(ctx context.Context) string {
+	tenantID := models.TenantFromContext(ctx)
+	r.idCounters[tenantID]++
+	return fmt.Sprintf("user_%d", r.idCounters[tenantID])
+}
+
+// userTxKey identifies a tenant partition within a unit of work
+type userTxKey struct {
+	repo     *MemoryUserRepository
+	tenantID string
+}
+
+// stagedUsers is a partition staged by a unit of work: base as it was
+// staged, and work with the changes of the unit
+type stagedUsers struct {
+	base *tenantUsers
+	work *tenantUsers
+}
+
+func (r *MemoryUserRepository) txOrder() uint64 { return r.order }
+func (r *MemoryUserRepository) lock()           { r.mutex.Lock() }
+func (r *MemoryUserRepository) unlock()         { r.mutex.Unlock() }
+
+// validate checks that the users and emails changed by tx are still as
+// they were staged. The caller must hold the write lock.
+func (r *MemoryUserRepository) validate(tx *memoryTx) error {
+	var err error
+	r.eachStaged(tx, func(tenantID string, staged *stagedUsers) {
+		live, exists := r.tenants[tenantID]
+		if !exists {
+			live = newTenantUsers()
+		}
+		for _, id := range changedUsers(staged.base.users, staged.work.users) {
+			if !sameUser(live.users[id], staged.base.users[id]) {
+				err = models.ErrConcurrentUpdate
+			}
+		}
+		for _, id := range changedUsers(staged.base.deleted, staged.work.deleted) {
+			if !sameUser(live.deleted[id], staged.base.deleted[id]) {
+				err = models.ErrConcurrentUpdate
+			}
+		}
+		for _, email := range changedEmails(staged.base.emailIndex, staged.work.emailIndex) {
+			liveID, liveExists := live.emailIndex[email]
+			baseID, baseExists := staged.base.emailIndex[email]
+			if liveID != baseID || liveExists != baseExists {
+				err = models.ErrConcurrentUpdate
+			}
+		}
+	})
+	return err
+}
+
+// apply writes the users and emails changed by tx, leaving everything else
+// as it is. The caller must hold the write lock.
+func (r *MemoryUserRepository) apply(tx *memoryTx) {
+	r.eachStaged(tx, func(tenantID string, staged *stagedUsers) {
+		users := changedUsers(staged.base.users, staged.work.users)
+		deleted := changedUsers(staged.base.deleted, staged.work.deleted)
+		emails := changedEmails(staged.base.emailIndex, staged.work.emailIndex)
+		if len(users)+len(deleted)+len(emails) == 0 {
+			return
+		}
+
+		live, exists := r.tenants[tenantID]
+		if !exists {
+			live = newTenantUsers()
+			r.tenants[tenantID] = live
+		}
+		applyUsers(live.users, staged.work.users, users)
+		applyUsers(live.deleted, staged.work.deleted, deleted)
+		for _, email := range emails {
+			if id, ok := staged.work.emailIndex[email]; ok {
+				live.emailIndex[email] = id
+			} else {
+				delete(live.emailIndex, email)
+			}
+		}
+	})
+}
+
+// eachStaged calls fn for the partitions of r staged by tx
+func (r *MemoryUserRepository) eachStaged(tx *memoryTx, fn func(tenantID string, staged *stagedUsers)) {
+	tx.each(func(key, staged interface{}) {
+		if k, ok := key.(userTxKey); ok && k.repo == r {
+			fn(k.tenantID, staged.(*stagedUsers))
+		}
+	})
+}
+
+// changedUsers returns the IDs of users added, changed or removed in work
+func changedUsers(base, work map[string]*models.User) []string {
+	var ids []string
+	for id, user := range work {
+		if !sameUser(base[id], user) {
+			ids = append(ids, id)
+		}
+	}
+	for id := range base {
+		if _, exists := work[id]; !exists {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// changedEmails returns the emails added, remapped or removed in work
+func changedEmails(base, work map[string]string) []string {
+	var emails []string
+	for email, id := range work {
+		if baseID, exists := base[email]; !exists || baseID != id {
+			emails = append(emails, email)
+		}
+	}
+	for email := range base {
+		if _, exists := work[email]; !exists {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+func sameUser(a, b *models.User) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return reflect.DeepEqual(*a, *b)
+}
+
+// applyUsers copies the users with the given IDs from work to live
+func applyUsers(live, work map[string]*models.User, ids []string) {
+	for _, id := range ids {
+		if user, exists := work[id]; exists {
+			userCopy := *user
+			live[id] = &userCopy
+		} else {
+			delete(live, id)
+		}
+	}
+}
+
+// clone deep-copies the partition for a unit of work
+func (s *tenantUsers) clone() *tenantUsers {
+	c := &tenantUsers{
+		users:      make(map[string]*models.User, len(s.users)),
+		deleted:    make(map[string]*models.User, len(s.deleted)),
+		emailIndex: make(map[string]string, len(s.emailIndex)),
+	}
+	for id, user := range s.users {
+		userCopy := *user
+		c.users[id] = &userCopy
+	}
+	for id, user := range s.deleted {
+		userCopy := *user
+		c.deleted[id] = &userCopy
+	}
+	for email, id := range s.emailIndex {
+		c.emailIndex[email] = id
+	}
+	return c
+}
+
 // sortUsers sorts users based on sort parameters
 func (r *MemoryUserRepository) sortUsers(users []*models.User, sortParams *models.SortParams) {
 	if sortParams == nil {
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"golang-patterns/internal/domain/models"
+	"testing"
+)
+
+func newTestUser(name, email, department string) *models.User {
+	return &models.User{
+		Name:       name,
+		Email:      email,
+		Department: department,
+		IsActive:   true,
+	}
+}
+
+func TestMemoryUserRepository_TenantIsolation(t *testing.T) {
+	repo := NewMemoryUserRepository()
+	acme := models.WithTenant(context.Background(), "acme")
+	globex := models.WithTenant(context.Background(), "globex")
+
+	acmeUser, err := repo.Create(acme, newTestUser("Alice", "alice@example.com", "Engineering"))
+	if err != nil {
+		t.Fatalf("create in acme: %v", err)
+	}
+	if _, err := repo.Create(acme, newTestUser("Bob", "bob@example.com", "Sales")); err != nil {
+		t.Fatalf("create in acme: %v", err)
+	}
+
+	t.Run("email uniqueness is per tenant", func(t *testing.T) {
+		if _, err := repo.Create(globex, newTestUser("Alice G", "alice@example.com", "Engineering")); err != nil {
+			t.Fatalf("same email in another tenant should be allowed: %v", err)
+		}
+		if _, err := repo.Create(acme, newTestUser("Alice 2", "alice@example.com", "HR")); err == nil {
+			t.Fatal("duplicate email within a tenant should be rejected")
+		}
+	})
+
+	t.Run("ID counters are per tenant", func(t *testing.T) {
+		globexUser, err := repo.GetByEmail(globex, "alice@example.com")
+		if err != nil {
+			t.Fatalf("get by email: %v", err)
+		}
+		if globexUser.ID != "user_1" || acmeUser.ID != "user_1" {
+			t.Errorf("expected both tenants to start at user_1, got acme=%s globex=%s", acmeUser.ID, globexUser.ID)
+		}
+		if globexUser.TenantID != "globex" || acmeUser.TenantID != "acme" {
+			t.Errorf("unexpected tenant IDs: acme=%s globex=%s", acmeUser.TenantID, globexUser.TenantID)
+		}
+	})
+
+	t.Run("reads never cross tenants", func(t *testing.T) {
+		got, err := repo.GetByID(globex, acmeUser.ID)
+		if err != nil {
+			t.Fatalf("get by id: %v", err)
+		}
+		if got.Name != "Alice G" {
+			t.Errorf("globex read returned acme data: %+v", got)
+		}
+
+		if _, err := repo.GetByEmail(globex, "bob@example.com"); err == nil {
+			t.Error("globex must not see acme's bob")
+		}
+
+		all, _ := repo.GetAll(globex)
+		if len(all) != 1 {
+			t.Errorf("globex GetAll = %d users, want 1", len(all))
+		}
+
+		sales, _ := repo.GetByDepartment(globex, "Sales")
+		if len(sales) != 0 {
+			t.Errorf("globex department query leaked %d acme users", len(sales))
+		}
+	})
+
+	t.Run("stats and search are per tenant", func(t *testing.T) {
+		stats, err := repo.GetUserStats(acme)
+		if err != nil {
+			t.Fatalf("stats: %v", err)
+		}
+		if stats.TotalUsers != 2 {
+			t.Errorf("acme TotalUsers = %d, want 2", stats.TotalUsers)
+		}
+
+		result, err := repo.SearchUsers(globex, "bob", models.NewPaginationParams(1, 10))
+		if err != nil {
+			t.Fatalf("search: %v", err)
+		}
+		if result.Total != 0 {
+			t.Errorf("globex search found %d acme users", result.Total)
+		}
+
+		count, _ := repo.CountUsers(models.WithTenant(context.Background(), "unknown"))
+		if count != 0 {
+			t.Errorf("unknown tenant CountUsers = %d, want 0", count)
+		}
+	})
+
+	t.Run("writes never cross tenants", func(t *testing.T) {
+		bob, _ := repo.GetByEmail(acme, "bob@example.com")
+
+		if err := repo.Delete(globex, bob.ID); err == nil {
+			t.Error("globex deleted an ID it does not own")
+		}
+		if err := repo.BulkDelete(globex, []string{bob.ID}); err == nil {
+			t.Error("globex bulk-deleted an ID it does not own")
+		}
+
+		intruder := *bob
+		intruder.Name = "Hijacked"
+		if _, err := repo.Update(globex, &intruder); err != nil {
+			var notFound models.NotFoundError
+			if !errors.As(err, &notFound) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		stillBob, err := repo.GetByEmail(acme, "bob@example.com")
+		if err != nil {
+			t.Fatalf("acme bob disappeared: %v", err)
+		}
+		if stillBob.Name != "Bob" {
+			t.Errorf("acme bob was modified through globex: %+v", stillBob)
+		}
+	})
+}
+
+func TestMemoryUserRepository_DefaultTenant(t *testing.T) {
+	repo := NewMemoryUserRepository()
+
+	user, err := repo.Create(context.Background(), newTestUser("Carol", "carol@example.com", ""))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if user.TenantID != models.DefaultTenantID {
+		t.Errorf("TenantID = %q, want %q", user.TenantID, models.DefaultTenantID)
+	}
+
+	explicit := models.WithTenant(context.Background(), models.DefaultTenantID)
+	if _, err := repo.GetByID(explicit, user.ID); err != nil {
+		t.Errorf("context without tenant should use the default tenant: %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package scheduler
+
+import "time"
+
+// Clock abstracts the current time so jobs can be tested deterministically
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now
+type SystemClock struct{}
+
+// Now returns the current local time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
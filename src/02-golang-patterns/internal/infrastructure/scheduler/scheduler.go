@@ -0,0 +1,251 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/interfaces/repositories"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultHistoryLimit is the number of job runs kept in memory
+const defaultHistoryLimit = 100
+
+// ErrJobRunning is returned when a job is triggered while already running
+var ErrJobRunning = models.ErrJobRunning
+
+// JobFunc executes a job as of now for tenantID, or for every tenant if
+// tenantID is empty. In dry-run mode it must only report what it would do.
+type JobFunc func(ctx context.Context, now time.Time, tenantID string, dryRun bool) (*models.JobReport, error)
+
+// Job is a named unit of work run on a cron-like schedule
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	// DryRun makes scheduled runs report without applying changes.
+	// Manual runs choose dry-run mode per request.
+	DryRun bool
+	Run    JobFunc
+}
+
+type registeredJob struct {
+	Job
+	schedule  *Schedule
+	running   bool
+	lastFired time.Time // minute of the last scheduled run
+}
+
+// Scheduler runs registered jobs when their schedule matches the clock and
+// keeps a bounded history of runs
+type Scheduler struct {
+	clock        Clock
+	logger       repositories.Logger
+	tick         time.Duration
+	historyLimit int
+
+	mutex      sync.Mutex
+	jobs       map[string]*registeredJob
+	history    []*models.JobRun
+	runCounter int64
+}
+
+// NewScheduler creates a new scheduler using the given clock
+func NewScheduler(clock Clock, logger repositories.Logger) *Scheduler {
+	return &Scheduler{
+		clock:        clock,
+		logger:       logger,
+		tick:         30 * time.Second,
+		historyLimit: defaultHistoryLimit,
+		jobs:         make(map[string]*registeredJob),
+	}
+}
+
+// Register adds a job to the scheduler
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return err
+	}
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job name and run function are required")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %q already registered", job.Name)
+	}
+	s.jobs[job.Name] = &registeredJob{Job: job, schedule: schedule}
+	return nil
+}
+
+// Start runs due jobs in the background until ctx is canceled
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.tick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunDue(ctx)
+			}
+		}
+	}()
+}
+
+// RunDue runs every job whose schedule matches the current minute and that
+// has not already fired in it, for every tenant. Jobs run synchronously; it
+// returns their runs.
+func (s *Scheduler) RunDue(ctx context.Context) []*models.JobRun {
+	now := s.clock.Now()
+	minute := now.Truncate(time.Minute)
+
+	s.mutex.Lock()
+	var due []*registeredJob
+	for _, job := range s.jobs {
+		if job.schedule.Matches(now) && !job.lastFired.Equal(minute) && !job.running {
+			job.lastFired = minute
+			due = append(due, job)
+		}
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].Name < due[j].Name
+	})
+
+	var runs []*models.JobRun
+	for _, job := range due {
+		run, err := s.run(ctx, job.Name, "", models.JobTriggerSchedule, job.DryRun)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Scheduled job not run", "job", job.Name, "error", err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// RunNow triggers a job manually for a single tenant
+func (s *Scheduler) RunNow(ctx context.Context, name, tenantID string, dryRun bool) (*models.JobRun, error) {
+	if tenantID == "" {
+		return nil, fmt.Errorf("manual runs of job %q need a tenant", name)
+	}
+	return s.run(ctx, name, tenantID, models.JobTriggerManual, dryRun)
+}
+
+// Jobs describes all registered jobs
+func (s *Scheduler) Jobs() []models.JobInfo {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.clock.Now()
+	infos := make([]models.JobInfo, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		infos = append(infos, models.JobInfo{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Schedule,
+			DryRun:      job.DryRun,
+			NextRun:     job.schedule.Next(now),
+			Running:     job.running,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// History returns up to limit job runs, most recent first.
+// An empty job name returns runs of all jobs. An empty tenantID returns the
+// runs as they are; otherwise only the manual runs of the tenant and the
+// scheduled runs, reporting only the users of the tenant.
+func (s *Scheduler) History(job, tenantID string, limit int) []*models.JobRun {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	runs := []*models.JobRun{}
+	for i := len(s.history) - 1; i >= 0 && (limit <= 0 || len(runs) < limit); i-- {
+		run := s.history[i]
+		if job != "" && run.Job != job {
+			continue
+		}
+		if tenantID != "" && run.TenantID != "" && run.TenantID != tenantID {
+			continue
+		}
+		runCopy := *run
+		if tenantID != "" && run.Report != nil {
+			runCopy.Report = reportForTenant(run.Report, tenantID)
+		}
+		runs = append(runs, &runCopy)
+	}
+	return runs
+}
+
+// reportForTenant returns a copy of report listing only the users of tenantID
+func reportForTenant(report *models.JobReport, tenantID string) *models.JobReport {
+	filtered := &models.JobReport{DryRun: report.DryRun, Affected: []models.AffectedUser{}}
+	for _, affected := range report.Affected {
+		if affected.TenantID == tenantID {
+			filtered.Affected = append(filtered.Affected, affected)
+		}
+	}
+	return filtered
+}
+
+// run executes a job once, for tenantID or every tenant if it is empty, and
+// records it in the history
+func (s *Scheduler) run(ctx context.Context, name, tenantID, trigger string, dryRun bool) (*models.JobRun, error) {
+	s.mutex.Lock()
+	job, exists := s.jobs[name]
+	if !exists {
+		s.mutex.Unlock()
+		return nil, models.NotFoundError{Resource: "job", ID: name}
+	}
+	if job.running {
+		s.mutex.Unlock()
+		return nil, ErrJobRunning
+	}
+	job.running = true
+	s.runCounter++
+	run := &models.JobRun{
+		ID:        fmt.Sprintf("run_%d", s.runCounter),
+		Job:       name,
+		Trigger:   trigger,
+		TenantID:  tenantID,
+		DryRun:    dryRun,
+		StartedAt: s.clock.Now(),
+	}
+	s.mutex.Unlock()
+
+	s.logger.InfoContext(ctx, "Job started", "job", name, "run_id", run.ID, "trigger", trigger, "dry_run", dryRun)
+
+	report, err := job.Run(ctx, run.StartedAt, tenantID, dryRun)
+	run.Report = report
+	if err != nil {
+		run.Error = err.Error()
+		s.logger.ErrorContext(ctx, "Job failed", "job", name, "run_id", run.ID, "error", err)
+	}
+
+	s.mutex.Lock()
+	run.FinishedAt = s.clock.Now()
+	job.running = false
+	s.history = append(s.history, run)
+	if len(s.history) > s.historyLimit {
+		s.history = s.history[len(s.history)-s.historyLimit:]
+	}
+	s.mutex.Unlock()
+
+	s.logger.InfoContext(ctx, "Job finished", "job", name, "run_id", run.ID, "duration", run.FinishedAt.Sub(run.StartedAt))
+
+	runCopy := *run
+	return &runCopy, nil
+}
@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"golang-patterns/internal/domain/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = t
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, fields ...interface{})  {}
+func (nopLogger) Error(msg string, fields ...interface{}) {}
+func (nopLogger) Debug(msg string, fields ...interface{}) {}
+
+func (nopLogger) InfoContext(ctx context.Context, msg string, fields ...interface{})  {}
+func (nopLogger) ErrorContext(ctx context.Context, msg string, fields ...interface{}) {}
+func (nopLogger) DebugContext(ctx context.Context, msg string, fields ...interface{}) {}
+
+func TestParseSchedule(t *testing.T) {
+	base := time.Date(2026, time.March, 2, 3, 0, 0, 0, time.UTC) // Monday
+
+	tests := []struct {
+		spec    string
+		at      time.Time
+		matches bool
+		next    time.Time
+	}{
+		{"0 3 * * *", base, true, base.AddDate(0, 0, 1)},
+		{"@daily", base, false, time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", base.Add(45 * time.Minute), true, base.Add(time.Hour)},
+		{"0 9-17 * * 1-5", base.Add(6 * time.Hour), true, base.Add(7 * time.Hour)},
+		{"0 0 1 * *", base, false, time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", base, false, time.Date(2026, time.March, 8, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := s.Matches(tt.at); got != tt.matches {
+				t.Errorf("Matches(%v) = %v, want %v", tt.at, got, tt.matches)
+			}
+			if got := s.Next(tt.at); !got.Equal(tt.next) {
+				t.Errorf("Next(%v) = %v, want %v", tt.at, got, tt.next)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a b c d e"} {
+		if _, err := ParseSchedule(invalid); err == nil {
+			t.Errorf("ParseSchedule(%q) should fail", invalid)
+		}
+	}
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, time.March, 2, 2, 59, 0, 0, time.UTC)}
+	s := NewScheduler(clock, nopLogger{})
+
+	var calls []bool
+	err := s.Register(Job{
+		Name:     "nightly",
+		Schedule: "0 3 * * *",
+		DryRun:   true,
+		Run: func(ctx context.Context, now time.Time, tenantID string, dryRun bool) (*models.JobReport, error) {
+			calls = append(calls, dryRun)
+			return &models.JobReport{DryRun: dryRun}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	if runs := s.RunDue(context.Background()); len(runs) != 0 {
+		t.Fatalf("job ran before its schedule: %d runs", len(runs))
+	}
+
+	clock.Set(time.Date(2026, time.March, 2, 3, 0, 10, 0, time.UTC))
+	if runs := s.RunDue(context.Background()); len(runs) != 1 || runs[0].Trigger != models.JobTriggerSchedule {
+		t.Fatalf("expected one scheduled run, got %+v", runs)
+	}
+
+	// A second tick within the same minute must not fire again
+	clock.Set(time.Date(2026, time.March, 2, 3, 0, 40, 0, time.UTC))
+	if runs := s.RunDue(context.Background()); len(runs) != 0 {
+		t.Fatalf("job fired twice in one minute")
+	}
+
+	if len(calls) != 1 || !calls[0] {
+		t.Errorf("scheduled run should use the job's dry-run setting, got %v", calls)
+	}
+
+	run, err := s.RunNow(context.Background(), "nightly", "acme", false)
+	if err != nil {
+		t.Fatalf("run now: %v", err)
+	}
+	if run.Trigger != models.JobTriggerManual || run.DryRun {
+		t.Errorf("unexpected manual run: %+v", run)
+	}
+
+	history := s.History("", "", 10)
+	if len(history) != 2 || history[0].ID != run.ID {
+		t.Errorf("history should list the manual run first, got %+v", history)
+	}
+
+	jobs := s.Jobs()
+	if len(jobs) != 1 || !jobs[0].NextRun.Equal(time.Date(2026, time.March, 3, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected job info: %+v", jobs)
+	}
+
+	if _, err := s.RunNow(context.Background(), "missing", "acme", true); err == nil {
+		t.Error("unknown job should fail")
+	}
+}
+
+func TestScheduler_RecordsFailures(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, time.March, 2, 3, 0, 0, 0, time.UTC)}
+	s := NewScheduler(clock, nopLogger{})
+	_ = s.Register(Job{
+		Name:     "broken",
+		Schedule: "@hourly",
+		Run: func(ctx context.Context, now time.Time, tenantID string, dryRun bool) (*models.JobReport, error) {
+			return nil, errors.New("boom")
+		},
+	})
+
+	run, err := s.RunNow(context.Background(), "broken", "acme", true)
+	if err != nil {
+		t.Fatalf("run now: %v", err)
+	}
+	if run.Error != "boom" {
+		t.Errorf("run error = %q, want boom", run.Error)
+	}
+}
+
+func TestScheduler_TenantScope(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, time.March, 2, 3, 0, 0, 0, time.UTC)}
+	s := NewScheduler(clock, nopLogger{})
+
+	var tenants []string
+	_ = s.Register(Job{
+		Name:     "nightly",
+		Schedule: "0 3 * * *",
+		Run: func(ctx context.Context, now time.Time, tenantID string, dryRun bool) (*models.JobReport, error) {
+			tenants = append(tenants, tenantID)
+			report := &models.JobReport{DryRun: dryRun}
+			for _, tenant := range []string{"acme", "globex"} {
+				if tenantID == "" || tenantID == tenant {
+					report.Affected = append(report.Affected, models.AffectedUser{TenantID: tenant, UserID: "user_1"})
+				}
+			}
+			return report, nil
+		},
+	})
+
+	s.RunDue(context.Background())
+	if _, err := s.RunNow(context.Background(), "nightly", "acme", true); err != nil {
+		t.Fatalf("run now: %v", err)
+	}
+	if _, err := s.RunNow(context.Background(), "nightly", "globex", true); err != nil {
+		t.Fatalf("run now: %v", err)
+	}
+	if _, err := s.RunNow(context.Background(), "nightly", "", true); err == nil {
+		t.Error("a manual run for every tenant should fail")
+	}
+	if len(tenants) != 3 || tenants[0] != "" || tenants[1] != "acme" || tenants[2] != "globex" {
+		t.Errorf("jobs ran for tenants %q", tenants)
+	}
+
+	history := s.History("", "acme", 10)
+	if len(history) != 2 || history[0].TenantID != "acme" || history[1].Trigger != models.JobTriggerSchedule {
+		t.Fatalf("acme history = %+v", history)
+	}
+	for _, run := range history {
+		for _, affected := range run.Report.Affected {
+			if affected.TenantID != "acme" {
+				t.Errorf("acme sees %+v of run %s", affected, run.ID)
+			}
+		}
+	}
+	if all := s.History("", "", 10); len(all) != 3 || len(all[2].Report.Affected) != 2 {
+		t.Errorf("unscoped history = %+v", all)
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/usecases"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// avatarFormField is the multipart field carrying the image
+const avatarFormField = "avatar"
+
+// AvatarHandler handles HTTP requests for user avatars
+type AvatarHandler struct {
+	avatarUseCase *usecases.AvatarUseCase
+}
+
+// NewAvatarHandler creates a new avatar handler
+func NewAvatarHandler(avatarUseCase *usecases.AvatarUseCase) *AvatarHandler {
+	return &AvatarHandler{
+		avatarUseCase: avatarUseCase,
+	}
+}
+
+// AvatarResponse is returned after a successful upload
+type AvatarResponse struct {
+	User *models.User      `json:"user"`
+	URLs map[string]string `json:"urls"`
+}
+
+// UploadAvatar handles PUT /users/{id}/avatar
+func (h *AvatarHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	// Leave room for multipart boundaries and headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, models.MaxAvatarUploadBytes+64<<10)
+	file, _, err := r.FormFile(avatarFormField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteJSONError(w, http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", fmt.Sprintf("Avatar must be at most %d bytes", models.MaxAvatarUploadBytes))
+			return
+		}
+		WriteJSONError(w, http.StatusBadRequest, "INVALID_UPLOAD", `Expected a multipart form with an "avatar" file`)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxAvatarUploadBytes+1))
+	if err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "INVALID_UPLOAD", "Failed to read uploaded file")
+		return
+	}
+
+	user, err := h.avatarUseCase.UploadAvatar(ctx, userID, data)
+	if err != nil {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
+			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
+			return
+		}
+
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			WriteJSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+
+		if errors.Is(err, models.ErrUnsupportedImage) {
+			WriteJSONError(w, http.StatusUnsupportedMediaType, "UNSUPPORTED_IMAGE", "Avatar must be a PNG, JPEG or GIF image")
+			return
+		}
+
+		if errors.Is(err, models.ErrUserErased) {
+			WriteJSONError(w, http.StatusConflict, "USER_ERASED", "User has been erased")
+			return
+		}
+
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
+		WriteJSONError(w, http.StatusInternalServerError, "UPLOAD_FAILED", "Failed to upload avatar")
+		return
+	}
+
+	urls := make(map[string]string, len(user.Avatar.Sizes))
+	for _, size := range user.Avatar.Sizes {
+		urls[strconv.Itoa(size)] = fmt.Sprintf("/api/users/%s/avatar?size=%d&v=%s", user.ID, size, user.Avatar.Version)
+	}
+
+	WriteJSONResponse(w, http.StatusOK, AvatarResponse{User: user, URLs: urls})
+}
+
+// GetAvatar handles GET /users/{id}/avatar
+func (h *AvatarHandler) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	size := models.DefaultAvatarSize
+	if s := r.URL.Query().Get("size"); s != "" {
+		parsed, err := strconv.Atoi(s)
+		if err != nil {
+			WriteJSONError(w, http.StatusBadRequest, "INVALID_SIZE", "Size must be an integer")
+			return
+		}
+		size = parsed
+	}
+
+	avatar, err := h.avatarUseCase.GetAvatar(ctx, userID, size)
+	if err != nil {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
+			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
+			return
+		}
+
+		if errors.Is(err, models.ErrAvatarNotFound) {
+			WriteJSONError(w, http.StatusNotFound, "AVATAR_NOT_FOUND", "User has no avatar")
+			return
+		}
+
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			WriteJSONError(w, http.StatusBadRequest, "INVALID_SIZE", err.Error())
+			return
+		}
+
+		WriteJSONError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		return
+	}
+
+	// Versioned URLs never change content; unversioned ones must revalidate.
+	// Avatars are personal data: only the browser may keep them, so that no
+	// shared cache serves one after its user is erased or purged.
+	if r.URL.Query().Get("v") == avatar.Version {
+		w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	w.Header().Set("Vary", "X-Tenant-ID")
+	w.Header().Set("Content-Type", avatar.ContentType)
+	w.Header().Set("ETag", avatar.ETag)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// ServeContent answers If-None-Match / If-Modified-Since with 304
+	http.ServeContent(w, r, "", avatar.UpdatedAt, bytes.NewReader(avatar.Data))
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/infrastructure/blobstore"
+	"golang-patterns/internal/infrastructure/repositories"
+	"golang-patterns/internal/usecases"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, fields ...interface{})  {}
+func (nopLogger) Error(msg string, fields ...interface{}) {}
+func (nopLogger) Debug(msg string, fields ...interface{}) {}
+
+func (nopLogger) InfoContext(ctx context.Context, msg string, fields ...interface{})  {}
+func (nopLogger) ErrorContext(ctx context.Context, msg string, fields ...interface{}) {}
+func (nopLogger) DebugContext(ctx context.Context, msg string, fields ...interface{}) {}
+
+// newTestBlobStore stores blobs in a directory removed after the test
+func newTestBlobStore(t *testing.T) *blobstore.LocalBlobStore {
+	t.Helper()
+	store, err := blobstore.NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	return store
+}
+
+func TestAvatarHandler_CachesOnlyPrivately(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	blobStore := newTestBlobStore(t)
+	userUC := usecases.NewUserUseCase(userRepo, repositories.NewMemoryAuditRepository(), repositories.NewMemoryUnitOfWork(), blobStore, nopLogger{})
+	avatarUC := usecases.NewAvatarUseCase(userRepo, blobStore, nopLogger{})
+	ctx := context.Background()
+
+	user, err := userUC.CreateUser(ctx, &models.UserCreateRequest{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 100, 100))); err != nil {
+		t.Fatal(err)
+	}
+	user, err = avatarUC.UploadAvatar(ctx, user.ID, buf.Bytes())
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/users/{id}/avatar", NewAvatarHandler(avatarUC).GetAvatar).Methods("GET")
+
+	tests := map[string]string{
+		fmt.Sprintf("/api/users/%s/avatar?size=64&v=%s", user.ID, user.Avatar.Version): "private, max-age=31536000, immutable",
+		fmt.Sprintf("/api/users/%s/avatar?size=64", user.ID):                           "private, no-cache",
+	}
+	for target, want := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", target, rr.Code, rr.Body.String())
+		}
+		if got := rr.Header().Get("Cache-Control"); got != want {
+			t.Errorf("%s: Cache-Control = %q, want %q", target, got, want)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Batch limits
+const (
+	MaxBatchRequests        = 20
+	MaxBatchBodyBytes       = 1 << 20
+	DefaultBatchConcurrency = 4
+	batchTimeout            = 30 * time.Second
+	batchPath               = "/api/batch"
+)
+
+// batchInheritedHeaders are copied from the batch request to every
+// sub-request so they run as the same tenant and share its request ID
+var batchInheritedHeaders = []string{"Authorization", "X-Tenant-ID", "X-Request-ID", "Accept-Language"}
+
+// batchMethods are the methods a sub-request may use
+var batchMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// BatchRequest is the body of POST /batch
+type BatchRequest struct {
+	Requests []BatchSubRequest `json:"requests"`
+}
+
+// BatchSubRequest is one API call in a batch. ID defaults to the
+// sub-request's index. A sub-request listed in DependsOn runs first; if it
+// fails, the dependent sub-request is skipped with 424 Failed Dependency.
+type BatchSubRequest struct {
+	ID        string            `json:"id,omitempty"`
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Body      json.RawMessage   `json:"body,omitempty"`
+	DependsOn []string          `json:"depends_on,omitempty"`
+}
+
+// BatchSubResponse is the result of one sub-request. JSON bodies are
+// embedded as is; other bodies are returned as a string, base64-encoded
+// when they are not valid UTF-8.
+type BatchSubResponse struct {
+	ID           string            `json:"id"`
+	Status       int               `json:"status"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         json.RawMessage   `json:"body,omitempty"`
+	BodyEncoding string            `json:"body_encoding,omitempty"`
+}
+
+// BatchResponse is the response of POST /batch, in request order
+type BatchResponse struct {
+	Responses []BatchSubResponse `json:"responses"`
+}
+
+// BatchHandler executes several API calls in one round trip by dispatching
+// them through the application's router in-process
+type BatchHandler struct {
+	router      http.Handler
+	concurrency int
+}
+
+// NewBatchHandler creates a batch handler that dispatches sub-requests to
+// router, running at most concurrency of them at a time
+func NewBatchHandler(router http.Handler, concurrency int) *BatchHandler {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	return &BatchHandler{
+		router:      router,
+		concurrency: concurrency,
+	}
+}
+
+// ExecuteBatch handles POST /batch
+func (h *BatchHandler) ExecuteBatch(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), batchTimeout)
+	defer cancel()
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBatchBodyBytes)
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteJSONError(w, http.StatusRequestEntityTooLarge, "BATCH_TOO_LARGE", fmt.Sprintf("Batch body must be at most %d bytes", MaxBatchBodyBytes))
+			return
+		}
+		WriteJSONError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid JSON format")
+		return
+	}
+
+	deps, err := validateBatch(req.Requests)
+	if err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "INVALID_BATCH", err.Error())
+		return
+	}
+
+	// The request ID middleware has already chosen this request's ID
+	inherited := make(http.Header)
+	for _, name := range batchInheritedHeaders {
+		if value := r.Header.Get(name); value != "" {
+			inherited.Set(name, value)
+		}
+	}
+	if requestID := w.Header().Get("X-Request-ID"); requestID != "" {
+		inherited.Set("X-Request-ID", requestID)
+	}
+
+	responses := h.execute(ctx, r.Host, inherited, req.Requests, deps)
+	WriteJSONResponse(w, http.StatusOK, BatchResponse{Responses: responses})
+}
+
+// execute runs the sub-requests, each as soon as its dependencies are done
+// and a concurrency slot is free
+func (h *BatchHandler) execute(ctx context.Context, host string, inherited http.Header, requests []BatchSubRequest, deps [][]int) []BatchSubResponse {
+	results := make([]BatchSubResponse, len(requests))
+	done := make([]chan struct{}, len(requests))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	slots := make(chan struct{}, h.concurrency)
+
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(done[i])
+
+			sub := requests[i]
+			for _, dep := range deps[i] {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					results[i] = batchErrorResponse(sub.ID, http.StatusGatewayTimeout, "TIMEOUT", "Batch timed out")
+					return
+				}
+				if results[dep].Status >= 400 {
+					results[i] = batchErrorResponse(sub.ID, http.StatusFailedDependency, "FAILED_DEPENDENCY",
+						fmt.Sprintf("Dependency %q failed with status %d", requests[dep].ID, results[dep].Status))
+					return
+				}
+			}
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i] = batchErrorResponse(sub.ID, http.StatusGatewayTimeout, "TIMEOUT", "Batch timed out")
+				return
+			}
+
+			results[i] = h.dispatch(ctx, host, inherited, sub)
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// dispatch serves one sub-request through the router
+func (h *BatchHandler) dispatch(ctx context.Context, host string, inherited http.Header, sub BatchSubRequest) (resp BatchSubResponse) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			resp = batchErrorResponse(sub.ID, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, sub.Method, sub.Path, bytes.NewReader(sub.Body))
+	if err != nil {
+		return batchErrorResponse(sub.ID, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+	}
+	req.Host = host
+	req.RemoteAddr = "batch"
+	req.Header = inherited.Clone()
+	for name, value := range sub.Headers {
+		req.Header.Set(name, value)
+	}
+	if len(sub.Body) > 0 && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rec := &batchResponseRecorder{header: make(http.Header)}
+	h.router.ServeHTTP(rec, req)
+	return rec.result(sub.ID)
+}
+
+// validateBatch checks the sub-requests, assigns default IDs and resolves
+// dependencies to indexes. Dependency cycles are rejected.
+func validateBatch(requests []BatchSubRequest) ([][]int, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("at least one request is required")
+	}
+	if len(requests) > MaxBatchRequests {
+		return nil, fmt.Errorf("a batch may contain at most %d requests", MaxBatchRequests)
+	}
+
+	index := make(map[string]int, len(requests))
+	for i := range requests {
+		sub := &requests[i]
+		if sub.ID == "" {
+			sub.ID = strconv.Itoa(i)
+		}
+		if _, exists := index[sub.ID]; exists {
+			return nil, fmt.Errorf("duplicate request id %q", sub.ID)
+		}
+		index[sub.ID] = i
+
+		sub.Method = strings.ToUpper(sub.Method)
+		if sub.Method == "" {
+			sub.Method = http.MethodGet
+		}
+		if !batchMethods[sub.Method] {
+			return nil, fmt.Errorf("request %q: unsupported method %s", sub.ID, sub.Method)
+		}
+		if err := validateBatchPath(sub.Path); err != nil {
+			return nil, fmt.Errorf("request %q: %w", sub.ID, err)
+		}
+	}
+
+	deps := make([][]int, len(requests))
+	for i, sub := range requests {
+		for _, depID := range sub.DependsOn {
+			dep, exists := index[depID]
+			if !exists {
+				return nil, fmt.Errorf("request %q depends on unknown request %q", sub.ID, depID)
+			}
+			if dep == i {
+				return nil, fmt.Errorf("request %q depends on itself", sub.ID)
+			}
+			deps[i] = append(deps[i], dep)
+		}
+	}
+
+	if cycle := findDependencyCycle(deps); cycle >= 0 {
+		return nil, fmt.Errorf("request %q is part of a dependency cycle", requests[cycle].ID)
+	}
+
+	return deps, nil
+}
+
+// validateBatchPath only allows relative API paths other than the batch endpoint itself
+func validateBatchPath(path string) error {
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(u.Path, "/api/") {
+		return fmt.Errorf("path must be an API path such as /api/users")
+	}
+	if strings.TrimSuffix(u.Path, "/") == batchPath {
+		return fmt.Errorf("batch requests cannot be nested")
+	}
+	return nil
+}
+
+// findDependencyCycle returns the index of a request on a dependency cycle, or -1
+func findDependencyCycle(deps [][]int) int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(deps))
+
+	var visit func(i int) int
+	visit = func(i int) int {
+		state[i] = visiting
+		for _, dep := range deps[i] {
+			switch state[dep] {
+			case visiting:
+				return dep
+			case unvisited:
+				if cycle := visit(dep); cycle >= 0 {
+					return cycle
+				}
+			}
+		}
+		state[i] = visited
+		return -1
+	}
+
+	for i := range deps {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle >= 0 {
+				return cycle
+			}
+		}
+	}
+	return -1
+}
+
+// batchErrorResponse builds a sub-response in the standard error envelope
+func batchErrorResponse(id string, status int, code, message string) BatchSubResponse {
+	body, _ := json.Marshal(APIResponse{Success: false, Error: &APIError{Code: code, Message: message}})
+	return BatchSubResponse{
+		ID:      id,
+		Status:  status,
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    body,
+	}
+}
+
+// batchResponseRecorder captures a sub-response
+type batchResponseRecorder struct {
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rec *batchResponseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *batchResponseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+}
+
+func (rec *batchResponseRecorder) Write(p []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	return rec.body.Write(p)
+}
+
+// result converts the recorded response. CORS headers are dropped because
+// they only apply to the batch response itself.
+func (rec *batchResponseRecorder) result(id string) BatchSubResponse {
+	if !rec.wroteHeader {
+		rec.status = http.StatusOK
+	}
+
+	resp := BatchSubResponse{ID: id, Status: rec.status, Headers: make(map[string]string)}
+	for name, values := range rec.header {
+		if strings.HasPrefix(name, "Access-Control-") || name == "Vary" {
+			continue
+		}
+		resp.Headers[name] = values[0]
+	}
+
+	body := rec.body.Bytes()
+	switch {
+	case len(body) == 0:
+	case strings.Contains(rec.header.Get("Content-Type"), "json") && json.Valid(body):
+		resp.Body = json.RawMessage(body)
+	case utf8.Valid(body):
+		resp.Body, _ = json.Marshal(string(body))
+	default:
+		resp.Body, _ = json.Marshal(body)
+		resp.BodyEncoding = "base64"
+	}
+	return resp
+}
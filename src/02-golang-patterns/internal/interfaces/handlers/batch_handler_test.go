@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"golang-patterns/internal/infrastructure/repositories"
+	"golang-patterns/internal/usecases"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// batchTestRouter serves the real user routes plus probes for headers,
+// concurrency and panics
+type batchTestRouter struct {
+	router  *mux.Router
+	running int32
+	peak    int32
+}
+
+func newBatchTestRouter(t *testing.T, concurrency int) *batchTestRouter {
+	t.Helper()
+	uc := usecases.NewUserUseCase(repositories.NewMemoryUserRepository(), repositories.NewMemoryAuditRepository(), repositories.NewMemoryUnitOfWork(), newTestBlobStore(t), nopLogger{})
+	userHandler := NewUserHandler(uc)
+
+	tr := &batchTestRouter{router: mux.NewRouter()}
+	api := tr.router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/batch", NewBatchHandler(tr.router, concurrency).ExecuteBatch).Methods("POST")
+	api.HandleFunc("/users/stats", userHandler.GetUserStats).Methods("GET")
+	api.HandleFunc("/users", userHandler.CreateUser).Methods("POST")
+	api.HandleFunc("/users/{id}", userHandler.GetUser).Methods("GET")
+	api.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSONResponse(w, http.StatusOK, map[string]string{
+			"tenant":     r.Header.Get("X-Tenant-ID"),
+			"request_id": r.Header.Get("X-Request-ID"),
+			"custom":     r.Header.Get("X-Custom"),
+		})
+	})
+	api.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		running := atomic.AddInt32(&tr.running, 1)
+		defer atomic.AddInt32(&tr.running, -1)
+		for {
+			peak := atomic.LoadInt32(&tr.peak)
+			if running <= peak || atomic.CompareAndSwapInt32(&tr.peak, peak, running) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+	api.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	return tr
+}
+
+func (tr *batchTestRouter) batch(t *testing.T, body string, header http.Header) (int, []BatchSubResponse) {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/batch", strings.NewReader(body))
+	for name := range header {
+		req.Header.Set(name, header.Get(name))
+	}
+	rr := httptest.NewRecorder()
+	tr.router.ServeHTTP(rr, req)
+
+	var envelope struct {
+		Data BatchResponse `json:"data"`
+	}
+	if rr.Code == http.StatusOK {
+		if err := json.Unmarshal(rr.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("decode batch response: %v", err)
+		}
+	}
+	return rr.Code, envelope.Data.Responses
+}
+
+func TestBatchHandler(t *testing.T) {
+	t.Run("dependent requests run in order", func(t *testing.T) {
+		tr := newBatchTestRouter(t, 4)
+		status, responses := tr.batch(t, `{"requests": [
+			{"id": "create", "method": "POST", "path": "/api/users", "body": {"name": "Alice", "email": "alice@example.com"}},
+			{"id": "get", "method": "GET", "path": "/api/users/user_1", "depends_on": ["create"]},
+			{"id": "stats", "path": "/api/users/stats", "depends_on": ["create"]}
+		]}`, nil)
+		if status != http.StatusOK {
+			t.Fatalf("status = %d", status)
+		}
+		if len(responses) != 3 {
+			t.Fatalf("got %d responses, want 3", len(responses))
+		}
+
+		wantStatus := map[string]int{"create": http.StatusCreated, "get": http.StatusOK, "stats": http.StatusOK}
+		for _, resp := range responses {
+			if resp.Status != wantStatus[resp.ID] {
+				t.Errorf("%s status = %d, want %d: %s", resp.ID, resp.Status, wantStatus[resp.ID], resp.Body)
+			}
+		}
+		if !bytes.Contains(responses[1].Body, []byte(`"alice@example.com"`)) {
+			t.Errorf("get did not see the created user: %s", responses[1].Body)
+		}
+		if !bytes.Contains(responses[2].Body, []byte(`"total_users":1`)) {
+			t.Errorf("stats did not see the created user: %s", responses[2].Body)
+		}
+		if responses[0].Headers["Content-Type"] != "application/json" {
+			t.Errorf("headers = %v", responses[0].Headers)
+		}
+	})
+
+	t.Run("failed dependency skips dependents", func(t *testing.T) {
+		tr := newBatchTestRouter(t, 4)
+		_, responses := tr.batch(t, `{"requests": [
+			{"id": "missing", "path": "/api/users/user_404"},
+			{"id": "child", "path": "/api/users/stats", "depends_on": ["missing"]},
+			{"id": "grandchild", "path": "/api/users/stats", "depends_on": ["child"]},
+			{"id": "independent", "path": "/api/users/stats"}
+		]}`, nil)
+
+		want := []int{http.StatusNotFound, http.StatusFailedDependency, http.StatusFailedDependency, http.StatusOK}
+		for i, resp := range responses {
+			if resp.Status != want[i] {
+				t.Errorf("%s status = %d, want %d", resp.ID, resp.Status, want[i])
+			}
+		}
+	})
+
+	t.Run("concurrency is bounded", func(t *testing.T) {
+		tr := newBatchTestRouter(t, 2)
+		var requests []string
+		for i := 0; i < 8; i++ {
+			requests = append(requests, `{"path": "/api/slow"}`)
+		}
+		_, responses := tr.batch(t, `{"requests": [`+strings.Join(requests, ",")+`]}`, nil)
+
+		for _, resp := range responses {
+			if resp.Status != http.StatusOK || string(resp.Body) != `"done"` {
+				t.Errorf("%s = %d %s", resp.ID, resp.Status, resp.Body)
+			}
+		}
+		if peak := atomic.LoadInt32(&tr.peak); peak != 2 {
+			t.Errorf("peak concurrency = %d, want 2", peak)
+		}
+	})
+
+	t.Run("headers are inherited and can be extended", func(t *testing.T) {
+		tr := newBatchTestRouter(t, 4)
+		header := http.Header{}
+		header.Set("X-Tenant-ID", "acme")
+		header.Set("X-Request-ID", "req-123")
+		_, responses := tr.batch(t, `{"requests": [{"path": "/api/echo", "headers": {"X-Custom": "yes"}}]}`, header)
+
+		var echo struct {
+			Data map[string]string `json:"data"`
+		}
+		if err := json.Unmarshal(responses[0].Body, &echo); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if echo.Data["tenant"] != "acme" || echo.Data["request_id"] != "req-123" || echo.Data["custom"] != "yes" {
+			t.Errorf("sub-request headers = %v", echo.Data)
+		}
+	})
+
+	t.Run("panics become 500 responses", func(t *testing.T) {
+		tr := newBatchTestRouter(t, 4)
+		_, responses := tr.batch(t, `{"requests": [{"path": "/api/panic"}, {"path": "/api/users/stats"}]}`, nil)
+		if responses[0].Status != http.StatusInternalServerError || responses[1].Status != http.StatusOK {
+			t.Errorf("statuses = %d, %d", responses[0].Status, responses[1].Status)
+		}
+	})
+
+	t.Run("invalid batches are rejected", func(t *testing.T) {
+		tr := newBatchTestRouter(t, 4)
+		tooMany := `{"requests": [` + strings.TrimSuffix(strings.Repeat(`{"path": "/api/users"},`, MaxBatchRequests+1), ",") + `]}`
+
+		tests := map[string]string{
+			"malformed":    `{"requests": [`,
+			"empty":        `{"requests": []}`,
+			"too many":     tooMany,
+			"duplicate id": `{"requests": [{"id": "a", "path": "/api/users"}, {"id": "a", "path": "/api/users"}]}`,
+			"unknown dep":  `{"requests": [{"path": "/api/users", "depends_on": ["nope"]}]}`,
+			"cycle":        `{"requests": [{"id": "a", "path": "/api/users", "depends_on": ["b"]}, {"id": "b", "path": "/api/users", "depends_on": ["a"]}]}`,
+			"self":         `{"requests": [{"id": "a", "path": "/api/users", "depends_on": ["a"]}]}`,
+			"nested batch": `{"requests": [{"method": "POST", "path": "/api/batch"}]}`,
+			"absolute url": `{"requests": [{"path": "http://example.com/api/users"}]}`,
+			"non-api path": `{"requests": [{"path": "/health"}]}`,
+			"bad method":   `{"requests": [{"method": "CONNECT", "path": "/api/users"}]}`,
+		}
+		for name, body := range tests {
+			if status, _ := tr.batch(t, body, nil); status != http.StatusBadRequest {
+				t.Errorf("%s: status = %d, want 400", name, status)
+			}
+		}
+	})
+
+	t.Run("oversized bodies are rejected", func(t *testing.T) {
+		tr := newBatchTestRouter(t, 4)
+		body := `{"requests": [{"path": "/api/users", "body": "` + strings.Repeat("x", MaxBatchBodyBytes) + `"}]}`
+		if status, _ := tr.batch(t, body, nil); status != http.StatusRequestEntityTooLarge {
+			t.Errorf("status = %d, want 413", status)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"golang-patterns/internal/domain/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// JobRunner is the scheduler functionality exposed over HTTP
+type JobRunner interface {
+	Jobs() []models.JobInfo
+	History(job, tenantID string, limit int) []*models.JobRun
+	RunNow(ctx context.Context, name, tenantID string, dryRun bool) (*models.JobRun, error)
+}
+
+// JobHandler handles HTTP requests for scheduled lifecycle jobs. Manual runs
+// and the run history are limited to the tenant of the request.
+type JobHandler struct {
+	runner JobRunner
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(runner JobRunner) *JobHandler {
+	return &JobHandler{
+		runner: runner,
+	}
+}
+
+// ListJobs handles GET /jobs
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	WriteJSONResponse(w, http.StatusOK, h.runner.Jobs())
+}
+
+// GetJobRuns handles GET /jobs/runs
+func (h *JobHandler) GetJobRuns(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	tenantID := models.TenantFromContext(r.Context())
+	WriteJSONResponse(w, http.StatusOK, h.runner.History(r.URL.Query().Get("job"), tenantID, limit))
+}
+
+// RunJob handles POST /jobs/{name}/run
+func (h *JobHandler) RunJob(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	name := vars["name"]
+
+	// Manual runs are dry runs unless explicitly disabled
+	dryRun := true
+	if dryRunStr := r.URL.Query().Get("dry_run"); dryRunStr != "" {
+		d, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			WriteJSONError(w, http.StatusBadRequest, "INVALID_PARAMS", "dry_run must be a boolean")
+			return
+		}
+		dryRun = d
+	}
+
+	run, err := h.runner.RunNow(ctx, name, models.TenantFromContext(ctx), dryRun)
+	if err != nil {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
+			WriteJSONError(w, http.StatusNotFound, "JOB_NOT_FOUND", "Job not found")
+			return
+		}
+
+		if errors.Is(err, models.ErrJobRunning) {
+			WriteJSONError(w, http.StatusConflict, "JOB_RUNNING", err.Error())
+			return
+		}
+
+		WriteJSONError(w, http.StatusInternalServerError, "JOB_FAILED", "Failed to run job")
+		return
+	}
+
+	if run.Error != "" {
+		WriteJSONResponse(w, http.StatusInternalServerError, run)
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, run)
+}
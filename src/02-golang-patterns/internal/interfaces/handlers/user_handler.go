@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"golang-patterns/internal/domain/models"
 	"golang-patterns/internal/usecases"
 	"net/http"
@@ -39,10 +40,15 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userUseCase.CreateUser(ctx, &req)
 	if err != nil {
-		if _, ok := err.(*models.ValidationError); ok {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
 			WriteJSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 			return
 		}
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
 		WriteJSONError(w, http.StatusInternalServerError, "CREATION_FAILED", "Failed to create user")
 		return
 	}
@@ -70,7 +76,8 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if _, ok := err.(models.NotFoundError); ok {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
 			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
 			return
 		}
@@ -103,16 +110,22 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userUseCase.UpdateUser(ctx, userID, &req)
 	if err != nil {
-		if _, ok := err.(*models.ValidationError); ok {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
 			WriteJSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 			return
 		}
 
-		if _, ok := err.(models.NotFoundError); ok {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
 			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
 			return
 		}
 
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
 		WriteJSONError(w, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update user")
 		return
 	}
@@ -120,7 +133,7 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	WriteJSONResponse(w, http.StatusOK, user)
 }
 
-// DeleteUser handles DELETE /users/{id}
+// DeleteUser handles DELETE /users/{id}, soft-deleting the user
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
@@ -135,11 +148,16 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	err := h.userUseCase.DeleteUser(ctx, userID)
 	if err != nil {
-		if _, ok := err.(models.NotFoundError); ok {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
 			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
 			return
 		}
 
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
 		WriteJSONError(w, http.StatusInternalServerError, "DELETE_FAILED", "Failed to delete user")
 		return
 	}
@@ -173,7 +191,8 @@ func (h *UserHandler) GetUserByEmail(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userUseCase.GetUserByEmail(ctx, email)
 	if err != nil {
-		if _, ok := err.(models.NotFoundError); ok {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
 			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
 			return
 		}
@@ -409,10 +428,15 @@ func (h *UserHandler) CreateUsersInBulk(w http.ResponseWriter, r *http.Request)
 
 	users, err := h.userUseCase.CreateUsersInBulk(ctx, requests)
 	if err != nil {
-		if _, ok := err.(*models.ValidationError); ok {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
 			WriteJSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 			return
 		}
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
 		WriteJSONError(w, http.StatusInternalServerError, "BULK_CREATE_FAILED", "Failed to create users in bulk")
 		return
 	}
@@ -433,10 +457,15 @@ func (h *UserHandler) UpdateUsersInBulk(w http.ResponseWriter, r *http.Request)
 
 	users, err := h.userUseCase.UpdateUsersInBulk(ctx, updates)
 	if err != nil {
-		if _, ok := err.(*models.ValidationError); ok {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
 			WriteJSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 			return
 		}
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
 		WriteJSONError(w, http.StatusInternalServerError, "BULK_UPDATE_FAILED", "Failed to update users in bulk")
 		return
 	}
@@ -444,7 +473,7 @@ func (h *UserHandler) UpdateUsersInBulk(w http.ResponseWriter, r *http.Request)
 	WriteJSONResponse(w, http.StatusOK, users)
 }
 
-// DeleteUsersInBulk handles DELETE /users/bulk
+// DeleteUsersInBulk handles DELETE /users/bulk, soft-deleting the users
 func (h *UserHandler) DeleteUsersInBulk(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
@@ -459,10 +488,15 @@ func (h *UserHandler) DeleteUsersInBulk(w http.ResponseWriter, r *http.Request)
 
 	err := h.userUseCase.DeleteUsersInBulk(ctx, request.IDs)
 	if err != nil {
-		if _, ok := err.(*models.ValidationError); ok {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
 			WriteJSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 			return
 		}
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
 		WriteJSONError(w, http.StatusInternalServerError, "BULK_DELETE_FAILED", "Failed to delete users in bulk")
 		return
 	}
@@ -482,11 +516,16 @@ func (h *UserHandler) ActivateUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userUseCase.ActivateUser(ctx, userID)
 	if err != nil {
-		if _, ok := err.(models.NotFoundError); ok {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
 			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
 			return
 		}
 
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
 		WriteJSONError(w, http.StatusInternalServerError, "ACTIVATION_FAILED", "Failed to activate user")
 		return
 	}
@@ -504,11 +543,16 @@ func (h *UserHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userUseCase.DeactivateUser(ctx, userID)
 	if err != nil {
-		if _, ok := err.(models.NotFoundError); ok {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
 			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
 			return
 		}
 
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
 		WriteJSONError(w, http.StatusInternalServerError, "DEACTIVATION_FAILED", "Failed to deactivate user")
 		return
 	}
@@ -526,11 +570,16 @@ func (h *UserHandler) UpdateLastLogin(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userUseCase.UpdateLastLogin(ctx, userID)
 	if err != nil {
-		if _, ok := err.(models.NotFoundError); ok {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
 			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
 			return
 		}
 
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
 		WriteJSONError(w, http.StatusInternalServerError, "LOGIN_UPDATE_FAILED", "Failed to update last login")
 		return
 	}
@@ -548,7 +597,8 @@ func (h *UserHandler) GetUserSummary(w http.ResponseWriter, r *http.Request) {
 
 	summary, err := h.userUseCase.GetUserSummary(ctx, userID)
 	if err != nil {
-		if _, ok := err.(models.NotFoundError); ok {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
 			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
 			return
 		}
@@ -558,4 +608,141 @@ func (h *UserHandler) GetUserSummary(w http.ResponseWriter, r *http.Request) {
 	}
 
 	WriteJSONResponse(w, http.StatusOK, summary)
-}
\ No newline at end of file
+}
+
+// === Duplicate Detection and Merge ===
+
+// FindDuplicateUsers handles GET /users/duplicates
+func (h *UserHandler) FindDuplicateUsers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	threshold := models.DefaultDuplicateThreshold
+	if thresholdStr := r.URL.Query().Get("threshold"); thresholdStr != "" {
+		t, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil {
+			WriteJSONError(w, http.StatusBadRequest, "INVALID_PARAMS", "threshold must be a number")
+			return
+		}
+		threshold = t
+	}
+
+	candidates, err := h.userUseCase.FindDuplicateUsers(ctx, threshold)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			WriteJSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+
+		WriteJSONError(w, http.StatusInternalServerError, "DUPLICATE_DETECTION_FAILED", "Failed to find duplicate users")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, candidates)
+}
+
+// MergeUsers handles POST /users/merge
+func (h *UserHandler) MergeUsers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	var req models.MergeUsersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteJSONError(w, http.StatusBadRequest, "INVALID_JSON", "Invalid JSON format")
+		return
+	}
+
+	user, err := h.userUseCase.MergeUsers(ctx, &req)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			WriteJSONError(w, http.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+			return
+		}
+
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
+			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", err.Error())
+			return
+		}
+
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
+		WriteJSONError(w, http.StatusInternalServerError, "MERGE_FAILED", "Failed to merge users")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, user)
+}
+
+// === Personal Data Requests ===
+
+// ExportUserData handles GET /users/{id}/export
+func (h *UserHandler) ExportUserData(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	export, err := h.userUseCase.ExportUserData(ctx, userID)
+	if err != nil {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
+			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
+			return
+		}
+
+		WriteJSONError(w, http.StatusInternalServerError, "EXPORT_FAILED", "Failed to export user data")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="`+userID+`-export.json"`)
+	WriteJSONResponse(w, http.StatusOK, export)
+}
+
+// EraseUser handles POST /users/{id}/erase
+func (h *UserHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	user, err := h.userUseCase.EraseUser(ctx, userID)
+	if err != nil {
+		var notFound models.NotFoundError
+		if errors.As(err, &notFound) {
+			WriteJSONError(w, http.StatusNotFound, "USER_NOT_FOUND", "User not found")
+			return
+		}
+
+		if errors.Is(err, models.ErrUserErased) {
+			WriteJSONError(w, http.StatusConflict, "ALREADY_ERASED", "User has already been erased")
+			return
+		}
+
+		if writeConcurrentUpdate(w, err) {
+			return
+		}
+
+		WriteJSONError(w, http.StatusInternalServerError, "ERASURE_FAILED", "Failed to erase user")
+		return
+	}
+
+	WriteJSONResponse(w, http.StatusOK, user)
+}
+
+// writeConcurrentUpdate answers 409 when err reports that the data was
+// changed by another request in the meantime, and reports whether it did.
+// Repeating the request usually succeeds.
+func writeConcurrentUpdate(w http.ResponseWriter, err error) bool {
+	if !errors.Is(err, models.ErrConcurrentUpdate) {
+		return false
+	}
+	WriteJSONError(w, http.StatusConflict, "CONCURRENT_UPDATE", "The data was changed by another request; please retry")
+	return true
+}
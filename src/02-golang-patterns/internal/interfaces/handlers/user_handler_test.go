@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/infrastructure/repositories"
+	"golang-patterns/internal/usecases"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// racingUserRepository loses every update to a concurrent writer
+type racingUserRepository struct {
+	*repositories.MemoryUserRepository
+}
+
+func (r racingUserRepository) Update(ctx context.Context, user *models.User) (*models.User, error) {
+	return nil, models.ErrConcurrentUpdate
+}
+
+func TestUserHandler_LostRaceIsConflict(t *testing.T) {
+	repo := racingUserRepository{repositories.NewMemoryUserRepository()}
+	uc := usecases.NewUserUseCase(repo, repositories.NewMemoryAuditRepository(), repositories.NewMemoryUnitOfWork(), newTestBlobStore(t), nopLogger{})
+	user, err := uc.CreateUser(context.Background(), &models.UserCreateRequest{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/users/{id}", NewUserHandler(uc).UpdateUser).Methods("PUT")
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("PUT", "/api/users/"+user.ID, strings.NewReader(`{"name":"Alice Liddell"}`)))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: %s", rr.Code, rr.Body.String())
+	}
+	var body APIResponse
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil || body.Error == nil || body.Error.Code != "CONCURRENT_UPDATE" {
+		t.Errorf("body = %+v (%v), want error CONCURRENT_UPDATE", body, err)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/interfaces/templates"
+	"golang-patterns/internal/usecases"
+	"net/http"
+	"shared/datatable"
+	"strconv"
+	"time"
+)
+
+// userTable lists users as HTML. Its sortable columns are those accepted
+// by models.SortParams.
+var userTable = &datatable.Table[*models.User]{
+	ID:  "users",
+	URL: "/users",
+	Columns: []datatable.Column[*models.User]{
+		{Key: "name", Label: "名前", Text: func(u *models.User) string { return u.Name }, Sortable: true, Filterable: true},
+		{Key: "email", Label: "メールアドレス", Text: func(u *models.User) string { return u.Email }, Sortable: true},
+		{Key: "department", Label: "部署", Text: func(u *models.User) string { return u.Department }},
+		{Key: "position", Label: "役職", Text: func(u *models.User) string { return u.Position }},
+		{Key: "age", Label: "年齢", Text: formatAge, Class: "text-right tabular-nums"},
+		{
+			Key: "status", Label: "状態", Text: formatStatus, Filterable: true,
+			Options: []datatable.Option{{Value: "active", Label: "有効"}, {Value: "inactive", Label: "無効"}},
+		},
+		{Key: "created_at", Label: "登録日時", Text: func(u *models.User) string { return u.CreatedAt.Format("2006-01-02 15:04") }, Sortable: true},
+	},
+	PageSizes: []int{10, 25, 50},
+	Sort:      "created_at",
+	Order:     datatable.Desc,
+	Empty:     "該当するユーザーがいません。",
+}
+
+func formatAge(u *models.User) string {
+	if u.Age == 0 {
+		return ""
+	}
+	return strconv.Itoa(u.Age)
+}
+
+func formatStatus(u *models.User) string {
+	if u.IsActive {
+		return "有効"
+	}
+	return "無効"
+}
+
+// UserTableHandler serves the users of the tenant as an HTML table
+type UserTableHandler struct {
+	source datatable.Source[*models.User]
+}
+
+// NewUserTableHandler creates a new user table handler
+func NewUserTableHandler(userUseCase *usecases.UserUseCase) *UserTableHandler {
+	return &UserTableHandler{
+		source: userSource{userUseCase: userUseCase},
+	}
+}
+
+// ServeHTTP handles GET /users. Sorting, filtering and paging through the
+// query string swap in the table alone.
+func (h *UserTableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	view, err := userTable.Load(ctx, h.source, r.URL.Query())
+	if err != nil {
+		http.Error(w, "Failed to get users", http.StatusInternalServerError)
+		return
+	}
+
+	if err := datatable.Render(w, r.WithContext(ctx), view, templates.UsersPage(view)); err != nil {
+		http.Error(w, "Failed to render users", http.StatusInternalServerError)
+	}
+}
+
+// userSource maps the state of the table to the query parameters of the
+// use case
+type userSource struct {
+	userUseCase *usecases.UserUseCase
+}
+
+func (s userSource) Query(ctx context.Context, q datatable.Query) (datatable.Result[*models.User], error) {
+	filter := &models.UserFilter{Name: q.Filter("name")}
+	if status := q.Filter("status"); status != "" {
+		active := status == "active"
+		filter.IsActive = &active
+	}
+	params := &models.QueryParams{
+		Filter:     filter,
+		Pagination: models.NewPaginationParams(q.Page, q.PageSize),
+		Sort:       models.NewSortParams(q.Sort, string(q.Order)),
+	}
+
+	result, err := s.userUseCase.GetUsersWithQuery(ctx, params)
+	if err != nil {
+		return datatable.Result[*models.User]{}, err
+	}
+	users, _ := result.Data.([]*models.User)
+	return datatable.Result[*models.User]{Rows: users, Total: result.Total}, nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/infrastructure/repositories"
+	"golang-patterns/internal/usecases"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUserTableHandler(t *testing.T) {
+	uc := usecases.NewUserUseCase(repositories.NewMemoryUserRepository(), repositories.NewMemoryAuditRepository(), repositories.NewMemoryUnitOfWork(), newTestBlobStore(t), nopLogger{})
+	ctx := context.Background()
+	for i, name := range []string{"Carol", "Alice", "Dave", "Bob"} {
+		user, err := uc.CreateUser(ctx, &models.UserCreateRequest{Name: name, Email: fmt.Sprintf("user%d@example.com", i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == "Dave" {
+			if _, err := uc.DeactivateUser(ctx, user.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	handler := NewUserTableHandler(uc)
+
+	t.Run("page", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/users?sort=name&filter_status=active", nil))
+
+		body := rr.Body.String()
+		if !strings.HasPrefix(body, "<!doctype html>") || !strings.Contains(body, `<form id="users"`) {
+			t.Fatalf("not a page with the table: %s", body)
+		}
+		alice, bob, carol := strings.Index(body, "Alice"), strings.Index(body, "Bob"), strings.Index(body, "Carol")
+		if alice < 0 || alice > bob || bob > carol {
+			t.Errorf("active users not sorted by name: %s", body)
+		}
+		if strings.Contains(body, "Dave") || !strings.Contains(body, "全3件中") {
+			t.Errorf("inactive user listed: %s", body)
+		}
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users?page_size=10&page=2&filter_name=a", nil)
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("HX-Target", "users-results")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		// Past the last page, the last page is shown
+		body := rr.Body.String()
+		if !strings.HasPrefix(body, `<div id="users-results"`) || !strings.Contains(body, "全3件中 1–3件を表示") {
+			t.Errorf("refresh = %s", body)
+		}
+	})
+	t.Run("huge page", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest("GET", "/users?page=1000000000000000000", nil))
+
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "全4件中 1–4件を表示") {
+			t.Errorf("huge page = %d %s", rr.Code, rr.Body.String())
+		}
+	})
+}
@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"golang-patterns/internal/domain/models"
+)
+
+// AuditRepository defines the interface for the append-only audit trail.
+// Like UserRepository, implementations are scoped to the context's tenant.
+type AuditRepository interface {
+	Record(ctx context.Context, entry *models.AuditEntry) (*models.AuditEntry, error)
+	ListByUser(ctx context.Context, userID string) ([]*models.AuditEntry, error)
+	ReassignUser(ctx context.Context, fromUserID, toUserID string) (int, error)
+}
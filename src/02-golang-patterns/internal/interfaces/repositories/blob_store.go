@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrBlobNotFound is returned by BlobStore.Get for unknown keys
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore defines the interface for storing binary objects such as avatars.
+// Keys are slash-separated paths chosen by the caller.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (data []byte, contentType string, err error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import "context"
+
+// UnitOfWork runs several repository calls atomically. Repository methods
+// called with the context passed to fn take part in the unit of work: if fn
+// returns an error, panics, or ctx is cancelled before it completes, all of
+// their writes are rolled back. Nested calls join the outer unit of work.
+// Do returns models.ErrConcurrentUpdate if the writes could not be applied
+// because the data they change was changed by someone else meanwhile.
+//
+// Only the memory repositories implement it so far; the chapter has no SQL
+// repositories. A SQL implementation would begin a *sql.Tx in Do, carry it
+// in the context passed to fn, and have its repositories run their queries
+// on the transaction found in their context.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -3,9 +3,14 @@ package repositories
 import (
 	"context"
 	"golang-patterns/internal/domain/models"
+	"time"
 )
 
-// UserRepository defines the interface for user data operations
+// UserRepository defines the interface for user data operations.
+// Implementations must scope every operation to the tenant returned by
+// models.TenantFromContext(ctx), including ID generation and email uniqueness.
+// Writes of existing users fail with models.ErrConcurrentUpdate when the
+// user's Version is not the stored one.
 type UserRepository interface {
 	// Basic CRUD operations
 	Create(ctx context.Context, user *models.User) (*models.User, error)
@@ -43,15 +48,28 @@ type UserRepository interface {
 	BulkCreate(ctx context.Context, users []*models.User) ([]*models.User, error)
 	BulkUpdate(ctx context.Context, users []*models.User) ([]*models.User, error)
 	BulkDelete(ctx context.Context, ids []string) error
+
+	// Soft deletion and lifecycle
+	SoftDelete(ctx context.Context, ids []string, deletedAt time.Time) error
+	GetSoftDeletedUsers(ctx context.Context) ([]*models.User, error)
+	PurgeUser(ctx context.Context, id string) error
+	ListTenants(ctx context.Context) ([]string, error)
+
+	// Merge operations
+	MergeUsers(ctx context.Context, survivor *models.User, loserID string) (*models.User, error)
 	
 	// Search operations
 	SearchUsers(ctx context.Context, query string, pagination *models.PaginationParams) (*models.PaginatedResult, error)
 	SearchUsersByField(ctx context.Context, field string, value string, pagination *models.PaginationParams) (*models.PaginatedResult, error)
 }
 
-// Logger defines the interface for logging
+// Logger defines the interface for logging. The Context variants attach
+// request-scoped values such as the request ID carried by ctx.
 type Logger interface {
 	Info(msg string, fields ...interface{})
 	Error(msg string, fields ...interface{})
 	Debug(msg string, fields ...interface{})
+	InfoContext(ctx context.Context, msg string, fields ...interface{})
+	ErrorContext(ctx context.Context, msg string, fields ...interface{})
+	DebugContext(ctx context.Context, msg string, fields ...interface{})
 }
\ No newline at end of file
@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// avatarJPEGQuality is used when re-encoding JPEG uploads
+const avatarJPEGQuality = 85
+
+// processedAvatar holds the encoded thumbnails of one upload
+type processedAvatar struct {
+	version     string
+	contentType string
+	thumbnails  map[int][]byte
+}
+
+// processAvatar validates an uploaded image and renders square thumbnails.
+// Decoding and re-encoding discards all embedded metadata (EXIF, ICC, text
+// chunks, GIF comments). JPEG uploads stay JPEG; PNG and GIF become PNG.
+func processAvatar(data []byte) (*processedAvatar, error) {
+	if len(data) == 0 {
+		return nil, models.NewFieldValidationError("avatar", "file is empty")
+	}
+	if len(data) > models.MaxAvatarUploadBytes {
+		return nil, models.NewFieldValidationError("avatar", fmt.Sprintf("file must be at most %d bytes", models.MaxAvatarUploadBytes))
+	}
+
+	// Trust the bytes, not the client-supplied filename or content type
+	sniffed := http.DetectContentType(data)
+	var decode func([]byte) (image.Image, error)
+	var decodeConfig func([]byte) (image.Config, error)
+	outputType := "image/png"
+	switch sniffed {
+	case "image/png":
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(b)) }
+	case "image/jpeg":
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(b)) }
+		outputType = "image/jpeg"
+	case "image/gif":
+		// Only the first frame of an animated GIF is used
+		decode = func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) }
+		decodeConfig = func(b []byte) (image.Config, error) { return gif.DecodeConfig(bytes.NewReader(b)) }
+	default:
+		return nil, models.ErrUnsupportedImage
+	}
+
+	// Check dimensions before decoding to avoid decompression bombs
+	cfg, err := decodeConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > models.MaxAvatarDimension || cfg.Height > models.MaxAvatarDimension {
+		return nil, models.NewFieldValidationError("avatar", fmt.Sprintf("image must be at most %dx%d pixels", models.MaxAvatarDimension, models.MaxAvatarDimension))
+	}
+
+	img, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrUnsupportedImage, err)
+	}
+
+	square := cropSquare(img)
+	thumbnails := make(map[int][]byte, len(models.AvatarSizes))
+	for _, size := range models.AvatarSizes {
+		var buf bytes.Buffer
+		thumb := resizeSquare(square, size)
+		if outputType == "image/jpeg" {
+			err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: avatarJPEGQuality})
+		} else {
+			err = png.Encode(&buf, thumb)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+		}
+		thumbnails[size] = buf.Bytes()
+	}
+
+	sum := sha256.Sum256(data)
+	return &processedAvatar{
+		version:     hex.EncodeToString(sum[:8]),
+		contentType: outputType,
+		thumbnails:  thumbnails,
+	}, nil
+}
+
+// cropSquare returns the centered square region of img as RGBA
+func cropSquare(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	origin := image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2)
+
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), img, origin, draw.Src)
+	return square
+}
+
+// resizeSquare scales a square image to size x size. Downscaling averages
+// every source pixel covered by a target pixel; upscaling falls back to
+// nearest neighbour.
+func resizeSquare(src *image.RGBA, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	srcSize := src.Bounds().Dx()
+
+	for dy := 0; dy < size; dy++ {
+		y0, y1 := span(dy, size, srcSize)
+		for dx := 0; dx < size; dx++ {
+			x0, x1 := span(dx, size, srcSize)
+
+			var r, g, b, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+
+			o := dst.PixOffset(dx, dy)
+			dst.Pix[o+0] = uint8(r / n)
+			dst.Pix[o+1] = uint8(g / n)
+			dst.Pix[o+2] = uint8(b / n)
+			dst.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// span maps target index i of n onto the source range [start, end) of m pixels
+func span(i, n, m int) (int, int) {
+	start := i * m / n
+	end := (i + 1) * m / n
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
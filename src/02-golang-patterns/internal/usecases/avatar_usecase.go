@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/interfaces/repositories"
+	"strconv"
+	"time"
+)
+
+// AvatarUseCase manages user profile images
+type AvatarUseCase struct {
+	userRepo  repositories.UserRepository
+	blobStore repositories.BlobStore
+	logger    repositories.Logger
+}
+
+// NewAvatarUseCase creates a new avatar use case
+func NewAvatarUseCase(userRepo repositories.UserRepository, blobStore repositories.BlobStore, logger repositories.Logger) *AvatarUseCase {
+	return &AvatarUseCase{
+		userRepo:  userRepo,
+		blobStore: blobStore,
+		logger:    logger,
+	}
+}
+
+// UploadAvatar processes an uploaded image, stores its thumbnails and
+// attaches the new avatar to the user. The previous avatar is removed.
+func (uc *AvatarUseCase) UploadAvatar(ctx context.Context, id string, data []byte) (*models.User, error) {
+	uc.logger.InfoContext(ctx, "Uploading avatar", "id", id, "bytes", len(data))
+
+	user, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsErased() {
+		return nil, models.ErrUserErased
+	}
+
+	processed, err := processAvatar(data)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Rejected avatar upload", "id", id, "error", err)
+		return nil, err
+	}
+
+	tenantID := models.TenantFromContext(ctx)
+	avatar := &models.Avatar{
+		Version:     processed.version,
+		ContentType: processed.contentType,
+		Sizes:       append([]int(nil), models.AvatarSizes...),
+		UpdatedAt:   time.Now(),
+	}
+
+	for _, size := range avatar.Sizes {
+		if err := uc.blobStore.Put(ctx, avatar.BlobKey(tenantID, id, size), processed.thumbnails[size], avatar.ContentType); err != nil {
+			uc.logger.ErrorContext(ctx, "Failed to store avatar", "id", id, "size", size, "error", err)
+			deleteBlobs(ctx, uc.blobStore, uc.logger, tenantID, id, avatar)
+			return nil, fmt.Errorf("failed to store avatar: %w", err)
+		}
+	}
+
+	previous := user.Avatar
+	user.Avatar = avatar
+	updatedUser, err := uc.userRepo.Update(ctx, user)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to attach avatar", "id", id, "error", err)
+		deleteBlobs(ctx, uc.blobStore, uc.logger, tenantID, id, avatar)
+		return nil, fmt.Errorf("failed to attach avatar: %w", err)
+	}
+
+	// Re-uploading the same image yields the same version and keys
+	if previous != nil && previous.Version != avatar.Version {
+		deleteBlobs(ctx, uc.blobStore, uc.logger, tenantID, id, previous)
+	}
+
+	uc.logger.InfoContext(ctx, "Avatar uploaded successfully", "id", id, "version", avatar.Version)
+	return updatedUser, nil
+}
+
+// GetAvatar returns one thumbnail of a user's avatar
+func (uc *AvatarUseCase) GetAvatar(ctx context.Context, id string, size int) (*models.AvatarImage, error) {
+	user, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Avatar == nil {
+		return nil, models.ErrAvatarNotFound
+	}
+	if !user.Avatar.HasSize(size) {
+		return nil, models.NewFieldValidationError("size", fmt.Sprintf("must be one of %v", user.Avatar.Sizes))
+	}
+
+	data, contentType, err := uc.blobStore.Get(ctx, user.Avatar.BlobKey(models.TenantFromContext(ctx), id, size))
+	if errors.Is(err, repositories.ErrBlobNotFound) {
+		return nil, models.ErrAvatarNotFound
+	}
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to read avatar", "id", id, "size", size, "error", err)
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+
+	return &models.AvatarImage{
+		Data:        data,
+		ContentType: contentType,
+		Version:     user.Avatar.Version,
+		ETag:        `"` + user.Avatar.Version + "-" + strconv.Itoa(size) + `"`,
+		UpdatedAt:   user.Avatar.UpdatedAt,
+	}, nil
+}
+
+// deleteBlobs removes all thumbnails of an avatar, logging failures. It is
+// shared by uploads and by the erasure and purging of users.
+func deleteBlobs(ctx context.Context, blobStore repositories.BlobStore, logger repositories.Logger, tenantID, id string, avatar *models.Avatar) {
+	if avatar == nil {
+		return
+	}
+	for _, size := range avatar.Sizes {
+		if err := blobStore.Delete(ctx, avatar.BlobKey(tenantID, id, size)); err != nil {
+			logger.ErrorContext(ctx, "Failed to delete avatar blob", "id", id, "size", size, "error", err)
+		}
+	}
+}
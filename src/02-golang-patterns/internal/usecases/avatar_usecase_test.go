@@ -0,0 +1,247 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/infrastructure/repositories"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// encodeTestImage renders a w x h image whose left half is red and right half blue
+func encodeTestImage(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// withPNGTextChunk inserts a tEXt metadata chunk right after the IHDR chunk
+func withPNGTextChunk(data []byte, text string) []byte {
+	const ihdrEnd = 8 + 4 + 4 + 13 + 4
+	body := append([]byte("tEXt"), text...)
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(text)))
+	chunk = append(chunk, body...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(body))
+
+	out := append([]byte{}, data[:ihdrEnd]...)
+	out = append(out, chunk...)
+	return append(out, data[ihdrEnd:]...)
+}
+
+func newTestAvatarUseCase(t *testing.T) (*AvatarUseCase, *UserUseCase) {
+	t.Helper()
+	store := newTestBlobStore(t)
+	userRepo := repositories.NewMemoryUserRepository()
+	userUC := NewUserUseCase(userRepo, repositories.NewMemoryAuditRepository(), repositories.NewMemoryUnitOfWork(), store, nopLogger{})
+	return NewAvatarUseCase(userRepo, store, nopLogger{}), userUC
+}
+
+func TestProcessAvatar(t *testing.T) {
+	t.Run("formats", func(t *testing.T) {
+		tests := []struct {
+			format   string
+			wantType string
+		}{
+			{format: "png", wantType: "image/png"},
+			{format: "jpeg", wantType: "image/jpeg"},
+			{format: "gif", wantType: "image/png"},
+		}
+		for _, tt := range tests {
+			processed, err := processAvatar(encodeTestImage(t, tt.format, 300, 200))
+			if err != nil {
+				t.Fatalf("%s: %v", tt.format, err)
+			}
+			if processed.contentType != tt.wantType {
+				t.Errorf("%s: content type = %s, want %s", tt.format, processed.contentType, tt.wantType)
+			}
+			for _, size := range models.AvatarSizes {
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(processed.thumbnails[size]))
+				if err != nil {
+					t.Fatalf("%s/%d: decode thumbnail: %v", tt.format, size, err)
+				}
+				if cfg.Width != size || cfg.Height != size {
+					t.Errorf("%s/%d: thumbnail is %dx%d", tt.format, size, cfg.Width, cfg.Height)
+				}
+			}
+		}
+	})
+
+	t.Run("crops the center square", func(t *testing.T) {
+		// A 300x100 image cropped to its center 100x100 is half red, half blue
+		processed, err := processAvatar(encodeTestImage(t, "png", 300, 100))
+		if err != nil {
+			t.Fatalf("process: %v", err)
+		}
+		img, err := png.Decode(bytes.NewReader(processed.thumbnails[32]))
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if r, _, b, _ := img.At(2, 16).RGBA(); r>>8 != 255 || b != 0 {
+			t.Errorf("left edge should be red, got r=%d b=%d", r>>8, b>>8)
+		}
+		if r, _, b, _ := img.At(29, 16).RGBA(); r != 0 || b>>8 != 255 {
+			t.Errorf("right edge should be blue, got r=%d b=%d", r>>8, b>>8)
+		}
+	})
+
+	t.Run("strips metadata", func(t *testing.T) {
+		secret := "GPS 35.6812N 139.7671E"
+		data := withPNGTextChunk(encodeTestImage(t, "png", 64, 64), secret)
+		if !bytes.Contains(data, []byte(secret)) {
+			t.Fatal("test image should carry the metadata")
+		}
+
+		processed, err := processAvatar(data)
+		if err != nil {
+			t.Fatalf("process: %v", err)
+		}
+		for size, thumb := range processed.thumbnails {
+			if bytes.Contains(thumb, []byte(secret)) || bytes.Contains(thumb, []byte("tEXt")) {
+				t.Errorf("thumbnail %d still contains metadata", size)
+			}
+		}
+	})
+
+	t.Run("rejects non-images by content", func(t *testing.T) {
+		_, err := processAvatar([]byte("<svg xmlns='http://www.w3.org/2000/svg'><script>alert(1)</script></svg>"))
+		if !errors.Is(err, models.ErrUnsupportedImage) {
+			t.Errorf("err = %v, want ErrUnsupportedImage", err)
+		}
+	})
+
+	t.Run("rejects truncated images", func(t *testing.T) {
+		data := encodeTestImage(t, "png", 64, 64)
+		_, err := processAvatar(data[:len(data)/2])
+		if !errors.Is(err, models.ErrUnsupportedImage) {
+			t.Errorf("err = %v, want ErrUnsupportedImage", err)
+		}
+	})
+
+	t.Run("rejects oversized dimensions", func(t *testing.T) {
+		_, err := processAvatar(encodeTestImage(t, "gif", models.MaxAvatarDimension+1, 1))
+		var validationErr *models.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("err = %v, want validation error", err)
+		}
+	})
+}
+
+func TestAvatarUseCase_UploadAndGet(t *testing.T) {
+	avatarUC, userUC := newTestAvatarUseCase(t)
+	ctx := context.Background()
+
+	user, err := userUC.CreateUser(ctx, &models.UserCreateRequest{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := avatarUC.GetAvatar(ctx, user.ID, models.DefaultAvatarSize); !errors.Is(err, models.ErrAvatarNotFound) {
+		t.Fatalf("err = %v, want ErrAvatarNotFound", err)
+	}
+
+	first, err := avatarUC.UploadAvatar(ctx, user.ID, encodeTestImage(t, "png", 100, 100))
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if first.Avatar == nil || first.Avatar.Version == "" {
+		t.Fatalf("avatar not attached: %+v", first.Avatar)
+	}
+
+	img, err := avatarUC.GetAvatar(ctx, user.ID, 64)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if img.ContentType != "image/png" || img.ETag != `"`+first.Avatar.Version+`-64"` {
+		t.Errorf("unexpected image metadata: type=%s etag=%s", img.ContentType, img.ETag)
+	}
+
+	if _, err := avatarUC.GetAvatar(ctx, user.ID, 100); err == nil {
+		t.Error("ungenerated size should be rejected")
+	}
+
+	t.Run("replacing removes the previous version", func(t *testing.T) {
+		second, err := avatarUC.UploadAvatar(ctx, user.ID, encodeTestImage(t, "jpeg", 80, 120))
+		if err != nil {
+			t.Fatalf("upload: %v", err)
+		}
+		if second.Avatar.Version == first.Avatar.Version {
+			t.Fatal("new upload should change the version")
+		}
+
+		tenantID := models.TenantFromContext(ctx)
+		if _, _, err := avatarUC.blobStore.Get(ctx, first.Avatar.BlobKey(tenantID, user.ID, 64)); err == nil {
+			t.Error("previous thumbnails should be deleted")
+		}
+
+		img, err := avatarUC.GetAvatar(ctx, user.ID, 64)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if img.ContentType != "image/jpeg" {
+			t.Errorf("content type = %s, want image/jpeg", img.ContentType)
+		}
+	})
+
+	t.Run("avatars are per tenant", func(t *testing.T) {
+		other := models.WithTenant(ctx, "globex")
+		if _, err := userUC.CreateUser(other, &models.UserCreateRequest{Name: "Gina", Email: "gina@example.com"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if _, err := avatarUC.GetAvatar(other, user.ID, 64); !errors.Is(err, models.ErrAvatarNotFound) {
+			t.Errorf("err = %v, want ErrAvatarNotFound", err)
+		}
+	})
+
+	t.Run("erasure drops the avatar", func(t *testing.T) {
+		current, err := userUC.GetUser(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if _, err := userUC.EraseUser(ctx, user.ID); err != nil {
+			t.Fatalf("erase: %v", err)
+		}
+		tenantID := models.TenantFromContext(ctx)
+		for _, size := range current.Avatar.Sizes {
+			if _, _, err := avatarUC.blobStore.Get(ctx, current.Avatar.BlobKey(tenantID, user.ID, size)); err == nil {
+				t.Errorf("thumbnail %d should be deleted", size)
+			}
+		}
+		if _, err := avatarUC.GetAvatar(ctx, user.ID, 64); !errors.Is(err, models.ErrAvatarNotFound) {
+			t.Errorf("err = %v, want ErrAvatarNotFound", err)
+		}
+		if _, err := avatarUC.UploadAvatar(ctx, user.ID, encodeTestImage(t, "png", 10, 10)); !errors.Is(err, models.ErrUserErased) {
+			t.Errorf("err = %v, want ErrUserErased", err)
+		}
+	})
+}
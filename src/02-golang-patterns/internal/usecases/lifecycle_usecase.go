@@ -0,0 +1,169 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/interfaces/repositories"
+	"sort"
+	"time"
+)
+
+// LifecycleUseCase implements account lifecycle policies such as
+// deactivating dormant users and purging soft-deleted ones
+type LifecycleUseCase struct {
+	userRepo  repositories.UserRepository
+	auditRepo repositories.AuditRepository
+	blobStore repositories.BlobStore
+	logger    repositories.Logger
+}
+
+// NewLifecycleUseCase creates a new lifecycle use case
+func NewLifecycleUseCase(userRepo repositories.UserRepository, auditRepo repositories.AuditRepository, blobStore repositories.BlobStore, logger repositories.Logger) *LifecycleUseCase {
+	return &LifecycleUseCase{
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		blobStore: blobStore,
+		logger:    logger,
+	}
+}
+
+// DeactivateInactiveUsers deactivates active users whose last login (or
+// creation, if they never logged in) is older than inactiveFor, in tenantID
+// or, if it is empty, in every tenant. In dry-run mode the affected users
+// are reported but not changed.
+func (uc *LifecycleUseCase) DeactivateInactiveUsers(ctx context.Context, now time.Time, tenantID string, inactiveFor time.Duration, dryRun bool) (*models.JobReport, error) {
+	uc.logger.InfoContext(ctx, "Deactivating inactive users", "inactive_for", inactiveFor, "dry_run", dryRun)
+
+	cutoff := now.Add(-inactiveFor)
+	report := &models.JobReport{DryRun: dryRun, Affected: []models.AffectedUser{}}
+
+	err := uc.forEachTenant(ctx, tenantID, func(ctx context.Context, tenantID string) error {
+		users, err := uc.userRepo.GetActiveUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get active users: %w", err)
+		}
+		sortUsersByID(users)
+
+		for _, user := range users {
+			lastSeen := user.CreatedAt
+			reason := "never logged in since " + lastSeen.Format(time.RFC3339)
+			if user.LastLoginAt != nil {
+				lastSeen = *user.LastLoginAt
+				reason = "last login " + lastSeen.Format(time.RFC3339)
+			}
+			if !lastSeen.Before(cutoff) {
+				continue
+			}
+
+			report.Affected = append(report.Affected, models.AffectedUser{
+				TenantID: tenantID,
+				UserID:   user.ID,
+				Action:   "deactivate",
+				Reason:   reason,
+			})
+			if dryRun {
+				continue
+			}
+
+			user.IsActive = false
+			if _, err := uc.userRepo.Update(ctx, user); err != nil {
+				return fmt.Errorf("failed to deactivate user %s: %w", user.ID, err)
+			}
+			uc.recordAudit(ctx, user.ID, models.AuditActionUserUpdated, map[string]string{"fields": "is_active", "source": "lifecycle"})
+		}
+		return nil
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to deactivate inactive users", "error", err)
+		return report, err
+	}
+
+	uc.logger.InfoContext(ctx, "Inactive users processed", "affected", len(report.Affected), "dry_run", dryRun)
+	return report, nil
+}
+
+// PurgeDeletedUsers permanently removes users soft-deleted more than
+// retention ago, in tenantID or, if it is empty, in every tenant. In dry-run
+// mode the affected users are only reported.
+func (uc *LifecycleUseCase) PurgeDeletedUsers(ctx context.Context, now time.Time, tenantID string, retention time.Duration, dryRun bool) (*models.JobReport, error) {
+	uc.logger.InfoContext(ctx, "Purging deleted users", "retention", retention, "dry_run", dryRun)
+
+	cutoff := now.Add(-retention)
+	report := &models.JobReport{DryRun: dryRun, Affected: []models.AffectedUser{}}
+
+	err := uc.forEachTenant(ctx, tenantID, func(ctx context.Context, tenantID string) error {
+		users, err := uc.userRepo.GetSoftDeletedUsers(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get deleted users: %w", err)
+		}
+		sortUsersByID(users)
+
+		for _, user := range users {
+			if user.DeletedAt == nil || !user.DeletedAt.Before(cutoff) {
+				continue
+			}
+
+			report.Affected = append(report.Affected, models.AffectedUser{
+				TenantID: tenantID,
+				UserID:   user.ID,
+				Action:   "purge",
+				Reason:   "deleted at " + user.DeletedAt.Format(time.RFC3339),
+			})
+			if dryRun {
+				continue
+			}
+
+			if err := uc.userRepo.PurgeUser(ctx, user.ID); err != nil {
+				return fmt.Errorf("failed to purge user %s: %w", user.ID, err)
+			}
+			deleteBlobs(ctx, uc.blobStore, uc.logger, tenantID, user.ID, user.Avatar)
+			uc.recordAudit(ctx, user.ID, models.AuditActionUserPurged, map[string]string{"source": "lifecycle"})
+		}
+		return nil
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to purge deleted users", "error", err)
+		return report, err
+	}
+
+	uc.logger.InfoContext(ctx, "Deleted users processed", "affected", len(report.Affected), "dry_run", dryRun)
+	return report, nil
+}
+
+// forEachTenant runs fn with a context scoped to tenantID, or once per
+// tenant if tenantID is empty
+func (uc *LifecycleUseCase) forEachTenant(ctx context.Context, tenantID string, fn func(ctx context.Context, tenantID string) error) error {
+	tenantIDs := []string{tenantID}
+	if tenantID == "" {
+		var err error
+		if tenantIDs, err = uc.userRepo.ListTenants(ctx); err != nil {
+			return fmt.Errorf("failed to list tenants: %w", err)
+		}
+	}
+
+	for _, tenantID := range tenantIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(models.WithTenant(ctx, tenantID), tenantID); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+	}
+
+	return nil
+}
+
+// recordAudit appends an entry to the audit trail, logging failures
+func (uc *LifecycleUseCase) recordAudit(ctx context.Context, userID string, action models.AuditAction, details map[string]string) {
+	if _, err := uc.auditRepo.Record(ctx, models.NewAuditEntry(userID, action, details)); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to record audit entry", "id", userID, "action", action, "error", err)
+	}
+}
+
+// sortUsersByID orders users deterministically for reports
+func sortUsersByID(users []*models.User) {
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+}
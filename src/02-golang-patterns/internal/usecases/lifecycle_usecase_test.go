@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"context"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/infrastructure/repositories"
+	"testing"
+	"time"
+)
+
+func TestLifecycleUseCase_DeactivateInactiveUsers(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	auditRepo := repositories.NewMemoryAuditRepository()
+	uc := NewLifecycleUseCase(userRepo, auditRepo, newTestBlobStore(t), nopLogger{})
+
+	now := time.Now()
+	acme := models.WithTenant(context.Background(), "acme")
+	globex := models.WithTenant(context.Background(), "globex")
+
+	dormant, _ := userRepo.Create(acme, &models.User{Name: "Dormant", Email: "dormant@example.com", IsActive: true})
+	oldLogin := now.AddDate(0, 0, -120)
+	dormant.LastLoginAt = &oldLogin
+	_, _ = userRepo.Update(acme, dormant)
+
+	recent, _ := userRepo.Create(acme, &models.User{Name: "Recent", Email: "recent@example.com", IsActive: true})
+	recentLogin := now.AddDate(0, 0, -5)
+	recent.LastLoginAt = &recentLogin
+	_, _ = userRepo.Update(acme, recent)
+
+	// Never logged in, but created recently: not dormant yet
+	_, _ = userRepo.Create(globex, &models.User{Name: "Newbie", Email: "newbie@example.com", IsActive: true})
+
+	report, err := uc.DeactivateInactiveUsers(context.Background(), now, "", 90*24*time.Hour, true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(report.Affected) != 1 || report.Affected[0].UserID != dormant.ID || report.Affected[0].TenantID != "acme" {
+		t.Fatalf("unexpected dry-run report: %+v", report.Affected)
+	}
+	if user, _ := userRepo.GetByID(acme, dormant.ID); !user.IsActive {
+		t.Fatal("dry run changed data")
+	}
+
+	report, err = uc.DeactivateInactiveUsers(context.Background(), now, "", 90*24*time.Hour, false)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(report.Affected) != 1 {
+		t.Fatalf("affected = %+v, want only the dormant user", report.Affected)
+	}
+	if user, _ := userRepo.GetByID(acme, dormant.ID); user.IsActive {
+		t.Error("dormant user still active")
+	}
+	if user, _ := userRepo.GetByID(acme, recent.ID); !user.IsActive {
+		t.Error("recently active user was deactivated")
+	}
+
+	// 91 days later the newbie counts as dormant since creation
+	report, _ = uc.DeactivateInactiveUsers(context.Background(), now.AddDate(0, 0, 91), "", 90*24*time.Hour, true)
+	if len(report.Affected) != 2 || report.Affected[1].TenantID != "globex" {
+		t.Errorf("affected = %+v, want recent and newbie", report.Affected)
+	}
+
+	// A run for one tenant leaves the others alone
+	report, _ = uc.DeactivateInactiveUsers(context.Background(), now.AddDate(0, 0, 91), "globex", 90*24*time.Hour, false)
+	if len(report.Affected) != 1 || report.Affected[0].TenantID != "globex" {
+		t.Errorf("affected = %+v, want only newbie", report.Affected)
+	}
+	if user, _ := userRepo.GetByID(acme, recent.ID); !user.IsActive {
+		t.Error("a run for globex deactivated a user of acme")
+	}
+}
+
+func TestLifecycleUseCase_PurgeDeletedUsers(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	auditRepo := repositories.NewMemoryAuditRepository()
+	blobStore := newTestBlobStore(t)
+	uc := NewLifecycleUseCase(userRepo, auditRepo, blobStore, nopLogger{})
+	ctx := context.Background()
+
+	now := time.Now()
+	avatar := &models.Avatar{Version: "v1", ContentType: "image/png", Sizes: []int{64}}
+	old, _ := userRepo.Create(ctx, &models.User{Name: "Old", Email: "old@example.com", Avatar: avatar})
+	avatarKey := avatar.BlobKey(models.TenantFromContext(ctx), old.ID, 64)
+	if err := blobStore.Put(ctx, avatarKey, []byte("png"), avatar.ContentType); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	fresh, _ := userRepo.Create(ctx, &models.User{Name: "Fresh", Email: "fresh@example.com"})
+	_ = userRepo.SoftDelete(ctx, []string{old.ID}, now.AddDate(0, 0, -31))
+	_ = userRepo.SoftDelete(ctx, []string{fresh.ID}, now.AddDate(0, 0, -2))
+
+	if _, err := userRepo.GetByID(ctx, old.ID); err == nil {
+		t.Fatal("soft-deleted user is still visible")
+	}
+
+	report, err := uc.PurgeDeletedUsers(ctx, now, "", 30*24*time.Hour, false)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if len(report.Affected) != 1 || report.Affected[0].UserID != old.ID {
+		t.Fatalf("unexpected report: %+v", report.Affected)
+	}
+
+	remaining, _ := userRepo.GetSoftDeletedUsers(ctx)
+	if len(remaining) != 1 || remaining[0].ID != fresh.ID {
+		t.Errorf("remaining soft-deleted users = %+v", remaining)
+	}
+
+	trail, _ := auditRepo.ListByUser(ctx, old.ID)
+	if len(trail) != 1 || trail[0].Action != models.AuditActionUserPurged {
+		t.Errorf("purge not audited: %+v", trail)
+	}
+
+	if _, _, err := blobStore.Get(ctx, avatarKey); err == nil {
+		t.Error("avatar of the purged user should be deleted")
+	}
+}
@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"sort"
+)
+
+// === Duplicate Detection and Merge ===
+
+// FindDuplicateUsers scores every pair of users and returns the pairs whose
+// score reaches threshold, most likely duplicates first
+func (uc *UserUseCase) FindDuplicateUsers(ctx context.Context, threshold float64) ([]*models.DuplicateCandidate, error) {
+	uc.logger.InfoContext(ctx, "Finding duplicate users", "threshold", threshold)
+
+	if threshold <= 0 || threshold > 1 {
+		return nil, models.NewValidationError("threshold must be greater than 0 and at most 1")
+	}
+
+	users, err := uc.userRepo.GetAll(ctx)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to get users for duplicate detection", "error", err)
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	// Stable pair order regardless of map iteration in the repository
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	candidates := []*models.DuplicateCandidate{}
+	for i := 0; i < len(users); i++ {
+		if users[i].IsErased() {
+			continue
+		}
+		for j := i + 1; j < len(users); j++ {
+			if users[j].IsErased() {
+				continue
+			}
+			score, reasons := models.ScoreDuplicate(users[i], users[j])
+			if score >= threshold {
+				candidates = append(candidates, &models.DuplicateCandidate{
+					UserA:   users[i],
+					UserB:   users[j],
+					Score:   score,
+					Reasons: reasons,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	uc.logger.InfoContext(ctx, "Duplicate detection completed", "users", len(users), "candidates", len(candidates))
+	return candidates, nil
+}
+
+// MergeUsers merges the loser into the survivor, reassigns the loser's audit
+// trail to the survivor and deletes the loser along with its avatar
+func (uc *UserUseCase) MergeUsers(ctx context.Context, req *models.MergeUsersRequest) (*models.User, error) {
+	uc.logger.InfoContext(ctx, "Merging users", "survivor_id", req.SurvivorID, "loser_id", req.LoserID)
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	survivor, err := uc.userRepo.GetByID(ctx, req.SurvivorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get survivor: %w", err)
+	}
+	loser, err := uc.userRepo.GetByID(ctx, req.LoserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loser: %w", err)
+	}
+
+	if survivor.IsErased() || loser.IsErased() {
+		return nil, models.NewValidationError("erased users cannot be merged")
+	}
+
+	merged := models.MergeUsers(survivor, loser, req)
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Merge, move the loser's audit trail and record the merge as one unit
+	var mergedUser *models.User
+	var reassigned int
+	err = uc.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		mergedUser, err = uc.userRepo.MergeUsers(ctx, merged, loser.ID)
+		if err != nil {
+			return fmt.Errorf("failed to merge users: %w", err)
+		}
+
+		reassigned, err = uc.auditRepo.ReassignUser(ctx, loser.ID, survivor.ID)
+		if err != nil {
+			return fmt.Errorf("failed to reassign audit trail: %w", err)
+		}
+
+		return uc.recordAudit(ctx, survivor.ID, models.AuditActionUserMerged, map[string]string{"merged_user_id": loser.ID})
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to merge users", "survivor_id", survivor.ID, "loser_id", loser.ID, "error", err)
+		return nil, err
+	}
+
+	// The survivor keeps its own avatar; the loser's goes with the loser
+	deleteBlobs(ctx, uc.blobStore, uc.logger, models.TenantFromContext(ctx), loser.ID, loser.Avatar)
+
+	uc.logger.InfoContext(ctx, "Users merged successfully", "survivor_id", survivor.ID, "loser_id", loser.ID, "audit_entries_reassigned", reassigned)
+	return mergedUser, nil
+}
@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"context"
+	"golang-patterns/internal/domain/models"
+	"testing"
+)
+
+func TestUserUseCase_FindAndMergeDuplicates(t *testing.T) {
+	uc := newTestUseCase(t)
+	ctx := context.Background()
+
+	survivor, _ := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "Tanaka Taro", Email: "taro.tanaka@gmail.com", Department: "Engineering"})
+	loser, _ := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "田中 太郎", Email: "tarotanaka@gmail.com", Age: 41, Department: "Engineering"})
+	other, _ := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "Suzuki Ichiro", Email: "suzuki@example.com", Department: "Sales"})
+	if _, err := uc.UpdateLastLogin(ctx, loser.ID); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	candidates, err := uc.FindDuplicateUsers(ctx, models.DefaultDuplicateThreshold)
+	if err != nil {
+		t.Fatalf("find duplicates: %v", err)
+	}
+	if len(candidates) != 1 {
+		t.Fatalf("got %d candidates, want 1", len(candidates))
+	}
+	if candidates[0].UserA.ID != survivor.ID || candidates[0].UserB.ID != loser.ID {
+		t.Errorf("unexpected pair: %s/%s", candidates[0].UserA.ID, candidates[0].UserB.ID)
+	}
+
+	merged, err := uc.MergeUsers(ctx, &models.MergeUsersRequest{SurvivorID: survivor.ID, LoserID: loser.ID})
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if merged.Age != 41 || merged.LastLoginAt == nil {
+		t.Errorf("loser values not carried over: %+v", merged)
+	}
+
+	if _, err := uc.GetUser(ctx, loser.ID); err == nil {
+		t.Error("loser still exists after merge")
+	}
+
+	export, err := uc.ExportUserData(ctx, survivor.ID)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if len(export.LoginHistory) != 1 {
+		t.Errorf("loser's login history was not reassigned: %v", export.LoginHistory)
+	}
+
+	t.Run("failed merge leaves both users untouched", func(t *testing.T) {
+		_, err := uc.MergeUsers(ctx, &models.MergeUsersRequest{SurvivorID: other.ID, LoserID: "user_999"})
+		if err == nil {
+			t.Fatal("merging an unknown loser should fail")
+		}
+		if _, err := uc.GetUser(ctx, other.ID); err != nil {
+			t.Errorf("survivor lost after failed merge: %v", err)
+		}
+	})
+
+	t.Run("the loser's avatar is deleted", func(t *testing.T) {
+		keeper, _ := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "Sato Hanako", Email: "hanako@example.com"})
+		duplicate, _ := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "佐藤 花子", Email: "sato.hanako@example.com"})
+		duplicate.Avatar = &models.Avatar{Version: "v1", ContentType: "image/png", Sizes: []int{64}}
+		if _, err := uc.userRepo.Update(ctx, duplicate); err != nil {
+			t.Fatalf("attach avatar: %v", err)
+		}
+		key := duplicate.Avatar.BlobKey(models.TenantFromContext(ctx), duplicate.ID, 64)
+		if err := uc.blobStore.Put(ctx, key, []byte("png"), "image/png"); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+
+		if _, err := uc.MergeUsers(ctx, &models.MergeUsersRequest{SurvivorID: keeper.ID, LoserID: duplicate.ID}); err != nil {
+			t.Fatalf("merge: %v", err)
+		}
+		if _, _, err := uc.blobStore.Get(ctx, key); err == nil {
+			t.Error("avatar of the merged-away user should be deleted")
+		}
+	})
+
+	t.Run("invalid threshold", func(t *testing.T) {
+		if _, err := uc.FindDuplicateUsers(ctx, 0); err == nil {
+			t.Error("threshold 0 should be rejected")
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"golang-patterns/internal/domain/models"
+	"time"
+)
+
+// === Personal Data Requests ===
+
+// ExportUserData builds a machine-readable bundle of everything stored about a user
+func (uc *UserUseCase) ExportUserData(ctx context.Context, id string) (*models.PersonalDataExport, error) {
+	uc.logger.InfoContext(ctx, "Exporting user data", "id", id)
+
+	user, err := uc.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	auditTrail, err := uc.auditRepo.ListByUser(ctx, id)
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to get audit trail", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to get audit trail: %w", err)
+	}
+
+	loginHistory := []time.Time{}
+	for _, entry := range auditTrail {
+		if entry.Action == models.AuditActionUserLogin {
+			loginHistory = append(loginHistory, entry.OccurredAt)
+		}
+	}
+
+	export := &models.PersonalDataExport{
+		Version:      models.PersonalDataExportVersion,
+		ExportedAt:   time.Now(),
+		User:         user,
+		LoginHistory: loginHistory,
+		AuditTrail:   auditTrail,
+	}
+
+	// Reading data does not change it, so a failed audit entry is only logged
+	_ = uc.recordAudit(ctx, id, models.AuditActionUserExported, nil)
+
+	uc.logger.InfoContext(ctx, "User data exported", "id", id, "audit_entries", len(auditTrail))
+	return export, nil
+}
+
+// EraseUser irreversibly pseudonymizes a user's name and email.
+// The user record is kept so aggregate statistics remain unchanged.
+func (uc *UserUseCase) EraseUser(ctx context.Context, id string) (*models.User, error) {
+	uc.logger.InfoContext(ctx, "Erasing user", "id", id)
+
+	user, err := uc.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsErased() {
+		return nil, models.ErrUserErased
+	}
+
+	avatar := user.Avatar
+	if err := user.Pseudonymize(); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to pseudonymize user", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to pseudonymize user: %w", err)
+	}
+
+	// The erasure itself must be on record, so it is undone if recording fails
+	var erasedUser *models.User
+	err = uc.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		erasedUser, err = uc.userRepo.Update(ctx, user)
+		if err != nil {
+			return fmt.Errorf("failed to erase user: %w", err)
+		}
+		return uc.recordAudit(ctx, id, models.AuditActionUserErased, nil)
+	})
+	if err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to erase user", "id", id, "error", err)
+		return nil, err
+	}
+
+	// The avatar is personal data too; it goes once the erasure is recorded
+	deleteBlobs(ctx, uc.blobStore, uc.logger, models.TenantFromContext(ctx), id, avatar)
+
+	uc.logger.InfoContext(ctx, "User erased successfully", "id", id)
+	return erasedUser, nil
+}
@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/infrastructure/blobstore"
+	"golang-patterns/internal/infrastructure/repositories"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, fields ...interface{})  {}
+func (nopLogger) Error(msg string, fields ...interface{}) {}
+func (nopLogger) Debug(msg string, fields ...interface{}) {}
+
+func (nopLogger) InfoContext(ctx context.Context, msg string, fields ...interface{})  {}
+func (nopLogger) ErrorContext(ctx context.Context, msg string, fields ...interface{}) {}
+func (nopLogger) DebugContext(ctx context.Context, msg string, fields ...interface{}) {}
+
+// newTestBlobStore stores blobs in a directory removed after the test
+func newTestBlobStore(t *testing.T) *blobstore.LocalBlobStore {
+	t.Helper()
+	store, err := blobstore.NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	return store
+}
+
+func newTestUseCase(t *testing.T) *UserUseCase {
+	t.Helper()
+	return NewUserUseCase(repositories.NewMemoryUserRepository(), repositories.NewMemoryAuditRepository(), repositories.NewMemoryUnitOfWork(), newTestBlobStore(t), nopLogger{})
+}
+
+func TestUserUseCase_ExportUserData(t *testing.T) {
+	uc := newTestUseCase(t)
+	ctx := context.Background()
+
+	user, err := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "Alice", Email: "alice@example.com", Department: "Engineering"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := uc.UpdateLastLogin(ctx, user.ID); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+	}
+
+	export, err := uc.ExportUserData(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	if export.User.Email != "alice@example.com" {
+		t.Errorf("export user = %+v", export.User)
+	}
+	if len(export.LoginHistory) != 2 {
+		t.Errorf("login history = %d entries, want 2", len(export.LoginHistory))
+	}
+	if len(export.AuditTrail) != 3 || export.AuditTrail[0].Action != models.AuditActionUserCreated {
+		t.Errorf("unexpected audit trail: %+v", export.AuditTrail)
+	}
+
+	if _, err := uc.ExportUserData(ctx, "user_999"); err == nil {
+		t.Error("exporting an unknown user should fail")
+	}
+}
+
+func TestUserUseCase_EraseUser(t *testing.T) {
+	uc := newTestUseCase(t)
+	ctx := context.Background()
+
+	user, err := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "Alice", Email: "alice@example.com", Age: 30, Department: "Engineering", Position: "Developer"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "Bob", Email: "bob@example.com", Age: 45, Department: "Sales"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := uc.UpdateLastLogin(ctx, user.ID); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	statsBefore, _ := uc.GetUserStats(ctx)
+
+	erased, err := uc.EraseUser(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("erase: %v", err)
+	}
+
+	t.Run("personal data is pseudonymized", func(t *testing.T) {
+		if strings.Contains(erased.Name, "Alice") || strings.Contains(erased.Email, "alice") {
+			t.Errorf("personal data survived erasure: %+v", erased)
+		}
+		if erased.ErasedAt == nil {
+			t.Error("ErasedAt not set")
+		}
+		if _, err := uc.GetUserByEmail(ctx, "alice@example.com"); err == nil {
+			t.Error("original email is still resolvable")
+		}
+		if err := (&models.User{Name: erased.Name, Email: erased.Email}).Validate(); err != nil {
+			t.Errorf("pseudonymized user is invalid: %v", err)
+		}
+	})
+
+	t.Run("aggregate statistics are preserved", func(t *testing.T) {
+		statsAfter, _ := uc.GetUserStats(ctx)
+		if !reflect.DeepEqual(statsBefore, statsAfter) {
+			t.Errorf("stats changed by erasure:\nbefore %+v\nafter  %+v", statsBefore, statsAfter)
+		}
+	})
+
+	t.Run("erasure is recorded", func(t *testing.T) {
+		export, err := uc.ExportUserData(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("export: %v", err)
+		}
+		var found bool
+		for _, entry := range export.AuditTrail {
+			if entry.Action == models.AuditActionUserErased {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("no erasure entry in audit trail")
+		}
+	})
+
+	t.Run("erasure is irreversible", func(t *testing.T) {
+		if _, err := uc.EraseUser(ctx, user.ID); !errors.Is(err, models.ErrUserErased) {
+			t.Errorf("second erase error = %v, want ErrUserErased", err)
+		}
+
+		name := "Alice"
+		if _, err := uc.UpdateUser(ctx, user.ID, &models.UserUpdateRequest{Name: &name}); err == nil {
+			t.Error("restoring the name of an erased user should be rejected")
+		}
+
+		dept := "HR"
+		if _, err := uc.UpdateUser(ctx, user.ID, &models.UserUpdateRequest{Department: &dept}); err != nil {
+			t.Errorf("non-personal fields should stay editable: %v", err)
+		}
+	})
+}
+
+// readHookRepository runs onRead once, right after the next user is read
+type readHookRepository struct {
+	*repositories.MemoryUserRepository
+	onRead func()
+}
+
+func (r *readHookRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	user, err := r.MemoryUserRepository.GetByID(ctx, id)
+	if onRead := r.onRead; onRead != nil {
+		r.onRead = nil
+		onRead()
+	}
+	return user, err
+}
+
+func TestUserUseCase_UpdateRacingEraseUser(t *testing.T) {
+	userRepo := repositories.NewMemoryUserRepository()
+	auditRepo := repositories.NewMemoryAuditRepository()
+	uow := repositories.NewMemoryUnitOfWork()
+	eraser := NewUserUseCase(userRepo, auditRepo, uow, newTestBlobStore(t), nopLogger{})
+	hooked := &readHookRepository{MemoryUserRepository: userRepo}
+	updater := NewUserUseCase(hooked, auditRepo, uow, newTestBlobStore(t), nopLogger{})
+	ctx := context.Background()
+
+	user, err := eraser.CreateUser(ctx, &models.UserCreateRequest{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// The user is erased after the update read it, but before it writes
+	hooked.onRead = func() {
+		if _, err := eraser.EraseUser(ctx, user.ID); err != nil {
+			t.Fatalf("erase: %v", err)
+		}
+	}
+	dept := "HR"
+	if _, err := updater.UpdateUser(ctx, user.ID, &models.UserUpdateRequest{Department: &dept}); !errors.Is(err, models.ErrConcurrentUpdate) {
+		t.Errorf("stale update error = %v, want ErrConcurrentUpdate", err)
+	}
+
+	stored, err := userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !stored.IsErased() || stored.Email == "alice@example.com" || stored.Name == "Alice" {
+		t.Errorf("stale update undid the erasure: %+v", stored)
+	}
+}
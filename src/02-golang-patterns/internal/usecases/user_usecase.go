@@ -10,15 +10,21 @@ import (
 
 // UserUseCase handles user business logic
 type UserUseCase struct {
-	userRepo repositories.UserRepository
-	logger   repositories.Logger
+	userRepo  repositories.UserRepository
+	auditRepo repositories.AuditRepository
+	uow       repositories.UnitOfWork
+	blobStore repositories.BlobStore
+	logger    repositories.Logger
 }
 
 // NewUserUseCase creates a new user use case
-func NewUserUseCase(userRepo repositories.UserRepository, logger repositories.Logger) *UserUseCase {
+func NewUserUseCase(userRepo repositories.UserRepository, auditRepo repositories.AuditRepository, uow repositories.UnitOfWork, blobStore repositories.BlobStore, logger repositories.Logger) *UserUseCase {
 	return &UserUseCase{
-		userRepo: userRepo,
-		logger:   logger,
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		uow:       uow,
+		blobStore: blobStore,
+		logger:    logger,
 	}
 }
 
@@ -26,11 +32,11 @@ func NewUserUseCase(userRepo repositories.UserRepository, logger repositories.Lo
 
 // CreateUser creates a new user with enhanced validation
 func (uc *UserUseCase) CreateUser(ctx context.Context, req *models.UserCreateRequest) (*models.User, error) {
-	uc.logger.Info("Creating user", "name", req.Name, "email", req.Email)
+	uc.logger.InfoContext(ctx, "Creating user", "name", req.Name, "email", req.Email)
 
 	// Validate request
 	if err := req.Validate(); err != nil {
-		uc.logger.Error("User creation validation failed", "error", err)
+		uc.logger.ErrorContext(ctx, "User creation validation failed", "error", err)
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
@@ -43,20 +49,28 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, req *models.UserCreateReq
 	// Convert request to user entity
 	user := req.ToUser()
 
-	// Create user
-	createdUser, err := uc.userRepo.Create(ctx, user)
+	// Create user together with its audit entry
+	var createdUser *models.User
+	err = uc.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		createdUser, err = uc.userRepo.Create(ctx, user)
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		return uc.recordAudit(ctx, createdUser.ID, models.AuditActionUserCreated, nil)
+	})
 	if err != nil {
-		uc.logger.Error("Failed to create user", "error", err)
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		uc.logger.ErrorContext(ctx, "Failed to create user", "error", err)
+		return nil, err
 	}
 
-	uc.logger.Info("User created successfully", "id", createdUser.ID, "email", createdUser.Email)
+	uc.logger.InfoContext(ctx, "User created successfully", "id", createdUser.ID, "email", createdUser.Email)
 	return createdUser, nil
 }
 
 // GetUser gets a user by ID
 func (uc *UserUseCase) GetUser(ctx context.Context, id string) (*models.User, error) {
-	uc.logger.Info("Getting user", "id", id)
+	uc.logger.InfoContext(ctx, "Getting user", "id", id)
 
 	if id == "" {
 		return nil, models.NewValidationError("user ID is required")
@@ -64,7 +78,7 @@ func (uc *UserUseCase) GetUser(ctx context.Context, id string) (*models.User, er
 
 	user, err := uc.userRepo.GetByID(ctx, id)
 	if err != nil {
-		uc.logger.Error("Failed to get user", "id", id, "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get user", "id", id, "error", err)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
@@ -73,21 +87,26 @@ func (uc *UserUseCase) GetUser(ctx context.Context, id string) (*models.User, er
 
 // UpdateUser updates an existing user
 func (uc *UserUseCase) UpdateUser(ctx context.Context, id string, req *models.UserUpdateRequest) (*models.User, error) {
-	uc.logger.Info("Updating user", "id", id)
+	uc.logger.InfoContext(ctx, "Updating user", "id", id)
 
 	// Validate request
 	if err := req.Validate(); err != nil {
-		uc.logger.Error("User update validation failed", "id", id, "error", err)
+		uc.logger.ErrorContext(ctx, "User update validation failed", "id", id, "error", err)
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
 	// Get existing user
 	existingUser, err := uc.userRepo.GetByID(ctx, id)
 	if err != nil {
-		uc.logger.Error("Failed to get user for update", "id", id, "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get user for update", "id", id, "error", err)
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
+	// Erasure is irreversible: personal data can never be restored
+	if existingUser.IsErased() && (req.Name != nil || req.Email != nil) {
+		return nil, models.NewValidationError("name and email of an erased user cannot be changed")
+	}
+
 	// Check for email conflict if email is being changed
 	if req.Email != nil && *req.Email != existingUser.Email {
 		_, err := uc.userRepo.GetByEmail(ctx, *req.Email)
@@ -99,20 +118,28 @@ func (uc *UserUseCase) UpdateUser(ctx context.Context, id string, req *models.Us
 	// Apply updates
 	existingUser.ApplyUpdate(req)
 
-	// Update user
-	updatedUser, err := uc.userRepo.Update(ctx, existingUser)
+	// Update user together with its audit entry
+	var updatedUser *models.User
+	err = uc.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		updatedUser, err = uc.userRepo.Update(ctx, existingUser)
+		if err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		return uc.recordAudit(ctx, id, models.AuditActionUserUpdated, map[string]string{"fields": req.ChangedFields()})
+	})
 	if err != nil {
-		uc.logger.Error("Failed to update user", "id", id, "error", err)
-		return nil, fmt.Errorf("failed to update user: %w", err)
+		uc.logger.ErrorContext(ctx, "Failed to update user", "id", id, "error", err)
+		return nil, err
 	}
 
-	uc.logger.Info("User updated successfully", "id", id)
+	uc.logger.InfoContext(ctx, "User updated successfully", "id", id)
 	return updatedUser, nil
 }
 
 // DeleteUser deletes a user
 func (uc *UserUseCase) DeleteUser(ctx context.Context, id string) error {
-	uc.logger.Info("Deleting user", "id", id)
+	uc.logger.InfoContext(ctx, "Deleting user", "id", id)
 
 	if id == "" {
 		return models.NewValidationError("user ID is required")
@@ -121,32 +148,37 @@ func (uc *UserUseCase) DeleteUser(ctx context.Context, id string) error {
 	// Check if user exists
 	_, err := uc.userRepo.GetByID(ctx, id)
 	if err != nil {
-		uc.logger.Error("Failed to get user for deletion", "id", id, "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get user for deletion", "id", id, "error", err)
 		return fmt.Errorf("failed to get user: %w", err)
 	}
 
-	// Delete user
-	err = uc.userRepo.Delete(ctx, id)
+	// Soft delete user; the purge lifecycle job removes it permanently later
+	err = uc.uow.Do(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.SoftDelete(ctx, []string{id}, time.Now()); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return uc.recordAudit(ctx, id, models.AuditActionUserDeleted, nil)
+	})
 	if err != nil {
-		uc.logger.Error("Failed to delete user", "id", id, "error", err)
-		return fmt.Errorf("failed to delete user: %w", err)
+		uc.logger.ErrorContext(ctx, "Failed to delete user", "id", id, "error", err)
+		return err
 	}
 
-	uc.logger.Info("User deleted successfully", "id", id)
+	uc.logger.InfoContext(ctx, "User deleted successfully", "id", id)
 	return nil
 }
 
 // GetAllUsers gets all users (legacy method)
 func (uc *UserUseCase) GetAllUsers(ctx context.Context) ([]*models.User, error) {
-	uc.logger.Info("Getting all users")
+	uc.logger.InfoContext(ctx, "Getting all users")
 
 	users, err := uc.userRepo.GetAll(ctx)
 	if err != nil {
-		uc.logger.Error("Failed to get all users", "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get all users", "error", err)
 		return nil, fmt.Errorf("failed to get users: %w", err)
 	}
 
-	uc.logger.Info("Retrieved all users", "count", len(users))
+	uc.logger.InfoContext(ctx, "Retrieved all users", "count", len(users))
 	return users, nil
 }
 
@@ -154,7 +186,7 @@ func (uc *UserUseCase) GetAllUsers(ctx context.Context) ([]*models.User, error)
 
 // GetUserByEmail gets a user by email
 func (uc *UserUseCase) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	uc.logger.Info("Getting user by email", "email", email)
+	uc.logger.InfoContext(ctx, "Getting user by email", "email", email)
 
 	if email == "" {
 		return nil, models.NewValidationError("email is required")
@@ -162,7 +194,7 @@ func (uc *UserUseCase) GetUserByEmail(ctx context.Context, email string) (*model
 
 	user, err := uc.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		uc.logger.Error("Failed to get user by email", "email", email, "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get user by email", "email", email, "error", err)
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
@@ -171,7 +203,7 @@ func (uc *UserUseCase) GetUserByEmail(ctx context.Context, email string) (*model
 
 // GetUsersByDepartment gets users by department
 func (uc *UserUseCase) GetUsersByDepartment(ctx context.Context, department string) ([]*models.User, error) {
-	uc.logger.Info("Getting users by department", "department", department)
+	uc.logger.InfoContext(ctx, "Getting users by department", "department", department)
 
 	if department == "" {
 		return nil, models.NewValidationError("department is required")
@@ -179,17 +211,17 @@ func (uc *UserUseCase) GetUsersByDepartment(ctx context.Context, department stri
 
 	users, err := uc.userRepo.GetByDepartment(ctx, department)
 	if err != nil {
-		uc.logger.Error("Failed to get users by department", "department", department, "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get users by department", "department", department, "error", err)
 		return nil, fmt.Errorf("failed to get users by department: %w", err)
 	}
 
-	uc.logger.Info("Retrieved users by department", "department", department, "count", len(users))
+	uc.logger.InfoContext(ctx, "Retrieved users by department", "department", department, "count", len(users))
 	return users, nil
 }
 
 // GetUsersByPosition gets users by position
 func (uc *UserUseCase) GetUsersByPosition(ctx context.Context, position string) ([]*models.User, error) {
-	uc.logger.Info("Getting users by position", "position", position)
+	uc.logger.InfoContext(ctx, "Getting users by position", "position", position)
 
 	if position == "" {
 		return nil, models.NewValidationError("position is required")
@@ -197,39 +229,39 @@ func (uc *UserUseCase) GetUsersByPosition(ctx context.Context, position string)
 
 	users, err := uc.userRepo.GetByPosition(ctx, position)
 	if err != nil {
-		uc.logger.Error("Failed to get users by position", "position", position, "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get users by position", "position", position, "error", err)
 		return nil, fmt.Errorf("failed to get users by position: %w", err)
 	}
 
-	uc.logger.Info("Retrieved users by position", "position", position, "count", len(users))
+	uc.logger.InfoContext(ctx, "Retrieved users by position", "position", position, "count", len(users))
 	return users, nil
 }
 
 // GetActiveUsers gets all active users
 func (uc *UserUseCase) GetActiveUsers(ctx context.Context) ([]*models.User, error) {
-	uc.logger.Info("Getting active users")
+	uc.logger.InfoContext(ctx, "Getting active users")
 
 	users, err := uc.userRepo.GetActiveUsers(ctx)
 	if err != nil {
-		uc.logger.Error("Failed to get active users", "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get active users", "error", err)
 		return nil, fmt.Errorf("failed to get active users: %w", err)
 	}
 
-	uc.logger.Info("Retrieved active users", "count", len(users))
+	uc.logger.InfoContext(ctx, "Retrieved active users", "count", len(users))
 	return users, nil
 }
 
 // GetInactiveUsers gets all inactive users
 func (uc *UserUseCase) GetInactiveUsers(ctx context.Context) ([]*models.User, error) {
-	uc.logger.Info("Getting inactive users")
+	uc.logger.InfoContext(ctx, "Getting inactive users")
 
 	users, err := uc.userRepo.GetInactiveUsers(ctx)
 	if err != nil {
-		uc.logger.Error("Failed to get inactive users", "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get inactive users", "error", err)
 		return nil, fmt.Errorf("failed to get inactive users: %w", err)
 	}
 
-	uc.logger.Info("Retrieved inactive users", "count", len(users))
+	uc.logger.InfoContext(ctx, "Retrieved inactive users", "count", len(users))
 	return users, nil
 }
 
@@ -237,7 +269,7 @@ func (uc *UserUseCase) GetInactiveUsers(ctx context.Context) ([]*models.User, er
 
 // GetUsersWithQuery gets users with complex query parameters
 func (uc *UserUseCase) GetUsersWithQuery(ctx context.Context, params *models.QueryParams) (*models.PaginatedResult, error) {
-	uc.logger.Info("Getting users with query", "page", params.Pagination.Page, "page_size", params.Pagination.PageSize)
+	uc.logger.InfoContext(ctx, "Getting users with query", "page", params.Pagination.Page, "page_size", params.Pagination.PageSize)
 
 	// Validate query parameters
 	if err := params.Pagination.Validate(); err != nil {
@@ -252,17 +284,17 @@ func (uc *UserUseCase) GetUsersWithQuery(ctx context.Context, params *models.Que
 
 	result, err := uc.userRepo.GetUsersWithQuery(ctx, params)
 	if err != nil {
-		uc.logger.Error("Failed to get users with query", "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get users with query", "error", err)
 		return nil, fmt.Errorf("failed to get users with query: %w", err)
 	}
 
-	uc.logger.Info("Retrieved users with query", "total", result.Total, "page", result.Page)
+	uc.logger.InfoContext(ctx, "Retrieved users with query", "total", result.Total, "page", result.Page)
 	return result, nil
 }
 
 // GetUsersWithPagination gets users with pagination and sorting
 func (uc *UserUseCase) GetUsersWithPagination(ctx context.Context, page, pageSize int, sortField, sortOrder string) (*models.PaginatedResult, error) {
-	uc.logger.Info("Getting users with pagination", "page", page, "page_size", pageSize, "sort_field", sortField, "sort_order", sortOrder)
+	uc.logger.InfoContext(ctx, "Getting users with pagination", "page", page, "page_size", pageSize, "sort_field", sortField, "sort_order", sortOrder)
 
 	// Create query parameters
 	pagination := models.NewPaginationParams(page, pageSize)
@@ -280,7 +312,7 @@ func (uc *UserUseCase) GetUsersWithPagination(ctx context.Context, page, pageSiz
 
 // SearchUsers searches users by query string
 func (uc *UserUseCase) SearchUsers(ctx context.Context, query string, page, pageSize int) (*models.PaginatedResult, error) {
-	uc.logger.Info("Searching users", "query", query, "page", page, "page_size", pageSize)
+	uc.logger.InfoContext(ctx, "Searching users", "query", query, "page", page, "page_size", pageSize)
 
 	if query == "" {
 		return nil, models.NewValidationError("search query is required")
@@ -290,11 +322,11 @@ func (uc *UserUseCase) SearchUsers(ctx context.Context, query string, page, page
 
 	result, err := uc.userRepo.SearchUsers(ctx, query, pagination)
 	if err != nil {
-		uc.logger.Error("Failed to search users", "query", query, "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to search users", "query", query, "error", err)
 		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
 
-	uc.logger.Info("User search completed", "query", query, "total", result.Total)
+	uc.logger.InfoContext(ctx, "User search completed", "query", query, "total", result.Total)
 	return result, nil
 }
 
@@ -302,7 +334,7 @@ func (uc *UserUseCase) SearchUsers(ctx context.Context, query string, page, page
 
 // GetUsersBatch gets users in batches for progressive loading
 func (uc *UserUseCase) GetUsersBatch(ctx context.Context, batchSize int, cursor, direction string) (*models.ProgressiveResult, error) {
-	uc.logger.Info("Getting users batch", "batch_size", batchSize, "cursor", cursor, "direction", direction)
+	uc.logger.InfoContext(ctx, "Getting users batch", "batch_size", batchSize, "cursor", cursor, "direction", direction)
 
 	params := models.NewProgressiveLoadParams(batchSize, cursor, direction)
 
@@ -313,12 +345,12 @@ func (uc *UserUseCase) GetUsersBatch(ctx context.Context, batchSize int, cursor,
 
 	result, err := uc.userRepo.GetUsersBatch(ctx, params)
 	if err != nil {
-		uc.logger.Error("Failed to get users batch", "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get users batch", "error", err)
 		return nil, fmt.Errorf("failed to get users batch: %w", err)
 	}
 
 	users := result.Data.([]*models.User)
-	uc.logger.Info("Retrieved users batch", "count", len(users), "has_more", result.HasMore)
+	uc.logger.InfoContext(ctx, "Retrieved users batch", "count", len(users), "has_more", result.HasMore)
 	return result, nil
 }
 
@@ -326,35 +358,35 @@ func (uc *UserUseCase) GetUsersBatch(ctx context.Context, batchSize int, cursor,
 
 // GetUserStats gets comprehensive user statistics
 func (uc *UserUseCase) GetUserStats(ctx context.Context) (*models.UserStats, error) {
-	uc.logger.Info("Getting user statistics")
+	uc.logger.InfoContext(ctx, "Getting user statistics")
 
 	stats, err := uc.userRepo.GetUserStats(ctx)
 	if err != nil {
-		uc.logger.Error("Failed to get user stats", "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get user stats", "error", err)
 		return nil, fmt.Errorf("failed to get user stats: %w", err)
 	}
 
-	uc.logger.Info("Retrieved user statistics", "total_users", stats.TotalUsers, "active_users", stats.ActiveUsers)
+	uc.logger.InfoContext(ctx, "Retrieved user statistics", "total_users", stats.TotalUsers, "active_users", stats.ActiveUsers)
 	return stats, nil
 }
 
 // GetDepartmentStats gets department-wise statistics
 func (uc *UserUseCase) GetDepartmentStats(ctx context.Context) (map[string]int, error) {
-	uc.logger.Info("Getting department statistics")
+	uc.logger.InfoContext(ctx, "Getting department statistics")
 
 	stats, err := uc.userRepo.GetDepartmentStats(ctx)
 	if err != nil {
-		uc.logger.Error("Failed to get department stats", "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get department stats", "error", err)
 		return nil, fmt.Errorf("failed to get department stats: %w", err)
 	}
 
-	uc.logger.Info("Retrieved department statistics", "departments", len(stats))
+	uc.logger.InfoContext(ctx, "Retrieved department statistics", "departments", len(stats))
 	return stats, nil
 }
 
 // GetRecentSignups gets users who signed up recently
 func (uc *UserUseCase) GetRecentSignups(ctx context.Context, days int) ([]*models.User, error) {
-	uc.logger.Info("Getting recent signups", "days", days)
+	uc.logger.InfoContext(ctx, "Getting recent signups", "days", days)
 
 	if days <= 0 {
 		return nil, models.NewValidationError("days must be greater than 0")
@@ -362,11 +394,11 @@ func (uc *UserUseCase) GetRecentSignups(ctx context.Context, days int) ([]*model
 
 	users, err := uc.userRepo.GetRecentSignups(ctx, days)
 	if err != nil {
-		uc.logger.Error("Failed to get recent signups", "days", days, "error", err)
+		uc.logger.ErrorContext(ctx, "Failed to get recent signups", "days", days, "error", err)
 		return nil, fmt.Errorf("failed to get recent signups: %w", err)
 	}
 
-	uc.logger.Info("Retrieved recent signups", "days", days, "count", len(users))
+	uc.logger.InfoContext(ctx, "Retrieved recent signups", "days", days, "count", len(users))
 	return users, nil
 }
 
@@ -374,7 +406,7 @@ func (uc *UserUseCase) GetRecentSignups(ctx context.Context, days int) ([]*model
 
 // CreateUsersInBulk creates multiple users at once
 func (uc *UserUseCase) CreateUsersInBulk(ctx context.Context, requests []*models.UserCreateRequest) ([]*models.User, error) {
-	uc.logger.Info("Creating users in bulk", "count", len(requests))
+	uc.logger.InfoContext(ctx, "Creating users in bulk", "count", len(requests))
 
 	if len(requests) == 0 {
 		return nil, models.NewValidationError("no users to create")
@@ -398,20 +430,33 @@ func (uc *UserUseCase) CreateUsersInBulk(ctx context.Context, requests []*models
 		users = append(users, user)
 	}
 
-	// Create users in bulk
-	createdUsers, err := uc.userRepo.BulkCreate(ctx, users)
+	// Create users in bulk together with their audit entries
+	var createdUsers []*models.User
+	err := uc.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		createdUsers, err = uc.userRepo.BulkCreate(ctx, users)
+		if err != nil {
+			return fmt.Errorf("failed to create users in bulk: %w", err)
+		}
+		for _, user := range createdUsers {
+			if err := uc.recordAudit(ctx, user.ID, models.AuditActionUserCreated, map[string]string{"source": "bulk"}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		uc.logger.Error("Failed to create users in bulk", "error", err)
-		return nil, fmt.Errorf("failed to create users in bulk: %w", err)
+		uc.logger.ErrorContext(ctx, "Failed to create users in bulk", "error", err)
+		return nil, err
 	}
 
-	uc.logger.Info("Users created in bulk successfully", "count", len(createdUsers))
+	uc.logger.InfoContext(ctx, "Users created in bulk successfully", "count", len(createdUsers))
 	return createdUsers, nil
 }
 
 // UpdateUsersInBulk updates multiple users at once
 func (uc *UserUseCase) UpdateUsersInBulk(ctx context.Context, updates map[string]*models.UserUpdateRequest) ([]*models.User, error) {
-	uc.logger.Info("Updating users in bulk", "count", len(updates))
+	uc.logger.InfoContext(ctx, "Updating users in bulk", "count", len(updates))
 
 	if len(updates) == 0 {
 		return nil, models.NewValidationError("no users to update")
@@ -433,24 +478,41 @@ func (uc *UserUseCase) UpdateUsersInBulk(ctx context.Context, updates map[string
 			return nil, fmt.Errorf("failed to get user %s: %w", id, err)
 		}
 
+		if existingUser.IsErased() && (req.Name != nil || req.Email != nil) {
+			return nil, models.NewValidationError(fmt.Sprintf("name and email of erased user %s cannot be changed", id))
+		}
+
 		existingUser.ApplyUpdate(req)
 		usersToUpdate = append(usersToUpdate, existingUser)
 	}
 
-	// Update users in bulk
-	updatedUsers, err := uc.userRepo.BulkUpdate(ctx, usersToUpdate)
+	// Update users in bulk together with their audit entries
+	var updatedUsers []*models.User
+	err := uc.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		updatedUsers, err = uc.userRepo.BulkUpdate(ctx, usersToUpdate)
+		if err != nil {
+			return fmt.Errorf("failed to update users in bulk: %w", err)
+		}
+		for id, req := range updates {
+			if err := uc.recordAudit(ctx, id, models.AuditActionUserUpdated, map[string]string{"fields": req.ChangedFields(), "source": "bulk"}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		uc.logger.Error("Failed to update users in bulk", "error", err)
-		return nil, fmt.Errorf("failed to update users in bulk: %w", err)
+		uc.logger.ErrorContext(ctx, "Failed to update users in bulk", "error", err)
+		return nil, err
 	}
 
-	uc.logger.Info("Users updated in bulk successfully", "count", len(updatedUsers))
+	uc.logger.InfoContext(ctx, "Users updated in bulk successfully", "count", len(updatedUsers))
 	return updatedUsers, nil
 }
 
 // DeleteUsersInBulk deletes multiple users at once
 func (uc *UserUseCase) DeleteUsersInBulk(ctx context.Context, ids []string) error {
-	uc.logger.Info("Deleting users in bulk", "count", len(ids))
+	uc.logger.InfoContext(ctx, "Deleting users in bulk", "count", len(ids))
 
 	if len(ids) == 0 {
 		return models.NewValidationError("no users to delete")
@@ -460,14 +522,24 @@ func (uc *UserUseCase) DeleteUsersInBulk(ctx context.Context, ids []string) erro
 		return models.NewValidationError("bulk delete limited to 100 users")
 	}
 
-	// Delete users in bulk
-	err := uc.userRepo.BulkDelete(ctx, ids)
+	// Soft delete users in bulk together with their audit entries
+	err := uc.uow.Do(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.SoftDelete(ctx, ids, time.Now()); err != nil {
+			return fmt.Errorf("failed to delete users in bulk: %w", err)
+		}
+		for _, id := range ids {
+			if err := uc.recordAudit(ctx, id, models.AuditActionUserDeleted, map[string]string{"source": "bulk"}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		uc.logger.Error("Failed to delete users in bulk", "error", err)
-		return fmt.Errorf("failed to delete users in bulk: %w", err)
+		uc.logger.ErrorContext(ctx, "Failed to delete users in bulk", "error", err)
+		return err
 	}
 
-	uc.logger.Info("Users deleted in bulk successfully", "count", len(ids))
+	uc.logger.InfoContext(ctx, "Users deleted in bulk successfully", "count", len(ids))
 	return nil
 }
 
@@ -475,7 +547,7 @@ func (uc *UserUseCase) DeleteUsersInBulk(ctx context.Context, ids []string) erro
 
 // ActivateUser activates a user account
 func (uc *UserUseCase) ActivateUser(ctx context.Context, id string) (*models.User, error) {
-	uc.logger.Info("Activating user", "id", id)
+	uc.logger.InfoContext(ctx, "Activating user", "id", id)
 
 	isActive := true
 	req := &models.UserUpdateRequest{
@@ -487,13 +559,13 @@ func (uc *UserUseCase) ActivateUser(ctx context.Context, id string) (*models.Use
 		return nil, fmt.Errorf("failed to activate user: %w", err)
 	}
 
-	uc.logger.Info("User activated successfully", "id", id)
+	uc.logger.InfoContext(ctx, "User activated successfully", "id", id)
 	return user, nil
 }
 
 // DeactivateUser deactivates a user account
 func (uc *UserUseCase) DeactivateUser(ctx context.Context, id string) (*models.User, error) {
-	uc.logger.Info("Deactivating user", "id", id)
+	uc.logger.InfoContext(ctx, "Deactivating user", "id", id)
 
 	isActive := false
 	req := &models.UserUpdateRequest{
@@ -505,13 +577,13 @@ func (uc *UserUseCase) DeactivateUser(ctx context.Context, id string) (*models.U
 		return nil, fmt.Errorf("failed to deactivate user: %w", err)
 	}
 
-	uc.logger.Info("User deactivated successfully", "id", id)
+	uc.logger.InfoContext(ctx, "User deactivated successfully", "id", id)
 	return user, nil
 }
 
 // UpdateLastLogin updates the last login time for a user
 func (uc *UserUseCase) UpdateLastLogin(ctx context.Context, id string) (*models.User, error) {
-	uc.logger.Info("Updating last login", "id", id)
+	uc.logger.InfoContext(ctx, "Updating last login", "id", id)
 
 	user, err := uc.userRepo.GetByID(ctx, id)
 	if err != nil {
@@ -521,19 +593,28 @@ func (uc *UserUseCase) UpdateLastLogin(ctx context.Context, id string) (*models.
 	now := time.Now()
 	user.LastLoginAt = &now
 
-	updatedUser, err := uc.userRepo.Update(ctx, user)
+	// The audit entry is the login history used by data exports
+	var updatedUser *models.User
+	err = uc.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		updatedUser, err = uc.userRepo.Update(ctx, user)
+		if err != nil {
+			return fmt.Errorf("failed to update last login: %w", err)
+		}
+		return uc.recordAudit(ctx, id, models.AuditActionUserLogin, nil)
+	})
 	if err != nil {
-		uc.logger.Error("Failed to update last login", "id", id, "error", err)
-		return nil, fmt.Errorf("failed to update last login: %w", err)
+		uc.logger.ErrorContext(ctx, "Failed to update last login", "id", id, "error", err)
+		return nil, err
 	}
 
-	uc.logger.Info("Last login updated successfully", "id", id)
+	uc.logger.InfoContext(ctx, "Last login updated successfully", "id", id)
 	return updatedUser, nil
 }
 
 // GetUserSummary gets a summary view of user data
 func (uc *UserUseCase) GetUserSummary(ctx context.Context, id string) (map[string]interface{}, error) {
-	uc.logger.Info("Getting user summary", "id", id)
+	uc.logger.InfoContext(ctx, "Getting user summary", "id", id)
 
 	user, err := uc.GetUser(ctx, id)
 	if err != nil {
@@ -557,4 +638,14 @@ func (uc *UserUseCase) GetUserSummary(ctx context.Context, id string) (map[strin
 	}
 
 	return summary, nil
-}
\ No newline at end of file
+}
+
+// recordAudit appends an entry to the audit trail. Called inside a unit of
+// work, a failure rolls back the operation being audited.
+func (uc *UserUseCase) recordAudit(ctx context.Context, userID string, action models.AuditAction, details map[string]string) error {
+	if _, err := uc.auditRepo.Record(ctx, models.NewAuditEntry(userID, action, details)); err != nil {
+		uc.logger.ErrorContext(ctx, "Failed to record audit entry", "id", userID, "action", action, "error", err)
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
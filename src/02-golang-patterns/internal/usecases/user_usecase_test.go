@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/infrastructure/repositories"
+	"testing"
+)
+
+var errAuditUnavailable = errors.New("audit store unavailable")
+
+// failingAuditRepository records nothing once failing is set
+type failingAuditRepository struct {
+	*repositories.MemoryAuditRepository
+	failing bool
+}
+
+func (r *failingAuditRepository) Record(ctx context.Context, entry *models.AuditEntry) (*models.AuditEntry, error) {
+	if r.failing {
+		return nil, errAuditUnavailable
+	}
+	return r.MemoryAuditRepository.Record(ctx, entry)
+}
+
+func (r *failingAuditRepository) ReassignUser(ctx context.Context, fromUserID, toUserID string) (int, error) {
+	if r.failing {
+		return 0, errAuditUnavailable
+	}
+	return r.MemoryAuditRepository.ReassignUser(ctx, fromUserID, toUserID)
+}
+
+func TestUserUseCase_AuditFailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repositories.NewMemoryUserRepository()
+	auditRepo := &failingAuditRepository{MemoryAuditRepository: repositories.NewMemoryAuditRepository()}
+	uc := NewUserUseCase(userRepo, auditRepo, repositories.NewMemoryUnitOfWork(), newTestBlobStore(t), nopLogger{})
+
+	alice, err := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	bob, err := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "Bob", Email: "bob@example.com"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	auditRepo.failing = true
+
+	t.Run("create", func(t *testing.T) {
+		_, err := uc.CreateUser(ctx, &models.UserCreateRequest{Name: "Carol", Email: "carol@example.com"})
+		if !errors.Is(err, errAuditUnavailable) {
+			t.Fatalf("error = %v, want %v", err, errAuditUnavailable)
+		}
+		if _, err := userRepo.GetByEmail(ctx, "carol@example.com"); err == nil {
+			t.Error("user was created without an audit entry")
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		name := "Alicia"
+		if _, err := uc.UpdateUser(ctx, alice.ID, &models.UserUpdateRequest{Name: &name}); err == nil {
+			t.Fatal("update should fail")
+		}
+		if got, _ := uc.GetUser(ctx, alice.ID); got.Name != "Alice" {
+			t.Errorf("update survived rollback: %+v", got)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := uc.DeleteUser(ctx, alice.ID); err == nil {
+			t.Fatal("delete should fail")
+		}
+		if _, err := uc.GetUser(ctx, alice.ID); err != nil {
+			t.Errorf("delete survived rollback: %v", err)
+		}
+	})
+
+	t.Run("erase", func(t *testing.T) {
+		if _, err := uc.EraseUser(ctx, alice.ID); err == nil {
+			t.Fatal("erase should fail")
+		}
+		if got, _ := uc.GetUser(ctx, alice.ID); got.ErasedAt != nil || got.Email != "alice@example.com" {
+			t.Errorf("erasure survived rollback: %+v", got)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		if _, err := uc.MergeUsers(ctx, &models.MergeUsersRequest{SurvivorID: alice.ID, LoserID: bob.ID}); err == nil {
+			t.Fatal("merge should fail")
+		}
+		if _, err := uc.GetUser(ctx, bob.ID); err != nil {
+			t.Errorf("loser removed despite failed audit reassignment: %v", err)
+		}
+	})
+}
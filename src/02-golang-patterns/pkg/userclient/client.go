@@ -0,0 +1,249 @@
+// Package userclient is a typed Go client for the users API served by
+// cmd/server. Every route is exposed as a method; error responses are
+// returned as *APIError and can be matched with errors.Is against the
+// sentinel errors in this package.
+package userclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TenantHeader carries the tenant ID, matching the server's middleware
+const TenantHeader = "X-Tenant-ID"
+
+// Client calls the users API
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	tenantID   string
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the underlying HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTenant sends every request on behalf of the given tenant
+func WithTenant(tenantID string) Option {
+	return func(c *Client) {
+		c.tenantID = tenantID
+	}
+}
+
+// WithRetry configures how often retryable failures are retried and the
+// bounds of the exponential backoff between attempts
+func WithRetry(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// New creates a client for the server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: 3,
+		minBackoff: 200 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// envelope mirrors handlers.APIResponse with a deferred data payload
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   *APIError       `json:"error,omitempty"`
+}
+
+// request describes one API call
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+}
+
+// jsonRequest builds a request with a JSON-encoded body
+func jsonRequest(method, path string, body interface{}) (*request, error) {
+	req := &request{method: method, path: path}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		req.body = data
+		req.contentType = "application/json"
+	}
+	return req, nil
+}
+
+// call performs req and decodes the envelope's data into out (if non-nil)
+func (c *Client) call(ctx context.Context, req *request, out interface{}) error {
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return decodeError(resp)
+	}
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if out == nil || len(env.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return nil
+}
+
+// send performs req, retrying transport errors and retryable statuses
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, req)
+
+		if attempt >= c.maxRetries || !c.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := c.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// do performs a single HTTP attempt
+func (c *Client) do(ctx context.Context, req *request) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if c.tenantID != "" {
+		httpReq.Header.Set(TenantHeader, c.tenantID)
+	}
+
+	return c.httpClient.Do(httpReq)
+}
+
+// shouldRetry decides whether an attempt can safely be repeated. Requests
+// rejected before processing (429, 503) are always retried; other server
+// errors and transport failures only for idempotent methods.
+func (c *Client) shouldRetry(req *request, resp *http.Response, err error) bool {
+	if err != nil {
+		return isIdempotent(req.method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return isIdempotent(req.method)
+	}
+	return false
+}
+
+// backoff returns the delay before the next attempt, honouring Retry-After
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.maxBackoff)
+		}
+	}
+
+	delay := c.minBackoff << attempt
+	if delay <= 0 || delay > c.maxBackoff {
+		delay = c.maxBackoff
+	}
+	// Equal jitter: wait at least half the backoff plus a random share of the
+	// other half, so many clients do not retry in lockstep
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isIdempotent reports whether repeating method has no additional effect
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// decodeError converts an error response into *APIError. Responses that are
+// not API envelopes (e.g. the router's plain-text 404) keep their status.
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err == nil && env.Error != nil {
+		env.Error.StatusCode = resp.StatusCode
+		return env.Error
+	}
+
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Code:       "HTTP_" + strconv.Itoa(resp.StatusCode),
+		Message:    strings.TrimSpace(string(data)),
+	}
+}
@@ -0,0 +1,273 @@
+package userclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang-patterns/internal/infrastructure/blobstore"
+	"golang-patterns/internal/infrastructure/middleware"
+	"golang-patterns/internal/infrastructure/repositories"
+	"golang-patterns/internal/interfaces/handlers"
+	"golang-patterns/internal/usecases"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, fields ...interface{})  {}
+func (nopLogger) Error(msg string, fields ...interface{}) {}
+func (nopLogger) Debug(msg string, fields ...interface{}) {}
+
+func (nopLogger) InfoContext(ctx context.Context, msg string, fields ...interface{})  {}
+func (nopLogger) ErrorContext(ctx context.Context, msg string, fields ...interface{}) {}
+func (nopLogger) DebugContext(ctx context.Context, msg string, fields ...interface{}) {}
+
+// newTestServer serves the real user handlers backed by memory repositories
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	blobStore, err := blobstore.NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	userUseCase := usecases.NewUserUseCase(repositories.NewMemoryUserRepository(), repositories.NewMemoryAuditRepository(), repositories.NewMemoryUnitOfWork(), blobStore, nopLogger{})
+	userHandler := handlers.NewUserHandler(userUseCase)
+
+	router := mux.NewRouter()
+	router.Use(middleware.TenantMiddleware(""))
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/users/email/{email}", userHandler.GetUserByEmail).Methods("GET")
+	api.HandleFunc("/users/paginated", userHandler.GetUsersWithPagination).Methods("GET")
+	api.HandleFunc("/users/search", userHandler.SearchUsers).Methods("GET")
+	api.HandleFunc("/users/batch", userHandler.GetUsersBatch).Methods("GET")
+	api.HandleFunc("/users/bulk", userHandler.CreateUsersInBulk).Methods("POST")
+	api.HandleFunc("/users/bulk", userHandler.DeleteUsersInBulk).Methods("DELETE")
+	api.HandleFunc("/users/{id}/erase", userHandler.EraseUser).Methods("POST")
+	api.HandleFunc("/users", userHandler.CreateUser).Methods("POST")
+	api.HandleFunc("/users/{id}", userHandler.GetUser).Methods("GET")
+	api.HandleFunc("/users/{id}", userHandler.UpdateUser).Methods("PUT")
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, url string, opts ...Option) *Client {
+	t.Helper()
+	opts = append([]Option{WithRetry(3, time.Millisecond, 5*time.Millisecond)}, opts...)
+	client, err := New(url, opts...)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return client
+}
+
+func TestClient_AgainstServer(t *testing.T) {
+	server := newTestServer(t)
+	client := newTestClient(t, server.URL)
+	ctx := context.Background()
+
+	alice, err := client.CreateUser(ctx, &UserCreateRequest{Name: "Alice", Email: "alice+test@example.com", Department: "Engineering"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	t.Run("typed round trip", func(t *testing.T) {
+		got, err := client.GetUserByEmail(ctx, "alice+test@example.com")
+		if err != nil {
+			t.Fatalf("get by email: %v", err)
+		}
+		if got.ID != alice.ID || got.Department != "Engineering" {
+			t.Errorf("unexpected user: %+v", got)
+		}
+
+		name := "Alice Liddell"
+		updated, err := client.UpdateUser(ctx, alice.ID, &UserUpdateRequest{Name: &name})
+		if err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if updated.Name != name {
+			t.Errorf("name = %q, want %q", updated.Name, name)
+		}
+	})
+
+	t.Run("error codes map to sentinel errors", func(t *testing.T) {
+		_, err := client.GetUser(ctx, "user_999")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("err = %v, want ErrNotFound", err)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != "USER_NOT_FOUND" || apiErr.StatusCode != http.StatusNotFound {
+			t.Errorf("unexpected API error: %+v", apiErr)
+		}
+
+		if _, err := client.CreateUser(ctx, &UserCreateRequest{Name: "X", Email: "bad"}); !errors.Is(err, ErrValidation) {
+			t.Errorf("err = %v, want ErrValidation", err)
+		}
+
+		if _, err := client.EraseUser(ctx, alice.ID); err != nil {
+			t.Fatalf("erase: %v", err)
+		}
+		if _, err := client.EraseUser(ctx, alice.ID); !errors.Is(err, ErrConflict) {
+			t.Errorf("err = %v, want ErrConflict", err)
+		}
+	})
+
+	t.Run("iterators walk every page", func(t *testing.T) {
+		reqs := make([]*UserCreateRequest, 24)
+		for i := range reqs {
+			reqs[i] = &UserCreateRequest{Name: fmt.Sprintf("Bulk User %02d", i), Email: fmt.Sprintf("bulk%02d@example.com", i)}
+		}
+		if _, err := client.CreateUsersInBulk(ctx, reqs); err != nil {
+			t.Fatalf("bulk create: %v", err)
+		}
+
+		seen := map[string]bool{}
+		for user, err := range client.Users(ctx, ListOptions{PageSize: 10, SortField: "id", SortOrder: "asc"}) {
+			if err != nil {
+				t.Fatalf("iterate pages: %v", err)
+			}
+			seen[user.ID] = true
+		}
+		if len(seen) != 25 {
+			t.Errorf("page iterator saw %d users, want 25", len(seen))
+		}
+
+		count := 0
+		for _, err := range client.UsersByCursor(ctx, 7) {
+			if err != nil {
+				t.Fatalf("iterate cursor: %v", err)
+			}
+			count++
+		}
+		if count != 25 {
+			t.Errorf("cursor iterator saw %d users, want 25", count)
+		}
+
+		found := 0
+		for _, err := range client.SearchAll(ctx, "Bulk User", 5) {
+			if err != nil {
+				t.Fatalf("iterate search: %v", err)
+			}
+			found++
+		}
+		if found != 24 {
+			t.Errorf("search iterator found %d users, want 24", found)
+		}
+
+		stopped := 0
+		for range client.Users(ctx, ListOptions{PageSize: 10}) {
+			stopped++
+			if stopped == 3 {
+				break
+			}
+		}
+		if stopped != 3 {
+			t.Errorf("breaking out of the iterator should stop after 3 users, got %d", stopped)
+		}
+	})
+
+	t.Run("tenant header isolates data", func(t *testing.T) {
+		acme := newTestClient(t, server.URL, WithTenant("acme"))
+		if _, err := acme.GetUserByEmail(ctx, "bulk00@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("err = %v, want ErrNotFound", err)
+		}
+
+		invalid := newTestClient(t, server.URL, WithTenant("Not A Tenant!"))
+		if _, err := invalid.GetUser(ctx, alice.ID); !errors.Is(err, ErrInvalidTenant) {
+			t.Errorf("err = %v, want ErrInvalidTenant", err)
+		}
+	})
+}
+
+func TestClient_Retries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retries unavailable responses with backoff", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) < 3 {
+				w.Header().Set("Retry-After", "0")
+				handlers.WriteJSONError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "try again")
+				return
+			}
+			handlers.WriteJSONResponse(w, http.StatusCreated, &User{ID: "user_1", Name: "Alice"})
+		}))
+		defer server.Close()
+
+		user, err := newTestClient(t, server.URL).CreateUser(ctx, &UserCreateRequest{Name: "Alice", Email: "alice@example.com"})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if user.ID != "user_1" || attempts.Load() != 3 {
+			t.Errorf("user=%+v attempts=%d", user, attempts.Load())
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			handlers.WriteJSONError(w, http.StatusBadGateway, "BAD_GATEWAY", "upstream failed")
+		}))
+		defer server.Close()
+
+		_, err := newTestClient(t, server.URL).GetUser(ctx, "user_1")
+		if !errors.Is(err, ErrServer) {
+			t.Errorf("err = %v, want ErrServer", err)
+		}
+		if attempts.Load() != 4 {
+			t.Errorf("attempts = %d, want 4", attempts.Load())
+		}
+	})
+
+	t.Run("does not repeat non-idempotent requests on server errors", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			handlers.WriteJSONError(w, http.StatusBadGateway, "BAD_GATEWAY", "upstream failed")
+		}))
+		defer server.Close()
+
+		if _, err := newTestClient(t, server.URL).ActivateUser(ctx, "user_1"); err == nil {
+			t.Fatal("expected an error")
+		}
+		if attempts.Load() != 1 {
+			t.Errorf("attempts = %d, want 1", attempts.Load())
+		}
+	})
+
+	t.Run("lost races are reported as concurrent conflicts", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			handlers.WriteJSONError(w, http.StatusConflict, "CONCURRENT_UPDATE", "changed concurrently")
+		}))
+		defer server.Close()
+
+		name := "Alice"
+		_, err := newTestClient(t, server.URL).UpdateUser(ctx, "user_1", &UserUpdateRequest{Name: &name})
+		if !errors.Is(err, ErrConcurrentUpdate) || !errors.Is(err, ErrConflict) {
+			t.Errorf("err = %v, want ErrConcurrentUpdate and ErrConflict", err)
+		}
+		if attempts.Load() != 1 {
+			t.Errorf("attempts = %d, want 1", attempts.Load())
+		}
+	})
+
+	t.Run("non-envelope errors keep their status", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+
+		_, err := newTestClient(t, server.URL).GetUserSummary(ctx, "user_1")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != "HTTP_404" || !errors.Is(err, ErrNotFound) {
+			t.Errorf("err = %v, want HTTP_404 mapped to ErrNotFound", err)
+		}
+	})
+}
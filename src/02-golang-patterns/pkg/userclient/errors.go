@@ -0,0 +1,80 @@
+package userclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors reported by the API, matched with errors.Is against *APIError.
+// ErrConcurrentUpdate is a write that lost a race with another request and
+// is worth retrying; it also matches ErrConflict.
+var (
+	ErrNotFound         = errors.New("userclient: not found")
+	ErrValidation       = errors.New("userclient: invalid request")
+	ErrConflict         = errors.New("userclient: conflict")
+	ErrConcurrentUpdate = errors.New("userclient: changed concurrently")
+	ErrTimeout          = errors.New("userclient: server timed out")
+	ErrUnsupportedImage = errors.New("userclient: unsupported image")
+	ErrTooLarge         = errors.New("userclient: payload too large")
+	ErrInvalidTenant    = errors.New("userclient: invalid tenant")
+	ErrServer           = errors.New("userclient: server error")
+)
+
+// codeErrors maps APIError codes emitted by the handlers to sentinel errors
+var codeErrors = map[string]error{
+	"USER_NOT_FOUND":    ErrNotFound,
+	"AVATAR_NOT_FOUND":  ErrNotFound,
+	"JOB_NOT_FOUND":     ErrNotFound,
+	"VALIDATION_ERROR":  ErrValidation,
+	"INVALID_JSON":      ErrValidation,
+	"INVALID_PARAMS":    ErrValidation,
+	"INVALID_ID":        ErrValidation,
+	"INVALID_SIZE":      ErrValidation,
+	"INVALID_UPLOAD":    ErrValidation,
+	"MISSING_QUERY":     ErrValidation,
+	"ALREADY_ERASED":    ErrConflict,
+	"USER_ERASED":       ErrConflict,
+	"JOB_RUNNING":       ErrConflict,
+	"CONCURRENT_UPDATE": ErrConcurrentUpdate,
+	"TIMEOUT":           ErrTimeout,
+	"UNSUPPORTED_IMAGE": ErrUnsupportedImage,
+	"FILE_TOO_LARGE":    ErrTooLarge,
+	"INVALID_TENANT":    ErrInvalidTenant,
+}
+
+// APIError is a non-2xx response from the API
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Details    string `json:"details,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("userclient: %s (%d %s)", e.Message, e.StatusCode, e.Code)
+}
+
+// Is reports whether target is the sentinel error for this response, using
+// the error code when it is known and the HTTP status otherwise
+func (e *APIError) Is(target error) bool {
+	if mapped, ok := codeErrors[e.Code]; ok {
+		return mapped == target || (mapped == ErrConcurrentUpdate && target == ErrConflict)
+	}
+
+	switch {
+	case e.StatusCode == http.StatusNotFound:
+		return target == ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return target == ErrConflict
+	case e.StatusCode == http.StatusRequestTimeout:
+		return target == ErrTimeout
+	case e.StatusCode == http.StatusRequestEntityTooLarge:
+		return target == ErrTooLarge
+	case e.StatusCode >= 500:
+		return target == ErrServer
+	case e.StatusCode >= 400:
+		return target == ErrValidation
+	}
+	return false
+}
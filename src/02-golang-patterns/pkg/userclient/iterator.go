@@ -0,0 +1,72 @@
+package userclient
+
+import (
+	"context"
+	"iter"
+)
+
+// Users iterates over every user matching opts, fetching pages on demand
+// starting at opts.Page. Iteration stops at the first error, which is
+// yielded with a nil user.
+func (c *Client) Users(ctx context.Context, opts ListOptions) iter.Seq2[*User, error] {
+	return c.pages(opts.Page, func(page int) (*UserPage, error) {
+		opts.Page = page
+		return c.GetUsersPage(ctx, opts)
+	})
+}
+
+// SearchAll iterates over every search result for q
+func (c *Client) SearchAll(ctx context.Context, q string, pageSize int) iter.Seq2[*User, error] {
+	return c.pages(1, func(page int) (*UserPage, error) {
+		return c.SearchUsers(ctx, q, page, pageSize)
+	})
+}
+
+// UsersByCursor iterates over all users with cursor-based batches. Unlike
+// page numbers, cursors do not skip or repeat users when others are created
+// or deleted during iteration.
+func (c *Client) UsersByCursor(ctx context.Context, batchSize int) iter.Seq2[*User, error] {
+	return func(yield func(*User, error) bool) {
+		cursor := ""
+		for {
+			batch, err := c.GetUsersBatch(ctx, batchSize, cursor, "forward")
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, user := range batch.Users {
+				if !yield(user, nil) {
+					return
+				}
+			}
+			if !batch.HasMore || batch.NextCursor == "" {
+				return
+			}
+			cursor = batch.NextCursor
+		}
+	}
+}
+
+// pages yields the users of successive pages returned by fetch
+func (c *Client) pages(first int, fetch func(page int) (*UserPage, error)) iter.Seq2[*User, error] {
+	if first < 1 {
+		first = 1
+	}
+	return func(yield func(*User, error) bool) {
+		for page := first; ; page++ {
+			result, err := fetch(page)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, user := range result.Users {
+				if !yield(user, nil) {
+					return
+				}
+			}
+			if len(result.Users) == 0 || result.Page >= result.TotalPages {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package userclient
+
+import "golang-patterns/internal/domain/models"
+
+// Domain types shared with the server. They are aliases so values decoded by
+// the client are identical to the ones the server encodes.
+type (
+	User               = models.User
+	UserCreateRequest  = models.UserCreateRequest
+	UserUpdateRequest  = models.UserUpdateRequest
+	UserStats          = models.UserStats
+	Avatar             = models.Avatar
+	DuplicateCandidate = models.DuplicateCandidate
+	MergeUsersRequest  = models.MergeUsersRequest
+	PersonalDataExport = models.PersonalDataExport
+	AuditEntry         = models.AuditEntry
+	JobInfo            = models.JobInfo
+	JobRun             = models.JobRun
+	JobReport          = models.JobReport
+	AffectedUser       = models.AffectedUser
+)
+
+// ListOptions selects a page of GET /users/paginated
+type ListOptions struct {
+	Page      int
+	PageSize  int
+	SortField string
+	SortOrder string
+	Name      string
+	Email     string
+}
+
+// UserPage is one page of users as returned by paginated endpoints
+type UserPage struct {
+	Users      []*User `json:"data"`
+	Total      int     `json:"total"`
+	Page       int     `json:"page"`
+	PageSize   int     `json:"page_size"`
+	TotalPages int     `json:"total_pages"`
+}
+
+// UserBatch is one cursor-based batch from GET /users/batch
+type UserBatch struct {
+	Users      []*User `json:"data"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	PrevCursor string  `json:"prev_cursor,omitempty"`
+	HasMore    bool    `json:"has_more"`
+}
+
+// UserSummary is returned by GET /users/{id}/summary
+type UserSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Department  string `json:"department"`
+	Position    string `json:"position"`
+	IsActive    bool   `json:"is_active"`
+	MemberSince string `json:"member_since"`
+	LastUpdated string `json:"last_updated"`
+	HasLoggedIn bool   `json:"has_logged_in"`
+	LastLogin   string `json:"last_login,omitempty"`
+}
+
+// AvatarUpload is returned by PUT /users/{id}/avatar
+type AvatarUpload struct {
+	User *User             `json:"user"`
+	URLs map[string]string `json:"urls"`
+}
+
+// AvatarImage is a downloaded avatar thumbnail
+type AvatarImage struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+}
+
+// Health is returned by GET /health
+type Health struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+}
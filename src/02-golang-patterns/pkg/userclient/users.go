@@ -0,0 +1,466 @@
+package userclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// userPath builds /api/users/{id}[/suffix] with the ID escaped
+func userPath(id, suffix string) string {
+	path := "/api/users/" + url.PathEscape(id)
+	if suffix != "" {
+		path += "/" + suffix
+	}
+	return path
+}
+
+// getJSON performs a GET request and decodes the response data into out
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.call(ctx, &request{method: http.MethodGet, path: path, query: query}, out)
+}
+
+// sendJSON performs a request with a JSON body and decodes the response data into out
+func (c *Client) sendJSON(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := jsonRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, req, out)
+}
+
+// === Basic CRUD Operations ===
+
+// CreateUser calls POST /api/users
+func (c *Client) CreateUser(ctx context.Context, req *UserCreateRequest) (*User, error) {
+	var user User
+	if err := c.sendJSON(ctx, http.MethodPost, "/api/users", req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUser calls GET /api/users/{id}
+func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
+	var user User
+	if err := c.getJSON(ctx, userPath(id, ""), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUser calls PUT /api/users/{id}
+func (c *Client) UpdateUser(ctx context.Context, id string, req *UserUpdateRequest) (*User, error) {
+	var user User
+	if err := c.sendJSON(ctx, http.MethodPut, userPath(id, ""), req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser calls DELETE /api/users/{id}. The user is soft-deleted: it
+// disappears from every query and frees its email at once, but the server
+// keeps it until the purge-deleted-users job removes it 30 days later.
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.sendJSON(ctx, http.MethodDelete, userPath(id, ""), nil, nil)
+}
+
+// GetAllUsers calls GET /api/users
+func (c *Client) GetAllUsers(ctx context.Context) ([]*User, error) {
+	var users []*User
+	if err := c.getJSON(ctx, "/api/users", nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// === Target Specification ===
+
+// GetUserByEmail calls GET /api/users/email/{email}
+func (c *Client) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	var user User
+	if err := c.getJSON(ctx, "/api/users/email/"+url.PathEscape(email), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUsersByDepartment calls GET /api/users/department/{department}
+func (c *Client) GetUsersByDepartment(ctx context.Context, department string) ([]*User, error) {
+	var users []*User
+	if err := c.getJSON(ctx, "/api/users/department/"+url.PathEscape(department), nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUsersByPosition calls GET /api/users/position/{position}
+func (c *Client) GetUsersByPosition(ctx context.Context, position string) ([]*User, error) {
+	var users []*User
+	if err := c.getJSON(ctx, "/api/users/position/"+url.PathEscape(position), nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetActiveUsers calls GET /api/users/active
+func (c *Client) GetActiveUsers(ctx context.Context) ([]*User, error) {
+	var users []*User
+	if err := c.getJSON(ctx, "/api/users/active", nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetInactiveUsers calls GET /api/users/inactive
+func (c *Client) GetInactiveUsers(ctx context.Context) ([]*User, error) {
+	var users []*User
+	if err := c.getJSON(ctx, "/api/users/inactive", nil, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// === Pagination, Search and Progressive Loading ===
+
+// GetUsersPage calls GET /api/users/paginated
+func (c *Client) GetUsersPage(ctx context.Context, opts ListOptions) (*UserPage, error) {
+	query := url.Values{}
+	setInt(query, "page", opts.Page)
+	setInt(query, "page_size", opts.PageSize)
+	setString(query, "sort_field", opts.SortField)
+	setString(query, "sort_order", opts.SortOrder)
+	setString(query, "name", opts.Name)
+	setString(query, "email", opts.Email)
+
+	var page UserPage
+	if err := c.getJSON(ctx, "/api/users/paginated", query, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// SearchUsers calls GET /api/users/search
+func (c *Client) SearchUsers(ctx context.Context, q string, page, pageSize int) (*UserPage, error) {
+	query := url.Values{"q": {q}}
+	setInt(query, "page", page)
+	setInt(query, "page_size", pageSize)
+
+	var result UserPage
+	if err := c.getJSON(ctx, "/api/users/search", query, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetUsersBatch calls GET /api/users/batch. Direction is "forward" or "backward".
+func (c *Client) GetUsersBatch(ctx context.Context, batchSize int, cursor, direction string) (*UserBatch, error) {
+	query := url.Values{}
+	setInt(query, "batch_size", batchSize)
+	setString(query, "cursor", cursor)
+	setString(query, "direction", direction)
+
+	var batch UserBatch
+	if err := c.getJSON(ctx, "/api/users/batch", query, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// === Statistics and Analytics ===
+
+// GetUserStats calls GET /api/users/stats
+func (c *Client) GetUserStats(ctx context.Context) (*UserStats, error) {
+	var stats UserStats
+	if err := c.getJSON(ctx, "/api/users/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetDepartmentStats calls GET /api/users/stats/departments
+func (c *Client) GetDepartmentStats(ctx context.Context) (map[string]int, error) {
+	var stats map[string]int
+	if err := c.getJSON(ctx, "/api/users/stats/departments", nil, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetRecentSignups calls GET /api/users/recent-signups; days <= 0 uses the server default
+func (c *Client) GetRecentSignups(ctx context.Context, days int) ([]*User, error) {
+	query := url.Values{}
+	setInt(query, "days", days)
+
+	var users []*User
+	if err := c.getJSON(ctx, "/api/users/recent-signups", query, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// === Duplicate Detection and Merge ===
+
+// FindDuplicateUsers calls GET /api/users/duplicates; threshold <= 0 uses the server default
+func (c *Client) FindDuplicateUsers(ctx context.Context, threshold float64) ([]*DuplicateCandidate, error) {
+	query := url.Values{}
+	if threshold > 0 {
+		query.Set("threshold", strconv.FormatFloat(threshold, 'f', -1, 64))
+	}
+
+	var candidates []*DuplicateCandidate
+	if err := c.getJSON(ctx, "/api/users/duplicates", query, &candidates); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// MergeUsers calls POST /api/users/merge
+func (c *Client) MergeUsers(ctx context.Context, req *MergeUsersRequest) (*User, error) {
+	var user User
+	if err := c.sendJSON(ctx, http.MethodPost, "/api/users/merge", req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// === Bulk Operations ===
+
+// CreateUsersInBulk calls POST /api/users/bulk
+func (c *Client) CreateUsersInBulk(ctx context.Context, reqs []*UserCreateRequest) ([]*User, error) {
+	var users []*User
+	if err := c.sendJSON(ctx, http.MethodPost, "/api/users/bulk", reqs, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UpdateUsersInBulk calls PUT /api/users/bulk with updates keyed by user ID
+func (c *Client) UpdateUsersInBulk(ctx context.Context, updates map[string]*UserUpdateRequest) ([]*User, error) {
+	var users []*User
+	if err := c.sendJSON(ctx, http.MethodPut, "/api/users/bulk", updates, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// DeleteUsersInBulk calls DELETE /api/users/bulk, soft-deleting the users
+// like DeleteUser
+func (c *Client) DeleteUsersInBulk(ctx context.Context, ids []string) error {
+	body := struct {
+		IDs []string `json:"ids"`
+	}{IDs: ids}
+	return c.sendJSON(ctx, http.MethodDelete, "/api/users/bulk", body, nil)
+}
+
+// === Progressive Enhancement ===
+
+// ActivateUser calls POST /api/users/{id}/activate
+func (c *Client) ActivateUser(ctx context.Context, id string) (*User, error) {
+	var user User
+	if err := c.sendJSON(ctx, http.MethodPost, userPath(id, "activate"), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeactivateUser calls POST /api/users/{id}/deactivate
+func (c *Client) DeactivateUser(ctx context.Context, id string) (*User, error) {
+	var user User
+	if err := c.sendJSON(ctx, http.MethodPost, userPath(id, "deactivate"), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RecordLogin calls POST /api/users/{id}/login
+func (c *Client) RecordLogin(ctx context.Context, id string) (*User, error) {
+	var user User
+	if err := c.sendJSON(ctx, http.MethodPost, userPath(id, "login"), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserSummary calls GET /api/users/{id}/summary
+func (c *Client) GetUserSummary(ctx context.Context, id string) (*UserSummary, error) {
+	var summary UserSummary
+	if err := c.getJSON(ctx, userPath(id, "summary"), nil, &summary); err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// === Personal Data Requests ===
+
+// ExportUserData calls GET /api/users/{id}/export
+func (c *Client) ExportUserData(ctx context.Context, id string) (*PersonalDataExport, error) {
+	var export PersonalDataExport
+	if err := c.getJSON(ctx, userPath(id, "export"), nil, &export); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// EraseUser calls POST /api/users/{id}/erase
+func (c *Client) EraseUser(ctx context.Context, id string) (*User, error) {
+	var user User
+	if err := c.sendJSON(ctx, http.MethodPost, userPath(id, "erase"), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// === Avatars ===
+
+// UploadAvatar calls PUT /api/users/{id}/avatar with the image read from r
+func (c *Client) UploadAvatar(ctx context.Context, id, filename string, r io.Reader) (*AvatarUpload, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("avatar", filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	req := &request{
+		method:      http.MethodPut,
+		path:        userPath(id, "avatar"),
+		body:        body.Bytes(),
+		contentType: form.FormDataContentType(),
+	}
+
+	var upload AvatarUpload
+	if err := c.call(ctx, req, &upload); err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// GetAvatar calls GET /api/users/{id}/avatar; size <= 0 uses the server default
+func (c *Client) GetAvatar(ctx context.Context, id string, size int) (*AvatarImage, error) {
+	query := url.Values{}
+	setInt(query, "size", size)
+
+	resp, err := c.send(ctx, &request{method: http.MethodGet, path: userPath(id, "avatar"), query: query})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, decodeError(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+	return &AvatarImage{
+		Data:        data,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+	}, nil
+}
+
+// === Lifecycle Jobs ===
+
+// ListJobs calls GET /api/jobs
+func (c *Client) ListJobs(ctx context.Context) ([]JobInfo, error) {
+	var jobs []JobInfo
+	if err := c.getJSON(ctx, "/api/jobs", nil, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// GetJobRuns calls GET /api/jobs/runs; an empty job returns runs of all jobs.
+// Only the runs and affected users of the client's tenant are returned.
+func (c *Client) GetJobRuns(ctx context.Context, job string, limit int) ([]*JobRun, error) {
+	query := url.Values{}
+	setString(query, "job", job)
+	setInt(query, "limit", limit)
+
+	var runs []*JobRun
+	if err := c.getJSON(ctx, "/api/jobs/runs", query, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// RunJob calls POST /api/jobs/{name}/run, running the job for the client's
+// tenant only. A run that fails on the server is returned together with an
+// *APIError so its report can still be inspected.
+func (c *Client) RunJob(ctx context.Context, name string, dryRun bool) (*JobRun, error) {
+	req := &request{
+		method: http.MethodPost,
+		path:   "/api/jobs/" + url.PathEscape(name) + "/run",
+		query:  url.Values{"dry_run": {strconv.FormatBool(dryRun)}},
+	}
+
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Failed runs are reported as a 500 whose data is the run itself
+	if resp.StatusCode == http.StatusInternalServerError {
+		data, _ := io.ReadAll(resp.Body)
+		var env envelope
+		var run JobRun
+		if json.Unmarshal(data, &env) == nil && env.Error == nil && json.Unmarshal(env.Data, &run) == nil && run.ID != "" {
+			return &run, &APIError{StatusCode: resp.StatusCode, Code: "JOB_FAILED", Message: run.Error}
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	if resp.StatusCode >= 400 {
+		return nil, decodeError(resp)
+	}
+
+	var env envelope
+	var run JobRun
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := json.Unmarshal(env.Data, &run); err != nil {
+		return nil, fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return &run, nil
+}
+
+// === Health ===
+
+// Health calls GET /health
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var health Health
+	if err := c.call(ctx, &request{method: http.MethodGet, path: "/health"}, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// setInt sets key when value is positive
+func setInt(query url.Values, key string, value int) {
+	if value > 0 {
+		query.Set(key, strconv.Itoa(value))
+	}
+}
+
+// setString sets key when value is non-empty
+func setString(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
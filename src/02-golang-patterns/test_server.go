@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"golang-patterns/internal/domain/models"
+	"golang-patterns/internal/infrastructure/blobstore"
 	"golang-patterns/internal/infrastructure/logger"
 	"golang-patterns/internal/infrastructure/middleware"
 	"golang-patterns/internal/infrastructure/repositories"
@@ -12,6 +13,8 @@ import (
 	"golang-patterns/internal/usecases"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 
 	"github.com/gorilla/mux"
 )
@@ -22,13 +25,22 @@ func main() {
 
 	// Setup the enhanced dependencies
 	userRepo := repositories.NewMemoryUserRepository()
-	logger := logger.NewConsoleLogger()
-	userUseCase := usecases.NewUserUseCase(userRepo, logger)
+	auditRepo := repositories.NewMemoryAuditRepository()
+	unitOfWork := repositories.NewMemoryUnitOfWork()
+	logger := logger.NewSlogLogger(logger.Config{Format: logger.FormatText})
+	blobStore, err := blobstore.NewLocalBlobStore(filepath.Join(os.TempDir(), "golang-patterns-test-blobs"))
+	if err != nil {
+		fmt.Printf("Failed to initialize blob store: %v\n", err)
+		return
+	}
+	userUseCase := usecases.NewUserUseCase(userRepo, auditRepo, unitOfWork, blobStore, logger)
 	userHandler := handlers.NewUserHandler(userUseCase)
 
 	router := mux.NewRouter()
+	router.Use(middleware.RequestIDMiddleware)
 	router.Use(middleware.CORSMiddleware)
-	router.Use(middleware.LoggingMiddleware)
+	router.Use(middleware.LoggingMiddleware(logger))
+	router.Use(middleware.TenantMiddleware(""))
 
 	api := router.PathPrefix("/api").Subrouter()
 
@@ -106,6 +118,10 @@ func setupRoutes(api *mux.Router, userHandler *handlers.UserHandler) {
 	api.HandleFunc("/users/stats/departments", userHandler.GetDepartmentStats).Methods("GET")
 	api.HandleFunc("/users/recent-signups", userHandler.GetRecentSignups).Methods("GET")
 
+	// Duplicate detection and merge
+	api.HandleFunc("/users/duplicates", userHandler.FindDuplicateUsers).Methods("GET")
+	api.HandleFunc("/users/merge", userHandler.MergeUsers).Methods("POST")
+
 	// Bulk operations
 	api.HandleFunc("/users/bulk", userHandler.CreateUsersInBulk).Methods("POST")
 	api.HandleFunc("/users/bulk", userHandler.UpdateUsersInBulk).Methods("PUT")
@@ -117,6 +133,10 @@ func setupRoutes(api *mux.Router, userHandler *handlers.UserHandler) {
 	api.HandleFunc("/users/{id}/login", userHandler.UpdateLastLogin).Methods("POST")
 	api.HandleFunc("/users/{id}/summary", userHandler.GetUserSummary).Methods("GET")
 
+	// Personal data requests
+	api.HandleFunc("/users/{id}/export", userHandler.ExportUserData).Methods("GET")
+	api.HandleFunc("/users/{id}/erase", userHandler.EraseUser).Methods("POST")
+
 	// Basic CRUD (generic {id} routes MUST be LAST)
 	api.HandleFunc("/users", userHandler.CreateUser).Methods("POST")
 	api.HandleFunc("/users", userHandler.GetAllUsers).Methods("GET")
@@ -0,0 +1,125 @@
+// Command hxlint renders the demo pages in memory and reports hx-*
+// attributes whose URL has no route for its method, whose selectors match
+// nothing, or whose triggers do not parse. It exits with status 1 when it
+// finds any.
+//
+//	go run ./cmd/hxlint            # every page
+//	go run ./cmd/hxlint /forms /   # only these pages
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"htmx-demo/internal/autocomplete"
+	"htmx-demo/internal/chaos"
+	"htmx-demo/internal/handlers"
+	"htmx-demo/internal/hxcheck"
+	"htmx-demo/internal/models"
+	"htmx-demo/internal/notify"
+	"htmx-demo/internal/session"
+	"htmx-demo/internal/taxonomy"
+	"htmx-demo/internal/templates"
+	"htmx-demo/internal/toast"
+	"htmx-demo/internal/upload"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// adminToken authorizes the checker on the admin pages
+const adminToken = "hxlint"
+
+func main() {
+	timeout := flag.Duration("timeout", 5*time.Second, "time limit for rendering each page or fragment")
+	list := flag.Bool("list", false, "list the pages that would be checked and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: hxlint [flags] [page ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	router, cleanup, err := newRouter()
+	if err != nil {
+		log.Fatalf("Failed to set up the router: %v", err)
+	}
+	defer cleanup()
+
+	header := make(http.Header)
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:"+adminToken)))
+	checker := &hxcheck.Checker{Router: router, Header: header, Timeout: *timeout}
+
+	pages := flag.Args()
+	if len(pages) == 0 {
+		if pages, err = checker.Pages(); err != nil {
+			log.Fatalf("Failed to list pages: %v", err)
+		}
+	}
+	if *list {
+		for _, page := range pages {
+			fmt.Println(page)
+		}
+		return
+	}
+
+	issues, err := checker.Check(pages...)
+	if err != nil {
+		log.Fatalf("Failed to check pages: %v", err)
+	}
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		cleanup()
+		fmt.Fprintf(os.Stderr, "%d issues in %d pages\n", len(issues), len(pages))
+		os.Exit(1)
+	}
+}
+
+// newRouter builds the demo router with in-memory state and uploads in a
+// temporary directory, which cleanup removes
+func newRouter() (*mux.Router, func(), error) {
+	sessions, err := session.NewManager(session.NewSecret(), time.Hour)
+	if err != nil {
+		return nil, nil, err
+	}
+	store := session.NewStore(time.Hour, models.NewDemoState)
+	notifier := toast.NewNotifier(session.NewStore(time.Hour, toast.NewFlash), toast.Views{Toasts: templates.Toasts, Modal: templates.Modal})
+
+	dir, err := os.MkdirTemp("", "hxlint-uploads-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+	uploadConfig := upload.Config{Dir: dir, MaxBytes: upload.DefaultMaxBytes, AllowedTypes: upload.DefaultAllowedTypes}
+	uploads, err := upload.NewStore(dir)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	taxonomyStore, err := taxonomy.NewStore("")
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	// Latency rules are left out so the fragments render without delay
+	router := handlers.NewRouter(handlers.Services{
+		SessionManager: sessions,
+		Sessions:       store,
+		Notifier:       notifier,
+		Notifications:  notify.NewBroker(notify.DefaultConfig()),
+		Cities:         autocomplete.NewIndex(autocomplete.DefaultCities()),
+		Uploads:        uploads,
+		UploadConfig:   uploadConfig,
+		Taxonomy:       taxonomyStore,
+		Chaos:          chaos.NewInjector(chaos.Config{}),
+		ChaosDefaults:  handlers.DemoLatency(),
+		AdminToken:     adminToken,
+	})
+	return router, cleanup, nil
+}
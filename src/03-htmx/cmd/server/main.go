@@ -1,89 +1,150 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"htmx-demo/internal/autocomplete"
+	"htmx-demo/internal/chaos"
 	"htmx-demo/internal/handlers"
+	"htmx-demo/internal/models"
+	"htmx-demo/internal/notify"
+	"htmx-demo/internal/session"
+	"htmx-demo/internal/taxonomy"
+	"htmx-demo/internal/templates"
+	"htmx-demo/internal/toast"
+	"htmx-demo/internal/upload"
 	"log"
 	"net/http"
 	"os"
-
-	"github.com/gorilla/mux"
+	"time"
 )
 
+// sessionTTL is how long an idle visitor keeps their demo state
+const sessionTTL = 30 * time.Minute
+
 func main() {
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	// Initialize handlers
-	pageHandler := handlers.NewPageHandler()
-	apiHandler := handlers.NewAPIHandler()
+	// Sessions are signed with SESSION_SECRET; without it a random secret is
+	// used and sessions do not survive a restart
+	secret := []byte(os.Getenv("SESSION_SECRET"))
+	if len(secret) == 0 {
+		log.Printf("SESSION_SECRET is not set; using a random secret")
+		secret = session.NewSecret()
+	}
+	sessionManager, err := session.NewManager(secret, sessionTTL)
+	if err != nil {
+		log.Fatalf("Failed to configure sessions: %v", err)
+	}
+	sessionStore := session.NewStore(sessionTTL, models.NewDemoState)
+	go sessionStore.RunCleanup(context.Background(), time.Minute)
+
+	// Toasts and dialogs that no response delivered, e.g. after a redirect,
+	// wait for the next page of the visitor
+	flashStore := session.NewStore(sessionTTL, toast.NewFlash)
+	go flashStore.RunCleanup(context.Background(), time.Minute)
+	notifier := toast.NewNotifier(flashStore, toast.Views{Toasts: templates.Toasts, Modal: templates.Modal})
+
+	// Notifications are pushed per session; brokers of idle visitors are
+	// removed along with their schedules
+	notificationBroker := notify.NewBroker(notify.DefaultConfig())
+	go notificationBroker.RunCleanup(context.Background(), time.Minute, sessionTTL)
+
+	// UPLOAD_DIR, UPLOAD_MAX_BYTES and UPLOAD_ALLOWED_TYPES configure uploads
+	uploadConfig, err := upload.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure uploads: %v", err)
+	}
+	uploadStore, err := upload.NewStore(uploadConfig.Dir)
+	if err != nil {
+		log.Fatalf("Failed to initialize upload store: %v", err)
+	}
+	// Upload records live in memory, so blobs of an earlier run are orphans;
+	// the files of a visitor go when their session expires
+	if _, err := uploadStore.CollectGarbage(); err != nil {
+		log.Printf("Failed to remove orphaned uploads: %v", err)
+	}
+	sessionStore.OnExpire(func(id string) {
+		if _, err := uploadStore.DeleteOwner(id); err != nil {
+			log.Printf("Failed to remove uploads of an expired session: %v", err)
+		}
+	})
+
+	// CITY_DATA_FILE replaces the bundled city list used by autocomplete
+	cities := autocomplete.DefaultCities()
+	if path := os.Getenv("CITY_DATA_FILE"); path != "" {
+		if cities, err = loadCities(path); err != nil {
+			log.Fatalf("Failed to load city data: %v", err)
+		}
+	}
+	cityIndex := autocomplete.NewIndex(cities)
 
-	// Setup router
-	router := mux.NewRouter()
+	// TAXONOMY_FILE (JSON or YAML) holds the categories of the dynamic form.
+	// Edits on the admin page are written back to it and edits made by hand
+	// are picked up; without it the bundled taxonomy is kept in memory.
+	taxonomyStore, err := taxonomy.NewStore(os.Getenv("TAXONOMY_FILE"))
+	if err != nil {
+		log.Fatalf("Failed to load taxonomy: %v", err)
+	}
+	go taxonomyStore.Watch(context.Background(), 2*time.Second)
+
+	// The admin pages ask for ADMIN_TOKEN as the basic auth password; without
+	// it a random token is generated and logged
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		adminToken = fmt.Sprintf("%x", session.NewSecret()[:16])
+		log.Printf("ADMIN_TOKEN is not set; admin password for this run: %s", adminToken)
+	}
 
-	// Page routes
-	router.HandleFunc("/", pageHandler.Home).Methods("GET")
-	router.HandleFunc("/basic-requests", pageHandler.BasicRequests).Methods("GET")
-	router.HandleFunc("/triggers", pageHandler.Triggers).Methods("GET")
-	router.HandleFunc("/targets", pageHandler.Targets).Methods("GET")
-	router.HandleFunc("/indicators", pageHandler.Indicators).Methods("GET")
-	router.HandleFunc("/forms", pageHandler.Forms).Methods("GET")
-	router.HandleFunc("/progressive", pageHandler.Progressive).Methods("GET")
+	// Demo latency comes from the chaos middleware rather than the handlers.
+	// CHAOS_CONFIG names a JSON file replacing the demo delays, e.g. to
+	// inject faults; the admin page changes them while the server runs.
+	chaosConfig := handlers.DemoLatency()
+	if path := os.Getenv("CHAOS_CONFIG"); path != "" {
+		if chaosConfig, err = loadChaosConfig(path); err != nil {
+			log.Fatalf("Failed to load chaos configuration: %v", err)
+		}
+	}
+	chaosInjector := chaos.NewInjector(chaosConfig)
 
-	// API routes for HTMX
-	api := router.PathPrefix("/api").Subrouter()
-	
-	// Basic requests APIs
-	api.HandleFunc("/users", apiHandler.GetUsers).Methods("GET")
-	api.HandleFunc("/users", apiHandler.CreateUser).Methods("POST")
-	api.HandleFunc("/echo", apiHandler.Echo).Methods("PUT", "DELETE")
-	api.HandleFunc("/search", apiHandler.Search).Methods("GET", "POST")
-	api.HandleFunc("/time", apiHandler.GetTime).Methods("GET")
-	api.HandleFunc("/focus-data", apiHandler.FocusData).Methods("GET")
-	api.HandleFunc("/special-action", apiHandler.SpecialAction).Methods("POST")
-	api.HandleFunc("/custom-response", apiHandler.CustomResponse).Methods("GET")
-	
-	// Target specification APIs
-	api.HandleFunc("/target-content", apiHandler.TargetContent).Methods("GET")
-	api.HandleFunc("/multi-target", apiHandler.MultiTarget).Methods("GET")
-	api.HandleFunc("/selector-target", apiHandler.SelectorTarget).Methods("GET")
-	api.HandleFunc("/relative-target", apiHandler.RelativeTarget).Methods("GET")
-	api.HandleFunc("/swap-demo", apiHandler.SwapDemo).Methods("GET")
-	
-	// Indicators APIs
-	api.HandleFunc("/slow-response", apiHandler.SlowResponse).Methods("GET")
-	api.HandleFunc("/progress-response", apiHandler.ProgressResponse).Methods("GET")
-	api.HandleFunc("/skeleton-response", apiHandler.SkeletonResponse).Methods("GET")
-	
-	// Forms APIs
-	api.HandleFunc("/form-submit", apiHandler.FormSubmit).Methods("POST")
-	api.HandleFunc("/validate-username", apiHandler.ValidateUsername).Methods("POST")
-	api.HandleFunc("/validate-password", apiHandler.ValidatePassword).Methods("POST")
-	api.HandleFunc("/validate-password-confirm", apiHandler.ValidatePasswordConfirm).Methods("POST")
-	api.HandleFunc("/validate-form", apiHandler.ValidateForm).Methods("POST")
-	api.HandleFunc("/subcategories", apiHandler.Subcategories).Methods("GET")
-	api.HandleFunc("/dynamic-form", apiHandler.DynamicForm).Methods("POST")
-	api.HandleFunc("/file-upload", apiHandler.FileUpload).Methods("POST")
-	api.HandleFunc("/edit-field", apiHandler.EditField).Methods("GET")
-	api.HandleFunc("/save-field", apiHandler.SaveField).Methods("POST")
-	api.HandleFunc("/cancel-edit", apiHandler.CancelEdit).Methods("GET")
-	api.HandleFunc("/bulk-action", apiHandler.BulkAction).Methods("POST")
-	
-	// Progressive APIs
-	api.HandleFunc("/load-more", apiHandler.LoadMore).Methods("GET")
-	api.HandleFunc("/live-counter", apiHandler.LiveCounter).Methods("GET")
-	api.HandleFunc("/live-stats", apiHandler.LiveStats).Methods("GET")
-	api.HandleFunc("/progressive-load", apiHandler.ProgressiveLoad).Methods("GET")
-	api.HandleFunc("/lazy-content", apiHandler.LazyContent).Methods("GET")
-	api.HandleFunc("/autocomplete", apiHandler.Autocomplete).Methods("GET")
-	api.HandleFunc("/select-city", apiHandler.SelectCity).Methods("GET")
-	api.HandleFunc("/save-order", apiHandler.SaveOrder).Methods("POST")
-	api.HandleFunc("/start-notifications", apiHandler.StartNotifications).Methods("POST")
-	api.HandleFunc("/stop-notifications", apiHandler.StopNotifications).Methods("POST")
-	api.HandleFunc("/notification-updates", apiHandler.NotificationUpdates).Methods("GET")
+	router := handlers.NewRouter(handlers.Services{
+		SessionManager: sessionManager,
+		Sessions:       sessionStore,
+		Notifier:       notifier,
+		Notifications:  notificationBroker,
+		Cities:         cityIndex,
+		Uploads:        uploadStore,
+		UploadConfig:   uploadConfig,
+		Taxonomy:       taxonomyStore,
+		Chaos:          chaosInjector,
+		ChaosDefaults:  chaosConfig,
+		AdminToken:     adminToken,
+	})
 
 	log.Printf("HTMX Demo Server starting on port %s", port)
 	log.Printf("Open http://localhost:%s to view the demo", port)
 	log.Fatal(http.ListenAndServe(":"+port, router))
-}
\ No newline at end of file
+}
+
+// loadCities reads a JSON city list for the autocomplete index
+func loadCities(path string) ([]autocomplete.City, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return autocomplete.LoadCities(f)
+}
+
+// loadChaosConfig reads a JSON chaos configuration
+func loadChaosConfig(path string) (chaos.Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return chaos.Config{}, err
+	}
+	defer f.Close()
+	return chaos.LoadConfig(f)
+}
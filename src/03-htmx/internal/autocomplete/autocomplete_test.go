@@ -0,0 +1,149 @@
+package autocomplete
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"トウキョウ":  "とうきょう",
+		"ﾄｳｷｮｳ":  "とうきょう",
+		"ｻｯﾎﾟﾛ":  "さっぽろ",
+		"ｶﾞｷﾞｸﾞ": "がぎぐ",
+		"ガ":     "が",
+		"ＴＯＫＹＯ":  "tokyo",
+		"Tokyo":  "tokyo",
+		"大 阪":    "大阪",
+		"さいたま":   "さいたま",
+	}
+	for input, want := range tests {
+		if got := Normalize(input); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestRomajiKey(t *testing.T) {
+	tests := map[string]string{
+		"tokyo":        "tokyo",
+		"toukyou":      "tokyo",
+		"Tookyoo":      "tokyo",
+		"oosaka":       "osaka",
+		"hukuoka":      "fukuoka",
+		"sizuoka":      "shizuoka",
+		"kitakyuusyuu": "kitakyushu",
+		"sendai":       "sendai",
+		"senndai":      "sendai",
+	}
+	for input, want := range tests {
+		if got := RomajiKey(input); got != want {
+			t.Errorf("RomajiKey(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func names(matches []Match) []string {
+	var result []string
+	for _, m := range matches {
+		result = append(result, m.City.Name)
+	}
+	return result
+}
+
+func testIndex() *Index {
+	return NewIndex([]City{
+		{Name: "大阪", Kana: "おおさか", Romaji: "osaka", Population: 2750000},
+		{Name: "岡山", Kana: "おかやま", Romaji: "okayama", Population: 720000},
+		{Name: "福岡", Kana: "ふくおか", Romaji: "fukuoka", Population: 1610000},
+		{Name: "静岡", Kana: "しずおか", Romaji: "shizuoka", Population: 680000},
+		{Name: "東京", Kana: "とうきょう", Romaji: "tokyo", Population: 14050000},
+		{Name: "京都", Kana: "きょうと", Romaji: "kyoto", Population: 1460000},
+	})
+}
+
+func TestIndex_Search(t *testing.T) {
+	ix := testIndex()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// Prefix matches first, then by population
+		{"岡", []string{"岡山", "福岡", "静岡"}},
+		{"京", []string{"京都", "東京"}},
+		{"お", []string{"大阪", "岡山", "福岡", "静岡"}},
+		{"オカ", []string{"岡山", "福岡", "静岡"}},
+		{"ｵｶ", []string{"岡山", "福岡", "静岡"}},
+		{"kyo", []string{"京都", "東京"}},
+		{"toukyou", []string{"東京"}},
+		{"ＯＫＡ", []string{"岡山", "福岡", "静岡"}},
+		{"k", nil},
+		{"", nil},
+		{"札幌", nil},
+	}
+	for _, tt := range tests {
+		if got := names(ix.Search(tt.query, 0)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	if got := names(ix.Search("お", 2)); !reflect.DeepEqual(got, []string{"大阪", "岡山"}) {
+		t.Errorf("limit not applied: %v", got)
+	}
+}
+
+func TestMatch_Segments(t *testing.T) {
+	ix := testIndex()
+
+	tests := []struct {
+		query string
+		want  []Segment
+	}{
+		{"京", []Segment{{Text: "京", Matched: true}, {Text: "都"}}},
+		{"ｳｷｮ", []Segment{{Text: "と"}, {Text: "うきょ", Matched: true}, {Text: "う"}}},
+		{"yama", []Segment{{Text: "oka"}, {Text: "yama", Matched: true}}},
+		{"京都", []Segment{{Text: "京都", Matched: true}}},
+	}
+	for _, tt := range tests {
+		matches := ix.Search(tt.query, 1)
+		if len(matches) != 1 {
+			t.Fatalf("Search(%q) returned %d matches", tt.query, len(matches))
+		}
+		if got := matches[0].Segments(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Segments for %q = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMatch_SegmentsUnhighlightedWhenSpellingIsNotCanonical(t *testing.T) {
+	ix := NewIndex([]City{{Name: "東京", Kana: "とうきょう", Romaji: "toukyou"}})
+
+	matches := ix.Search("kyo", 1)
+	if len(matches) != 1 {
+		t.Fatalf("expected a match, got %v", matches)
+	}
+	if got := matches[0].Segments(); !reflect.DeepEqual(got, []Segment{{Text: "toukyou"}}) {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestLoadCities(t *testing.T) {
+	if _, err := LoadCities(strings.NewReader(`[{"kana": "なし"}]`)); err == nil {
+		t.Error("expected an error for a city without a name")
+	}
+	if _, err := LoadCities(strings.NewReader(`{`)); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+
+	cities := DefaultCities()
+	if len(cities) == 0 {
+		t.Fatal("no bundled cities")
+	}
+	for _, city := range cities {
+		if city.Kana == "" || RomajiKey(city.Romaji) != city.Romaji {
+			t.Errorf("bundled city %+v should have a reading and canonical romaji", city)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Package autocomplete ranks city names for the autocomplete demo. Names,
+// readings and romaji spellings are normalized and indexed in a trie of
+// their suffixes, so a query matches anywhere in a key and the match
+// position is known for ranking and highlighting.
+package autocomplete
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf8"
+)
+
+//go:embed cities.json
+var defaultCities []byte
+
+// City is an entry of the city data file
+type City struct {
+	Name       string `json:"name"`
+	Kana       string `json:"kana"`
+	Romaji     string `json:"romaji"`
+	Population int    `json:"population"`
+}
+
+// Field identifies which spelling of a city a query matched
+type Field int
+
+const (
+	FieldName Field = iota
+	FieldKana
+	FieldRomaji
+)
+
+// LoadCities decodes a JSON array of cities
+func LoadCities(r io.Reader) ([]City, error) {
+	var cities []City
+	if err := json.NewDecoder(r).Decode(&cities); err != nil {
+		return nil, fmt.Errorf("failed to decode city data: %w", err)
+	}
+	for i, city := range cities {
+		if city.Name == "" {
+			return nil, fmt.Errorf("city %d has no name", i)
+		}
+	}
+	return cities, nil
+}
+
+// DefaultCities returns the cities bundled with the demo
+func DefaultCities() []City {
+	cities, err := LoadCities(bytes.NewReader(defaultCities))
+	if err != nil {
+		panic(err)
+	}
+	return cities
+}
+
+// Match is a city matching a query. Start and End are rune offsets of the
+// matched part of Text.
+type Match struct {
+	City  City
+	Field Field
+	Start int
+	End   int
+}
+
+// Text returns the spelling of the city that matched
+func (m Match) Text() string {
+	switch m.Field {
+	case FieldKana:
+		return m.City.Kana
+	case FieldRomaji:
+		return m.City.Romaji
+	}
+	return m.City.Name
+}
+
+// Segment is a run of Text that is either part of the match or not
+type Segment struct {
+	Text    string
+	Matched bool
+}
+
+// Segments splits Text around the matched part for highlighting. Offsets
+// are taken from the normalized key; if normalizing changed the length of
+// Text, e.g. for a romaji spelling with macrons, nothing is highlighted.
+func (m Match) Segments() []Segment {
+	text := []rune(m.Text())
+	if utf8.RuneCountInString(key(m.City, m.Field)) != len(text) || m.End > len(text) || m.Start >= m.End {
+		return []Segment{{Text: string(text)}}
+	}
+
+	var segments []Segment
+	if m.Start > 0 {
+		segments = append(segments, Segment{Text: string(text[:m.Start])})
+	}
+	segments = append(segments, Segment{Text: string(text[m.Start:m.End]), Matched: true})
+	if m.End < len(text) {
+		segments = append(segments, Segment{Text: string(text[m.End:])})
+	}
+	return segments
+}
+
+// key returns the normalized form of a spelling of city
+func key(city City, field Field) string {
+	switch field {
+	case FieldKana:
+		return Normalize(city.Kana)
+	case FieldRomaji:
+		return RomajiKey(city.Romaji)
+	}
+	return Normalize(city.Name)
+}
+
+// Searchable reports whether query is long enough to search. Romaji needs
+// two letters, since a single letter matches nearly every city; a single
+// kana or kanji is specific enough.
+func Searchable(query string) bool {
+	normalized := Normalize(query)
+	if isRomaji(normalized) {
+		return len(normalized) >= 2
+	}
+	return normalized != ""
+}
+
+// posting records that a key contains the path to a trie node at start
+type posting struct {
+	city  int
+	field Field
+	start int
+}
+
+type node struct {
+	children map[rune]*node
+	postings []posting
+}
+
+func (n *node) child(r rune) *node {
+	c, exists := n.children[r]
+	if !exists {
+		c = &node{children: make(map[rune]*node)}
+		n.children[r] = c
+	}
+	return c
+}
+
+// Index is a read-only search index over a list of cities. It is safe for
+// concurrent use.
+type Index struct {
+	cities []City
+	kana   *node // names and readings
+	romaji *node
+}
+
+// NewIndex builds an index over cities
+func NewIndex(cities []City) *Index {
+	ix := &Index{
+		cities: append([]City(nil), cities...),
+		kana:   &node{children: make(map[rune]*node)},
+		romaji: &node{children: make(map[rune]*node)},
+	}
+	for i, city := range ix.cities {
+		ix.insert(ix.kana, i, FieldName, key(city, FieldName))
+		ix.insert(ix.kana, i, FieldKana, key(city, FieldKana))
+		ix.insert(ix.romaji, i, FieldRomaji, key(city, FieldRomaji))
+	}
+	return ix
+}
+
+// insert adds every suffix of key, recording the earliest start of each
+// substring once per city and field
+func (ix *Index) insert(root *node, city int, field Field, key string) {
+	runes := []rune(key)
+	for start := range runes {
+		n := root
+		for _, r := range runes[start:] {
+			n = n.child(r)
+			last := len(n.postings) - 1
+			if last >= 0 && n.postings[last].city == city && n.postings[last].field == field {
+				continue
+			}
+			n.postings = append(n.postings, posting{city: city, field: field, start: start})
+		}
+	}
+}
+
+// Search returns up to limit cities matching query, best first: matches
+// nearer the start of a spelling rank higher, then more populous cities.
+// Latin queries are matched as romaji, anything else against names and
+// readings.
+func (ix *Index) Search(query string, limit int) []Match {
+	if !Searchable(query) {
+		return nil
+	}
+
+	root, q := ix.kana, Normalize(query)
+	if isRomaji(q) {
+		root, q = ix.romaji, RomajiKey(q)
+	}
+
+	n := root
+	for _, r := range q {
+		if n = n.children[r]; n == nil {
+			return nil
+		}
+	}
+
+	// Keep the best match per city; postings are grouped by city and
+	// ordered by field, so a name match wins a tie with its reading
+	length := utf8.RuneCountInString(q)
+	best := make(map[int]Match)
+	for _, p := range n.postings {
+		if m, exists := best[p.city]; exists && m.Start <= p.start {
+			continue
+		}
+		best[p.city] = Match{City: ix.cities[p.city], Field: p.field, Start: p.start, End: p.start + length}
+	}
+
+	matches := make([]Match, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.City.Population != b.City.Population {
+			return a.City.Population > b.City.Population
+		}
+		return a.City.Name < b.City.Name
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
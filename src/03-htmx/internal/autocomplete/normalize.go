@@ -0,0 +1,125 @@
+package autocomplete
+
+import (
+	"strings"
+	"unicode"
+)
+
+// halfwidthKatakana maps U+FF66..U+FF9D to full-width katakana
+var halfwidthKatakana = []rune("ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン")
+
+// Normalize folds the differences users do not care about when searching:
+// full-width ASCII becomes ASCII, half-width katakana becomes full-width
+// (joining voiced sound marks), katakana becomes hiragana, letters are
+// lowercased and spaces are removed.
+func Normalize(s string) string {
+	runes := []rune(s)
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r >= '\uff01' && r <= '\uff5e':
+			r -= 0xFEE0
+		case r >= '\uff66' && r <= '\uff9d':
+			r = halfwidthKatakana[r-'\uff66']
+			if i+1 < len(runes) {
+				if voiced, ok := applySoundMark(r, runes[i+1]); ok {
+					r = voiced
+					i++
+				}
+			}
+		case isSoundMark(r) && len(out) > 0:
+			// A separate mark after full-width kana, as some IMEs send it
+			if voiced, ok := applySoundMark(toKatakana(out[len(out)-1]), r); ok {
+				out = out[:len(out)-1]
+				r = voiced
+			}
+		}
+
+		if r >= 'ァ' && r <= 'ヶ' {
+			r -= 0x60
+		}
+		out = append(out, unicode.ToLower(r))
+	}
+	return string(out)
+}
+
+func isSoundMark(r rune) bool {
+	switch r {
+	case '\uff9e', '\uff9f', '\u3099', '\u309a', '\u309b', '\u309c':
+		return true
+	}
+	return false
+}
+
+func toKatakana(r rune) rune {
+	if r >= 'ぁ' && r <= 'ゖ' {
+		return r + 0x60
+	}
+	return r
+}
+
+// applySoundMark combines katakana r with a (han)dakuten mark
+func applySoundMark(r, mark rune) (rune, bool) {
+	switch mark {
+	case '\uff9e', '\u3099', '\u309b':
+		switch {
+		case r == 'ウ':
+			return 'ヴ', true
+		case strings.ContainsRune("カキクケコサシスセソタチツテトハヒフヘホ", r):
+			return r + 1, true
+		}
+	case '\uff9f', '\u309a', '\u309c':
+		if strings.ContainsRune("ハヒフヘホ", r) {
+			return r + 2, true
+		}
+	}
+	return r, false
+}
+
+// kunreiReplacer rewrites Kunrei-shiki spellings to Hepburn
+var kunreiReplacer = strings.NewReplacer(
+	"sya", "sha", "syu", "shu", "syo", "sho",
+	"tya", "cha", "tyu", "chu", "tyo", "cho",
+	"zya", "ja", "zyu", "ju", "zyo", "jo",
+	"si", "shi", "ti", "chi", "tu", "tsu", "zi", "ji",
+)
+
+// longVowelReplacer folds the ways long vowels and ん are typed
+var longVowelReplacer = strings.NewReplacer("ou", "o", "oo", "o", "uu", "u", "nn", "n")
+
+// RomajiKey returns the canonical romaji spelling of s, so "toukyou",
+// "tokyo" and "tôkyô" typed as "tokyo" all compare equal. Characters other
+// than ASCII letters are dropped.
+func RomajiKey(s string) string {
+	var letters strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' {
+			letters.WriteRune(r)
+		}
+	}
+	key := kunreiReplacer.Replace(letters.String())
+
+	// hu is Kunrei for fu, but also the tail of Hepburn shu and chu
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] == 'h' && i+1 < len(key) && key[i+1] == 'u' && (i == 0 || (key[i-1] != 's' && key[i-1] != 'c')) {
+			b.WriteByte('f')
+			continue
+		}
+		b.WriteByte(key[i])
+	}
+	return longVowelReplacer.Replace(b.String())
+}
+
+// isRomaji reports whether a normalized query should be matched as romaji
+func isRomaji(s string) bool {
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,194 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const body = "0123456789abcdefghij"
+
+// newServer routes /items/{id} and /other through an injector
+func newServer(t *testing.T, cfg Config) (*Injector, *httptest.Server) {
+	t.Helper()
+	injector := NewInjector(cfg)
+	router := mux.NewRouter()
+	router.Use(injector.Middleware)
+	handler := func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, body) }
+	router.HandleFunc("/items/{id}", handler)
+	router.HandleFunc("/other", handler)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return injector, server
+}
+
+func get(t *testing.T, url string) (*http.Response, string, error) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return resp, string(data), err
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig(strings.NewReader(`{"enabled": true, "rules": [
+		{"route": "/api/*", "method": "GET", "latency": {"distribution": "uniform", "mean": "1s", "spread": "250ms"}, "errorRate": 0.5, "errorStatus": 502}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule := cfg.Rules[0]
+	if time.Duration(rule.Latency.Mean) != time.Second || time.Duration(rule.Latency.Spread) != 250*time.Millisecond {
+		t.Errorf("latency = %+v", rule.Latency)
+	}
+	if rule.ErrorRate != 0.5 || rule.ErrorStatus != 502 {
+		t.Errorf("rule = %+v", rule)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	tests := []struct{ name, data string }{
+		{"no route", `{"rules": [{}]}`},
+		{"bad pattern", `{"rules": [{"route": "/api/["}]}`},
+		{"unknown distribution", `{"rules": [{"route": "/a", "latency": {"distribution": "poisson"}}]}`},
+		{"negative latency", `{"rules": [{"route": "/a", "latency": {"mean": "-1s"}}]}`},
+		{"numeric duration", `{"rules": [{"route": "/a", "latency": {"mean": 500}}]}`},
+		{"rate above one", `{"rules": [{"route": "/a", "dropRate": 1.5}]}`},
+		{"success status", `{"rules": [{"route": "/a", "errorRate": 1, "errorStatus": 200}]}`},
+		{"unknown field", `{"rules": [{"route": "/a", "delay": "1s"}]}`},
+	}
+	for _, tt := range tests {
+		if _, err := LoadConfig(strings.NewReader(tt.data)); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected ErrInvalidConfig, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestRule_Matches(t *testing.T) {
+	tests := []struct {
+		rule   Rule
+		route  string
+		target string
+		want   bool
+	}{
+		{Rule{Route: "/items/{id}"}, "/items/{id}", "/items/1", true},
+		{Rule{Route: "/items/*"}, "/items/{id}", "/items/1", true},
+		{Rule{Route: "/items/*"}, "/other", "/other", false},
+		{Rule{Route: "/other", Method: "post"}, "/other", "/other", false},
+		{Rule{Route: "/other", Query: map[string]string{"type": "heavy"}}, "/other", "/other?type=heavy", true},
+		{Rule{Route: "/other", Query: map[string]string{"type": "heavy"}}, "/other", "/other?type=image", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.target, nil)
+		if got := tt.rule.matches(tt.route, req); got != tt.want {
+			t.Errorf("%+v matches %s %s = %v", tt.rule, tt.route, tt.target, got)
+		}
+	}
+}
+
+func TestInjector_Delay(t *testing.T) {
+	injector := NewInjector(Config{})
+	mean, spread := 100*time.Millisecond, 20*time.Millisecond
+
+	if d := injector.delay(Latency{Mean: Duration(mean)}); d != mean {
+		t.Errorf("fixed delay = %v", d)
+	}
+	for range 1000 {
+		d := injector.delay(Latency{Distribution: Uniform, Mean: Duration(mean), Spread: Duration(spread)})
+		if d < mean-spread || d > mean+spread {
+			t.Fatalf("uniform delay %v outside %v ± %v", d, mean, spread)
+		}
+		if d := injector.delay(Latency{Distribution: Normal, Mean: Duration(time.Millisecond), Spread: Duration(time.Second)}); d < 0 {
+			t.Fatalf("negative delay %v", d)
+		}
+	}
+}
+
+func TestMiddleware_LatencyPerRoute(t *testing.T) {
+	_, server := newServer(t, Config{Enabled: true, Rules: []Rule{
+		{Route: "/items/{id}", Latency: Latency{Mean: Duration(100 * time.Millisecond)}},
+	}})
+
+	start := time.Now()
+	if _, got, err := get(t, server.URL+"/items/1"); err != nil || got != body {
+		t.Fatalf("body = %q, %v", got, err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("matched route answered after %v", elapsed)
+	}
+
+	start = time.Now()
+	get(t, server.URL+"/other")
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("unmatched route delayed by %v", elapsed)
+	}
+}
+
+func TestMiddleware_Faults(t *testing.T) {
+	injector, server := newServer(t, Config{Enabled: true, Rules: []Rule{{Route: "/other", ErrorRate: 1, ErrorStatus: http.StatusBadGateway}}})
+
+	resp, _, err := get(t, server.URL+"/other")
+	if err != nil || resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("error injection: %v, %v", resp, err)
+	}
+
+	injector.SetConfig(Config{Enabled: true, Rules: []Rule{{Route: "/other", TruncateRate: 1}}})
+	_, got, err := get(t, server.URL+"/other")
+	if err == nil || got != body[:len(body)/2] {
+		t.Errorf("truncation: body %q, err %v", got, err)
+	}
+
+	injector.SetConfig(Config{Enabled: true, Rules: []Rule{{Route: "/other", DropRate: 1}}})
+	if _, _, err := get(t, server.URL+"/other"); err == nil {
+		t.Error("dropped connection answered")
+	}
+
+	injector.SetEnabled(false)
+	if _, got, err := get(t, server.URL+"/other"); err != nil || got != body {
+		t.Errorf("disabled injector: body %q, err %v", got, err)
+	}
+}
+
+func TestMiddleware_CanceledRequestStopsWaiting(t *testing.T) {
+	injector := NewInjector(Config{Enabled: true, Rules: []Rule{{Route: "/slow", Latency: Latency{Mean: Duration(time.Hour)}}}})
+	called := false
+	handler := injector.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil).WithContext(ctx))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("middleware kept waiting after the request was canceled")
+	}
+	if called {
+		t.Error("handler ran for a canceled request")
+	}
+}
+
+func TestInjector_SetConfigRefusesInvalid(t *testing.T) {
+	injector := NewInjector(Config{Enabled: true})
+	if err := injector.SetConfig(Config{Rules: []Rule{{Route: "/a", ErrorRate: 2}}}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+	if !injector.Config().Enabled {
+		t.Error("refused configuration was applied")
+	}
+}
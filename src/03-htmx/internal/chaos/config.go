@@ -0,0 +1,162 @@
+// Package chaos injects latency and faults into HTTP responses per route,
+// so loading indicators, retries and error handling can be seen and tested
+// without a slow or broken network. Rules can be changed while the server
+// runs.
+package chaos
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrInvalidConfig is returned for a configuration that cannot be applied
+var ErrInvalidConfig = errors.New("invalid chaos configuration")
+
+// Duration is a time.Duration written as a string like "500ms" in JSON
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"500ms\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Distribution is how a latency is drawn
+type Distribution string
+
+const (
+	// Fixed always waits Mean
+	Fixed Distribution = "fixed"
+	// Uniform waits between Mean-Spread and Mean+Spread
+	Uniform Distribution = "uniform"
+	// Normal waits a normally distributed time with Spread as the standard
+	// deviation
+	Normal Distribution = "normal"
+)
+
+// Latency is the delay added before a response. Delays below zero are
+// treated as zero.
+type Latency struct {
+	Distribution Distribution `json:"distribution,omitempty"`
+	Mean         Duration     `json:"mean,omitempty"`
+	Spread       Duration     `json:"spread,omitempty"`
+}
+
+// Rule applies latency and faults to the requests it matches. Rates are
+// probabilities from 0 to 1 and are drawn independently per request.
+type Rule struct {
+	// Route is a route's path template, like /api/uploads/{id}, or a
+	// path.Match pattern over templates, like /api/*
+	Route string `json:"route"`
+	// Method limits the rule to one method; empty matches any
+	Method string `json:"method,omitempty"`
+	// Query limits the rule to requests with these query values
+	Query map[string]string `json:"query,omitempty"`
+
+	Latency Latency `json:"latency"`
+
+	// ErrorRate answers with ErrorStatus, 503 if unset, instead of
+	// calling the handler
+	ErrorRate   float64 `json:"errorRate,omitempty"`
+	ErrorStatus int     `json:"errorStatus,omitempty"`
+	// TruncateRate sends only the first half of the body and then closes
+	// the connection
+	TruncateRate float64 `json:"truncateRate,omitempty"`
+	// DropRate closes the connection without any response
+	DropRate float64 `json:"dropRate,omitempty"`
+}
+
+// matches reports whether the rule applies to a request for route
+func (r Rule) matches(route string, req *http.Request) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, req.Method) {
+		return false
+	}
+	if r.Route != route {
+		if ok, _ := path.Match(r.Route, route); !ok {
+			return false
+		}
+	}
+	query := req.URL.Query()
+	for key, value := range r.Query {
+		if query.Get(key) != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Rule) validate() error {
+	if r.Route == "" {
+		return fmt.Errorf("%w: rule without a route", ErrInvalidConfig)
+	}
+	if _, err := path.Match(r.Route, ""); err != nil {
+		return fmt.Errorf("%w: route %q: %v", ErrInvalidConfig, r.Route, err)
+	}
+	switch r.Latency.Distribution {
+	case "", Fixed, Uniform, Normal:
+	default:
+		return fmt.Errorf("%w: route %q: unknown distribution %q", ErrInvalidConfig, r.Route, r.Latency.Distribution)
+	}
+	if r.Latency.Mean < 0 || r.Latency.Spread < 0 {
+		return fmt.Errorf("%w: route %q: negative latency", ErrInvalidConfig, r.Route)
+	}
+	for name, rate := range map[string]float64{"errorRate": r.ErrorRate, "truncateRate": r.TruncateRate, "dropRate": r.DropRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%w: route %q: %s must be between 0 and 1", ErrInvalidConfig, r.Route, name)
+		}
+	}
+	if r.ErrorStatus != 0 && (r.ErrorStatus < 400 || r.ErrorStatus > 599) {
+		return fmt.Errorf("%w: route %q: errorStatus must be 4xx or 5xx", ErrInvalidConfig, r.Route)
+	}
+	return nil
+}
+
+// Config is the complete chaos configuration. The first matching rule
+// applies; requests matching none pass through untouched.
+type Config struct {
+	Enabled bool   `json:"enabled"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Validate checks every rule
+func (c Config) Validate() error {
+	for _, rule := range c.Rules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadConfig decodes and validates a JSON configuration
+func LoadConfig(r io.Reader) (Config, error) {
+	var cfg Config
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
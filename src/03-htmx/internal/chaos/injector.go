@@ -0,0 +1,194 @@
+package chaos
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Injector applies a Config to requests passing through its middleware
+type Injector struct {
+	mutex  sync.RWMutex
+	config Config
+	// random draws latencies and faults; rand.Rand is not safe for
+	// concurrent use
+	randomMutex sync.Mutex
+	random      *rand.Rand
+}
+
+// NewInjector creates an injector. cfg must be valid.
+func NewInjector(cfg Config) *Injector {
+	return &Injector{config: cfg, random: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Config returns the current configuration
+func (i *Injector) Config() Config {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	cfg := i.config
+	cfg.Rules = append([]Rule(nil), cfg.Rules...)
+	return cfg
+}
+
+// SetConfig replaces the configuration; an invalid one is refused
+func (i *Injector) SetConfig(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.config = cfg
+	return nil
+}
+
+// SetEnabled switches injection on or off, keeping the rules
+func (i *Injector) SetEnabled(enabled bool) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.config.Enabled = enabled
+}
+
+// rule returns the rule for a request matched to route
+func (i *Injector) rule(route string, r *http.Request) (Rule, bool) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+	if !i.config.Enabled {
+		return Rule{}, false
+	}
+	for _, rule := range i.config.Rules {
+		if rule.matches(route, r) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// chance reports true with probability rate
+func (i *Injector) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	i.randomMutex.Lock()
+	defer i.randomMutex.Unlock()
+	return i.random.Float64() < rate
+}
+
+// delay draws a delay from latency
+func (i *Injector) delay(latency Latency) time.Duration {
+	mean, spread := time.Duration(latency.Mean), time.Duration(latency.Spread)
+
+	i.randomMutex.Lock()
+	var d time.Duration
+	switch latency.Distribution {
+	case Uniform:
+		d = mean - spread + time.Duration(i.random.Float64()*float64(2*spread))
+	case Normal:
+		d = mean + time.Duration(i.random.NormFloat64()*float64(spread))
+	default:
+		d = mean
+	}
+	i.randomMutex.Unlock()
+
+	return max(d, 0)
+}
+
+// Middleware applies the rule matching each request. It must run after
+// routing, e.g. through mux.Router.Use, so the route's path template is
+// known; without a route the request path is matched instead.
+func (i *Injector) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+		rule, ok := i.rule(route, r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if d := i.delay(rule.Latency); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				// The client gave up or a newer request replaced this one;
+				// nobody is waiting for a response
+				timer.Stop()
+				return
+			}
+		}
+
+		switch {
+		case i.chance(rule.DropRate):
+			drop(w)
+		case i.chance(rule.ErrorRate):
+			status := rule.ErrorStatus
+			if status == 0 {
+				status = http.StatusServiceUnavailable
+			}
+			http.Error(w, "chaos: injected "+http.StatusText(status), status)
+		case i.chance(rule.TruncateRate):
+			truncate(w, r, next)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// drop closes the connection without writing a response
+func drop(w http.ResponseWriter) {
+	if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+		conn.Close()
+		return
+	}
+	// Connections that cannot be hijacked, like HTTP/2 streams, are reset
+	// by aborting the handler
+	panic(http.ErrAbortHandler)
+}
+
+// bufferedResponse records a response so it can be cut short
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// truncate runs next, sends the first half of its body and then aborts
+// the connection, so the client sees a response that ends early. Streaming
+// responses are held until the handler returns.
+func truncate(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	recorded := &bufferedResponse{header: w.Header()}
+	next.ServeHTTP(recorded, r)
+
+	// The promised length must not match the bytes sent
+	w.Header().Del("Content-Length")
+	if recorded.status == 0 {
+		recorded.status = http.StatusOK
+	}
+	w.WriteHeader(recorded.status)
+	w.Write(recorded.body.Bytes()[:recorded.body.Len()/2])
+	http.NewResponseController(w).Flush()
+	panic(http.ErrAbortHandler)
+}
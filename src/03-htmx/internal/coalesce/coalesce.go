@@ -0,0 +1,147 @@
+// Package coalesce lets the newest request of an element win. When an
+// element asks again while its previous request is still running, the
+// previous request is canceled so its handler stops early.
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"htmx-demo/internal/session"
+	"net/http"
+	"shared/htmx"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// HeaderSequence numbers the coalesced responses. Numbers grow with every
+// request, so a client drops a response numbered below one it has already
+// swapped in.
+const HeaderSequence = "X-Request-Sequence"
+
+// ErrSuperseded is the cause of the context of a request canceled by a newer
+// request of the same element
+var ErrSuperseded = errors.New("coalesce: superseded by a newer request")
+
+// Group coalesces the requests to a set of routes. Requests are grouped by
+// session, route and the id of the triggering element; requests without a
+// session or from an element without an id pass through untouched.
+type Group struct {
+	routes map[string]bool
+
+	mu       sync.Mutex
+	sequence uint64
+	inflight map[string]*call
+}
+
+// call is the running request of a key
+type call struct {
+	sequence uint64
+	cancel   context.CancelCauseFunc
+}
+
+// NewGroup returns a group coalescing the routes with the given path
+// templates, e.g. "/api/search"
+func NewGroup(routes ...string) *Group {
+	g := &Group{routes: make(map[string]bool), inflight: make(map[string]*call)}
+	for _, route := range routes {
+		g.routes[route] = true
+	}
+	return g
+}
+
+// Middleware coalesces requests. It must run after the session middleware
+// and after routing, e.g. through mux.Router.Use, and before middleware that
+// should be canceled along with the handler.
+func (g *Group) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := g.key(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, sequence, done := g.begin(r.Context(), key)
+		defer done()
+		w.Header().Set(HeaderSequence, strconv.FormatUint(sequence, 10))
+
+		rw := &responseWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+		if !rw.wroteHeader && errors.Is(context.Cause(ctx), ErrSuperseded) {
+			// htmx leaves the page alone on 204, so the canceled request
+			// cannot blank out the results of the newer one
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+// key identifies the element a request comes from. It is false for requests
+// that are not coalesced.
+func (g *Group) key(r *http.Request) (string, bool) {
+	current := mux.CurrentRoute(r)
+	if current == nil {
+		return "", false
+	}
+	route, err := current.GetPathTemplate()
+	if err != nil || !g.routes[route] {
+		return "", false
+	}
+	id, element := session.IDFromContext(r.Context()), htmx.Trigger(r)
+	if id == "" || element == "" {
+		return "", false
+	}
+	return id + "\x00" + route + "\x00" + element, true
+}
+
+// begin registers a request under key and cancels the one it replaces. done
+// must be called when the request finishes.
+func (g *Group) begin(parent context.Context, key string) (ctx context.Context, sequence uint64, done func()) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	g.mu.Lock()
+	g.sequence++
+	c := &call{sequence: g.sequence, cancel: cancel}
+	if previous, ok := g.inflight[key]; ok {
+		previous.cancel(ErrSuperseded)
+	}
+	g.inflight[key] = c
+	g.mu.Unlock()
+
+	return ctx, c.sequence, func() {
+		g.mu.Lock()
+		if g.inflight[key] == c {
+			delete(g.inflight, key)
+		}
+		g.mu.Unlock()
+		cancel(context.Canceled)
+	}
+}
+
+// Inflight returns the number of requests running
+func (g *Group) Inflight() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.inflight)
+}
+
+// responseWriter records whether the handler started a response
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
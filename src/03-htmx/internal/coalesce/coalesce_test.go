@@ -0,0 +1,152 @@
+package coalesce
+
+import (
+	"context"
+	"errors"
+	"htmx-demo/internal/session"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// server holds /search requests until they are canceled or released
+type server struct {
+	group    *Group
+	router   *mux.Router
+	started  chan struct{}
+	release  chan struct{}
+	canceled chan error
+}
+
+func newServer() *server {
+	s := &server{
+		group:    NewGroup("/search"),
+		started:  make(chan struct{}, 10),
+		release:  make(chan struct{}),
+		canceled: make(chan error, 10),
+	}
+	s.router = mux.NewRouter()
+	s.router.Use(s.group.Middleware)
+	s.router.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
+		s.started <- struct{}{}
+		select {
+		case <-s.release:
+			io.WriteString(w, r.URL.Query().Get("q"))
+		case <-r.Context().Done():
+			s.canceled <- context.Cause(r.Context())
+		}
+	})
+	s.router.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "other")
+	})
+	return s
+}
+
+// request is made by element from the visitor session sessionID
+func request(sessionID, element, target string) *http.Request {
+	req := httptest.NewRequest("GET", target, nil)
+	if element != "" {
+		req.Header.Set("HX-Trigger", element)
+	}
+	return req.WithContext(session.WithID(req.Context(), sessionID))
+}
+
+// serve runs req in the background
+func (s *server) serve(req *http.Request) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		done <- rr
+	}()
+	<-s.started
+	return done
+}
+
+func sequence(t *testing.T, rr *httptest.ResponseRecorder) uint64 {
+	t.Helper()
+	n, err := strconv.ParseUint(rr.Header().Get(HeaderSequence), 10, 64)
+	if err != nil {
+		t.Fatalf("%s header: %v", HeaderSequence, err)
+	}
+	return n
+}
+
+func wait(t *testing.T, done <-chan *httptest.ResponseRecorder) *httptest.ResponseRecorder {
+	t.Helper()
+	select {
+	case rr := <-done:
+		return rr
+	case <-time.After(time.Second):
+		t.Fatal("request did not finish")
+		return nil
+	}
+}
+
+func TestMiddleware_NewerRequestCancelsOlder(t *testing.T) {
+	s := newServer()
+
+	older := s.serve(request("alice", "search-input", "/search?q=g"))
+	newer := s.serve(request("alice", "search-input", "/search?q=go"))
+
+	rr := wait(t, older)
+	if rr.Code != http.StatusNoContent || rr.Body.Len() != 0 {
+		t.Errorf("superseded request answered %d %q", rr.Code, rr.Body.String())
+	}
+	if cause := <-s.canceled; !errors.Is(cause, ErrSuperseded) {
+		t.Errorf("handler context canceled by %v", cause)
+	}
+
+	close(s.release)
+	latest := wait(t, newer)
+	if latest.Code != http.StatusOK || latest.Body.String() != "go" {
+		t.Errorf("latest request answered %d %q", latest.Code, latest.Body.String())
+	}
+	if sequence(t, latest) <= sequence(t, rr) {
+		t.Errorf("sequence %d of the newer request is not above %d", sequence(t, latest), sequence(t, rr))
+	}
+	if n := s.group.Inflight(); n != 0 {
+		t.Errorf("%d requests still in flight", n)
+	}
+}
+
+func TestMiddleware_KeysBySessionAndElement(t *testing.T) {
+	s := newServer()
+
+	first := s.serve(request("alice", "search-input", "/search?q=a"))
+	otherElement := s.serve(request("alice", "sidebar-search", "/search?q=b"))
+	otherSession := s.serve(request("bob", "search-input", "/search?q=c"))
+	if n := s.group.Inflight(); n != 3 {
+		t.Errorf("%d requests in flight, want 3", n)
+	}
+
+	close(s.release)
+	for want, done := range map[string]<-chan *httptest.ResponseRecorder{"a": first, "b": otherElement, "c": otherSession} {
+		if rr := wait(t, done); rr.Code != http.StatusOK || rr.Body.String() != want {
+			t.Errorf("request %s answered %d %q", want, rr.Code, rr.Body.String())
+		}
+	}
+}
+
+func TestMiddleware_PassesThroughOtherRequests(t *testing.T) {
+	s := newServer()
+	close(s.release)
+
+	requests := []*http.Request{
+		request("alice", "search-input", "/other"),
+		request("alice", "", "/search?q=a"),
+		request("", "search-input", "/search?q=a"),
+	}
+	for _, req := range requests {
+		rr := httptest.NewRecorder()
+		s.router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK || rr.Header().Get(HeaderSequence) != "" {
+			t.Errorf("%s (trigger %q) answered %d with sequence %q", req.URL, req.Header.Get("HX-Trigger"), rr.Code, rr.Header().Get(HeaderSequence))
+		}
+	}
+}
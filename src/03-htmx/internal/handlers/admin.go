@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"htmx-demo/internal/templates"
+	"net/http"
+	"shared/htmx"
+
+	"github.com/gorilla/mux"
+)
+
+// AdminAuth requires HTTP basic authentication with token as the password
+func AdminAuth(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, password, ok := r.BasicAuth()
+			if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// renderAdminError shows message in the error area target of an admin
+// page instead of the request's target, which keeps its content
+func renderAdminError(w http.ResponseWriter, r *http.Request, target string, status int, message string) {
+	htmx.Retarget(w, target)
+	htmx.Reswap(w, htmx.SwapInnerHTML)
+	render(w, r, status, templates.FormError("エラー", message))
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAuth(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := AdminAuth("s3cret")(next)
+
+	tests := []struct {
+		name     string
+		password string
+		auth     bool
+		status   int
+	}{
+		{"no credentials", "", false, http.StatusUnauthorized},
+		{"wrong password", "guess", true, http.StatusUnauthorized},
+		{"token", "s3cret", true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/admin/taxonomy", nil)
+		if tt.auth {
+			req.SetBasicAuth("admin", tt.password)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rr.Code, tt.status)
+		}
+		if tt.status == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("%s: no WWW-Authenticate challenge", tt.name)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const (
+	scriptPayload    = `<script>alert(1)</script>`
+	attributePayload = `"><img src=x onerror=alert(1)>`
+)
+
+// formRequest builds a form POST to target with the given fields
+func formRequest(target string, fields url.Values) *http.Request {
+	req := httptest.NewRequest("POST", target, strings.NewReader(fields.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+// htmxRequest is a GET request made by htmx, which gets the fragment alone
+func htmxRequest(target string) *http.Request {
+	req := httptest.NewRequest("GET", target, nil)
+	req.Header.Set("HX-Request", "true")
+	return req
+}
+
+// htmxFormRequest is a form POST made by htmx
+func htmxFormRequest(target string, fields url.Values) *http.Request {
+	req := formRequest(target, fields)
+	req.Header.Set("HX-Request", "true")
+	return req
+}
+
+func TestFragments_EscapeUserInput(t *testing.T) {
+	h := newTestAPIHandler()
+	profiles := NewProfileEditor(h.sessions)
+	categories := newTestTaxonomyHandler(t)
+	notifications := newTestNotifier()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		req     *http.Request
+		want    string // how the payload must appear in the response
+	}{
+		{
+			name:    "Inline edit saved value",
+			handler: profiles.ServeHTTP,
+			req:     formRequest("/api/inline/profile/1/name", url.Values{"value": {scriptPayload}}),
+			want:    `&lt;script&gt;alert(1)&lt;/script&gt;`,
+		},
+		{
+			name:    "Inline edit rejected value",
+			handler: profiles.ServeHTTP,
+			req:     formRequest("/api/inline/profile/1/email", url.Values{"value": {attributePayload}}),
+			want:    `value="&#34;&gt;&lt;img src=x onerror=alert(1)&gt;"`,
+		},
+		{
+			name:    "FormSubmit",
+			handler: h.FormSubmit,
+			req:     htmxFormRequest("/api/form-submit", url.Values{"name": {scriptPayload}, "email": {attributePayload}, "message": {scriptPayload}}),
+			want:    `&#34;&gt;&lt;img src=x onerror=alert(1)&gt;`,
+		},
+		{
+			name:    "ValidateForm username",
+			handler: h.ValidateForm,
+			// Short enough to pass validation, so the username is echoed
+			req:  formRequest("/api/validate-form", url.Values{"username": {`"><img src=x>`}, "password": {"Passw0rd!"}, "password_confirm": {"Passw0rd!"}}),
+			want: `&#34;&gt;&lt;img src=x&gt;`,
+		},
+		{
+			name:    "DynamicForm",
+			handler: categories.DynamicForm,
+			req:     formRequest("/api/dynamic-form", url.Values{"path": {"business", "business/sales"}, "title": {attributePayload}}),
+			want:    `&#34;&gt;&lt;img src=x onerror=alert(1)&gt;`,
+		},
+		{
+			name:    "BulkAction items",
+			handler: notifications.Middleware(http.HandlerFunc(h.BulkAction)).ServeHTTP,
+			req:     htmxFormRequest("/api/bulk-action?action=delete-selected", url.Values{"items": {scriptPayload, attributePayload}}),
+			want:    `&lt;script&gt;alert(1)&lt;/script&gt;, &#34;&gt;&lt;img`,
+		},
+		{
+			name:    "Search",
+			handler: h.Search,
+			req:     htmxRequest("/api/search?search=" + url.QueryEscape(scriptPayload)),
+			want:    `&lt;script&gt;alert(1)&lt;/script&gt;`,
+		},
+		{
+			name:    "SelectCity",
+			handler: h.SelectCity,
+			req:     httptest.NewRequest("GET", "/api/select-city?city="+url.QueryEscape(attributePayload), nil),
+			want:    `&#34;&gt;&lt;img src=x onerror=alert(1)&gt;`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rr := httptest.NewRecorder()
+			tt.handler(rr, tt.req)
+
+			body := rr.Body.String()
+			if strings.Contains(body, "<script") || strings.Contains(body, "<img") {
+				t.Fatalf("user input rendered as markup: %s", body)
+			}
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("want %s in: %s", tt.want, body)
+			}
+			if got := rr.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+		})
+	}
+}
+
+func TestAutocomplete_LinksAreQueryEscaped(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rr := httptest.NewRecorder()
+	h.Autocomplete(rr, httptest.NewRequest("GET", "/api/autocomplete?city="+url.QueryEscape("大阪"), nil))
+
+	want := `hx-get="/api/select-city?city=` + url.QueryEscape("大阪") + `"`
+	if !strings.Contains(rr.Body.String(), want) {
+		t.Errorf("want %s in: %s", want, rr.Body.String())
+	}
+}
+
+func TestTaxonomyOptions_UnknownPathIsEmpty(t *testing.T) {
+	h := newTestTaxonomyHandler(t)
+
+	rr := httptest.NewRecorder()
+	h.Options(rr, httptest.NewRequest("GET", "/api/taxonomy/options?path="+url.QueryEscape(scriptPayload), nil))
+	if rr.Body.Len() != 0 {
+		t.Errorf("unknown path rendered: %s", rr.Body.String())
+	}
+}
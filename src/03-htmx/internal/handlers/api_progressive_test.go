@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"htmx-demo/internal/autocomplete"
+	"htmx-demo/internal/models"
+	"htmx-demo/internal/notify"
+	"htmx-demo/internal/session"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestAPIHandler() *APIHandler {
+	return NewAPIHandler(session.NewStore(time.Hour, models.NewDemoState), notify.NewBroker(notify.DefaultConfig()), autocomplete.NewIndex(autocomplete.DefaultCities()))
+}
+
+// requestAs returns a request made from the given visitor session
+func requestAs(sessionID, method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	return req.WithContext(session.WithID(req.Context(), sessionID))
+}
+
+func TestLiveCounter_PerSession(t *testing.T) {
+	h := newTestAPIHandler()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			h.LiveCounter(httptest.NewRecorder(), requestAs("alice", "GET", "/api/live-counter"))
+		}()
+		go func() {
+			defer wg.Done()
+			h.LiveCounter(httptest.NewRecorder(), requestAs("bob", "GET", "/api/live-counter"))
+		}()
+	}
+	wg.Wait()
+
+	rr := httptest.NewRecorder()
+	h.LiveCounter(rr, requestAs("carol", "GET", "/api/live-counter"))
+	if !strings.Contains(rr.Body.String(), ">1</div>") {
+		t.Errorf("new visitor should start at 1: %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.LiveCounter(rr, requestAs("alice", "GET", "/api/live-counter"))
+	if !strings.Contains(rr.Body.String(), ">21</div>") {
+		t.Errorf("alice's counter should be 21: %s", rr.Body.String())
+	}
+}
+
+func TestNotifications_PerSession(t *testing.T) {
+	h := newTestAPIHandler()
+	h.StartNotifications(httptest.NewRecorder(), requestAs("alice", "POST", "/api/start-notifications"))
+
+	if history := h.notifications.History("bob"); len(history) != 0 {
+		t.Errorf("bob sees alice's notifications: %+v", history)
+	}
+	if history := h.notifications.History("alice"); len(history) != 1 || history[0].Message != notify.StartedMessage {
+		t.Errorf("alice's notifications = %+v", history)
+	}
+	if h.notifications.Running("bob") {
+		t.Error("bob's notifications should not be running")
+	}
+}
+
+func TestAutocomplete_RanksAndHighlights(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rr := httptest.NewRecorder()
+	h.Autocomplete(rr, requestAs("alice", "GET", "/api/autocomplete?city=oosaka"))
+	body := rr.Body.String()
+	if !strings.Contains(body, `role="listbox"`) || !strings.Contains(body, `id="city-option-0"`) {
+		t.Errorf("suggestions should be a listbox of options: %s", body)
+	}
+	if !strings.Contains(body, `data-city="大阪"`) || !strings.Contains(body, "<mark") {
+		t.Errorf("romaji query should suggest 大阪 with the match marked: %s", body)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Autocomplete(rr, requestAs("alice", "GET", "/api/autocomplete?city="+url.QueryEscape("ｶﾜ")))
+	if !strings.Contains(rr.Body.String(), `data-city="川崎"`) {
+		t.Errorf("half-width katakana should match the reading: %s", rr.Body.String())
+	}
+}
+
+func TestAutocomplete_ShortRomajiIsEmpty(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rr := httptest.NewRecorder()
+	h.Autocomplete(rr, requestAs("alice", "GET", "/api/autocomplete?city=o"))
+	if rr.Body.Len() != 0 {
+		t.Errorf("a single romaji letter should not search: %s", rr.Body.String())
+	}
+}
+
+// saveOrder posts an order of task ids based on version as alice
+func saveOrder(h *APIHandler, version string, ids ...string) *httptest.ResponseRecorder {
+	req := formRequest("/api/save-order", url.Values{"version": {version}, "item": ids})
+	rr := httptest.NewRecorder()
+	h.SaveOrder(rr, req.WithContext(session.WithID(req.Context(), "alice")))
+	return rr
+}
+
+// taskPositions returns alice's tasks in list order as id to position key
+func taskPositions(h *APIHandler) (ids []string, positions map[string]string) {
+	positions = make(map[string]string)
+	h.sessions.With("alice", func(state *models.DemoState) {
+		for _, task := range state.SortedTasks() {
+			ids = append(ids, task.ID)
+			positions[task.ID] = task.Position
+		}
+	})
+	return ids, positions
+}
+
+func TestSaveOrder_MovingOneTaskChangesOneKey(t *testing.T) {
+	h := newTestAPIHandler()
+	_, before := taskPositions(h)
+
+	rr := saveOrder(h, "0", "4", "1", "2", "3")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+
+	ids, after := taskPositions(h)
+	if strings.Join(ids, ",") != "4,1,2,3" {
+		t.Errorf("saved order = %v", ids)
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		if before[id] != after[id] {
+			t.Errorf("task %s was re-keyed from %q to %q", id, before[id], after[id])
+		}
+	}
+
+	body := rr.Body.String()
+	if strings.Index(body, `data-id="4"`) > strings.Index(body, `data-id="1"`) {
+		t.Errorf("list is not re-rendered in saved order: %s", body)
+	}
+	if !strings.Contains(body, `name="version" value="1"`) || !strings.Contains(body, `hx-swap-oob="innerHTML:#order-result"`) {
+		t.Errorf("response should carry the new version and the result: %s", body)
+	}
+}
+
+func TestSaveOrder_RejectsStaleOrder(t *testing.T) {
+	h := newTestAPIHandler()
+	saveOrder(h, "0", "2", "1", "3", "4")
+
+	// A second tab still showing version 0
+	rr := saveOrder(h, "0", "1", "2", "4", "3")
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusConflict)
+	}
+	if ids, _ := taskPositions(h); strings.Join(ids, ",") != "2,1,3,4" {
+		t.Errorf("stale order was saved: %v", ids)
+	}
+	if !strings.Contains(rr.Body.String(), `name="version" value="1"`) {
+		t.Errorf("the saved list should be re-rendered: %s", rr.Body.String())
+	}
+}
+
+func TestSaveOrder_RejectsUnknownTasks(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rr := saveOrder(h, "0", "1", "2", "3", "99")
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rr.Code, http.StatusBadRequest)
+	}
+	if ids, _ := taskPositions(h); strings.Join(ids, ",") != "1,2,3,4" {
+		t.Errorf("order changed: %v", ids)
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"htmx-demo/internal/chaos"
+	"htmx-demo/internal/templates"
+	"net/http"
+	"shared/htmx"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// chaosErrorTarget shows refused changes on the chaos admin page
+const chaosErrorTarget = "#chaos-admin-error"
+
+// ChaosHandler serves the admin page controlling latency and fault
+// injection at runtime
+type ChaosHandler struct {
+	injector *chaos.Injector
+	defaults chaos.Config
+}
+
+// NewChaosHandler creates a new ChaosHandler. Resetting restores defaults.
+func NewChaosHandler(injector *chaos.Injector, defaults chaos.Config) *ChaosHandler {
+	return &ChaosHandler{injector: injector, defaults: defaults}
+}
+
+// Admin serves the chaos admin page
+func (h *ChaosHandler) Admin(w http.ResponseWriter, r *http.Request) {
+	cfg, rules := h.config()
+	renderPage(w, r, "HTMX デモ - 遅延・障害の注入", templates.ChaosAdmin(cfg, rules))
+}
+
+// SetEnabled switches injection on or off from the "enabled" form value
+func (h *ChaosHandler) SetEnabled(w http.ResponseWriter, r *http.Request) {
+	h.injector.SetEnabled(r.FormValue("enabled") == "true")
+	h.renderPanel(w, r)
+}
+
+// SaveRules replaces the rules with the JSON list in the "rules" form value
+func (h *ChaosHandler) SaveRules(w http.ResponseWriter, r *http.Request) {
+	var rules []chaos.Rule
+	decoder := json.NewDecoder(strings.NewReader(r.FormValue("rules")))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		renderAdminError(w, r, chaosErrorTarget, http.StatusBadRequest, "ルールを JSON の配列として読み取れませんでした: "+err.Error())
+		return
+	}
+
+	cfg := h.injector.Config()
+	cfg.Rules = rules
+	if err := h.injector.SetConfig(cfg); err != nil {
+		renderAdminError(w, r, chaosErrorTarget, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.renderPanel(w, r)
+}
+
+// Reset restores the rules the server started with
+func (h *ChaosHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	if err := h.injector.SetConfig(h.defaults); err != nil {
+		renderAdminError(w, r, chaosErrorTarget, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.renderPanel(w, r)
+}
+
+// config returns the current configuration and its rules as editable JSON
+func (h *ChaosHandler) config() (chaos.Config, string) {
+	cfg := h.injector.Config()
+	rules, _ := json.MarshalIndent(cfg.Rules, "", "  ")
+	return cfg, string(rules)
+}
+
+// renderPanel renders the panel and clears an earlier error
+func (h *ChaosHandler) renderPanel(w http.ResponseWriter, r *http.Request) {
+	cfg, rules := h.config()
+	render(w, r, http.StatusOK, htmx.NewOOBWriter(templates.ChaosPanel(cfg, rules)).
+		Swap(htmx.SwapInnerHTML, chaosErrorTarget, templ.NopComponent))
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"htmx-demo/internal/chaos"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDemoLatency_IsValid(t *testing.T) {
+	if err := DemoLatency().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChaosAdmin_SaveRules(t *testing.T) {
+	injector := chaos.NewInjector(DemoLatency())
+	h := NewChaosHandler(injector, DemoLatency())
+
+	rr := httptest.NewRecorder()
+	h.SaveRules(rr, formRequest("/admin/chaos/rules", url.Values{"rules": {`[{"route": "/api/*", "errorRate": 0.25}]`}}))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if !strings.Contains(rr.Body.String(), "/api/*") || !strings.Contains(rr.Body.String(), "25%") {
+		t.Errorf("panel does not show the new rule: %s", rr.Body.String())
+	}
+	if rules := injector.Config().Rules; len(rules) != 1 || rules[0].ErrorRate != 0.25 {
+		t.Errorf("rules = %+v", rules)
+	}
+
+	rr = httptest.NewRecorder()
+	h.Reset(rr, httptest.NewRequest("POST", "/admin/chaos/reset", nil))
+	if got, want := len(injector.Config().Rules), len(DemoLatency().Rules); got != want {
+		t.Errorf("after reset %d rules, want %d", got, want)
+	}
+}
+
+func TestChaosAdmin_RefusesInvalidRules(t *testing.T) {
+	injector := chaos.NewInjector(DemoLatency())
+	h := NewChaosHandler(injector, DemoLatency())
+
+	for _, rules := range []string{`not json`, `[{"route": "/api/*", "dropRate": 3}]`} {
+		rr := httptest.NewRecorder()
+		h.SaveRules(rr, formRequest("/admin/chaos/rules", url.Values{"rules": {rules}}))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d", rules, rr.Code)
+		}
+		if got := rr.Header().Get("HX-Retarget"); got != chaosErrorTarget {
+			t.Errorf("%s: HX-Retarget = %q", rules, got)
+		}
+	}
+	if got, want := len(injector.Config().Rules), len(DemoLatency().Rules); got != want {
+		t.Errorf("refused rules were applied: %d rules", got)
+	}
+}
+
+func TestChaosAdmin_SetEnabled(t *testing.T) {
+	injector := chaos.NewInjector(DemoLatency())
+	h := NewChaosHandler(injector, DemoLatency())
+
+	rr := httptest.NewRecorder()
+	h.SetEnabled(rr, formRequest("/admin/chaos/enabled", url.Values{"enabled": {"false"}}))
+	if injector.Config().Enabled {
+		t.Error("injection still enabled")
+	}
+	if !strings.Contains(rr.Body.String(), "有効にする") {
+		t.Errorf("panel does not offer enabling: %s", rr.Body.String())
+	}
+}
+
+func TestChaos_SparesAdminPages(t *testing.T) {
+	services := newTestServices(t)
+	services.Chaos = chaos.NewInjector(chaos.Config{Enabled: true})
+	router := NewRouter(services)
+	serve := func(method, target string, fields url.Values) int {
+		req := formRequest(target, fields)
+		req.Method = method
+		req.SetBasicAuth("admin", testAdminToken)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	rules := `[{"route": "/admin/*", "errorRate": 1}, {"route": "/admin/chaos/*", "dropRate": 1}, {"route": "/api/time", "errorRate": 1}]`
+	if status := serve("PUT", "/admin/chaos/rules", url.Values{"rules": {rules}}); status != http.StatusOK {
+		t.Fatalf("saving rules: status %d", status)
+	}
+	if status := serve("GET", "/api/time", nil); status != http.StatusServiceUnavailable {
+		t.Errorf("demo route: status %d, want injected 503", status)
+	}
+	if status := serve("GET", "/admin/chaos", nil); status != http.StatusOK {
+		t.Errorf("admin page: status %d", status)
+	}
+	if status := serve("POST", "/admin/chaos/reset", nil); status != http.StatusOK {
+		t.Errorf("reset: status %d", status)
+	}
+	if rules := services.Chaos.Config().Rules; len(rules) != len(DemoLatency().Rules) {
+		t.Errorf("rules after reset = %+v", rules)
+	}
+}
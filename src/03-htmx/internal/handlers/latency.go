@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"htmx-demo/internal/chaos"
+	"time"
+)
+
+// fixed is a latency of exactly d
+func fixed(d time.Duration) chaos.Latency {
+	return chaos.Latency{Distribution: chaos.Fixed, Mean: chaos.Duration(d)}
+}
+
+// DemoLatency is the chaos configuration giving each demo the delay its
+// loading indicator is meant to show. The handlers themselves answer
+// immediately.
+func DemoLatency() chaos.Config {
+	return chaos.Config{
+		Enabled: true,
+		Rules: []chaos.Rule{
+			// Basic requests
+			{Route: "/api/users", Method: "GET", Latency: fixed(500 * time.Millisecond)},
+			{Route: "/api/users", Method: "POST", Latency: fixed(300 * time.Millisecond)},
+			{Route: "/api/echo", Latency: fixed(200 * time.Millisecond)},
+			{Route: "/api/search", Latency: fixed(200 * time.Millisecond)},
+			{Route: "/api/focus-data", Latency: fixed(100 * time.Millisecond)},
+			{Route: "/api/special-action", Latency: fixed(150 * time.Millisecond)},
+			{Route: "/api/custom-response", Latency: fixed(100 * time.Millisecond)},
+
+			// Targets
+			{Route: "/api/target-content", Latency: fixed(200 * time.Millisecond)},
+			{Route: "/api/multi-target", Latency: fixed(150 * time.Millisecond)},
+			{Route: "/api/selector-target", Latency: fixed(100 * time.Millisecond)},
+			{Route: "/api/relative-target", Latency: fixed(200 * time.Millisecond)},
+			{Route: "/api/swap-demo", Latency: fixed(100 * time.Millisecond)},
+
+			// Indicators; /api/slow-response waits for its own delay parameter
+			{Route: "/api/progress-response", Latency: fixed(2 * time.Second)},
+			{Route: "/api/skeleton-response", Latency: fixed(1500 * time.Millisecond)},
+
+			// Forms; validation looks the username up in a user directory
+			{Route: "/api/form-submit", Latency: fixed(500 * time.Millisecond)},
+			{Route: "/api/validate/{field}", Latency: fixed(200 * time.Millisecond)},
+			{Route: "/api/validate-form", Latency: fixed(200 * time.Millisecond)},
+
+			// Progressive enhancement
+			{Route: "/api/load-more", Latency: fixed(800 * time.Millisecond)},
+			{Route: "/api/progressive-load", Query: map[string]string{"step": "1"}, Latency: fixed(500 * time.Millisecond)},
+			{Route: "/api/progressive-load", Query: map[string]string{"step": "2"}, Latency: fixed(800 * time.Millisecond)},
+			{Route: "/api/progressive-load", Query: map[string]string{"step": "3"}, Latency: fixed(1000 * time.Millisecond)},
+			{Route: "/api/lazy-content", Query: map[string]string{"type": "image"}, Latency: fixed(800 * time.Millisecond)},
+			{Route: "/api/lazy-content", Query: map[string]string{"type": "chart"}, Latency: fixed(1200 * time.Millisecond)},
+			{Route: "/api/lazy-content", Query: map[string]string{"type": "heavy"}, Latency: fixed(2000 * time.Millisecond)},
+			{Route: "/api/autocomplete", Latency: fixed(200 * time.Millisecond)},
+			{Route: "/api/save-order", Latency: fixed(300 * time.Millisecond)},
+		},
+	}
+}
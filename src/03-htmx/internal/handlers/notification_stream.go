@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"htmx-demo/internal/models"
+	"htmx-demo/internal/session"
+	"htmx-demo/internal/templates"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NotificationEvent is the SSE event name the page swaps with sse-swap
+const NotificationEvent = "notification"
+
+// sseRetry is the reconnection delay suggested to EventSource clients
+const sseRetry = 3 * time.Second
+
+// sseHeartbeatInterval keeps idle streams open through proxies
+const sseHeartbeatInterval = 15 * time.Second
+
+// NotificationStream pushes the visitor's notifications as server-sent
+// events. Each event carries a rendered NotificationEntry fragment and its
+// id, so a reconnecting EventSource resumes after Last-Event-ID.
+func (h *APIHandler) NotificationStream(w http.ResponseWriter, r *http.Request) {
+	// The controller flushes through middleware wrapping the writer
+	rc := http.NewResponseController(w)
+
+	lastID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	sub, replay := h.notifications.Subscribe(session.IDFromContext(r.Context()), lastID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	for _, n := range replay {
+		if err := writeNotificationEvent(r.Context(), w, n); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		// The writer cannot stream, e.g. a recorder in a test
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case n, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and
+				// replays from the last event it received
+				return
+			}
+			if err := writeNotificationEvent(r.Context(), w, n); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeNotificationEvent writes n as one SSE event, splitting the rendered
+// fragment into data lines
+func writeNotificationEvent(ctx context.Context, w io.Writer, n models.Notification) error {
+	var fragment bytes.Buffer
+	if err := templates.NotificationEntry(n).Render(ctx, &fragment); err != nil {
+		return err
+	}
+
+	var event strings.Builder
+	fmt.Fprintf(&event, "id: %d\nevent: %s\n", n.ID, NotificationEvent)
+	for _, line := range strings.Split(fragment.String(), "\n") {
+		fmt.Fprintf(&event, "data: %s\n", line)
+	}
+	event.WriteString("\n")
+
+	_, err := io.WriteString(w, event.String())
+	return err
+}
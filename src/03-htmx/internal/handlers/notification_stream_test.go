@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"htmx-demo/internal/session"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readEvents reads SSE events from r until n events with an id were read
+func readEvents(t *testing.T, r *bufio.Reader, n int) []map[string]string {
+	t.Helper()
+	var events []map[string]string
+	event := map[string]string{}
+	for len(events) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v (events so far: %v)", err, events)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if event["id"] != "" {
+				events = append(events, event)
+			}
+			event = map[string]string{}
+			continue
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		event[field] += value
+	}
+	return events
+}
+
+func TestNotificationStream(t *testing.T) {
+	h := newTestAPIHandler()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.NotificationStream(w, r.WithContext(session.WithID(r.Context(), "alice")))
+	}))
+	defer server.Close()
+
+	h.notifications.Publish("alice", "one")
+	h.notifications.Publish("alice", "<script>alert(1)</script>")
+	h.notifications.Publish("bob", "not for alice")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", got)
+	}
+
+	body := bufio.NewReader(resp.Body)
+	replayed := readEvents(t, body, 1)[0]
+	if replayed["id"] != "2" || replayed["event"] != NotificationEvent {
+		t.Errorf("replay should resume after Last-Event-ID: %v", replayed)
+	}
+	if strings.Contains(replayed["data"], "<script") || !strings.Contains(replayed["data"], "&lt;script&gt;") {
+		t.Errorf("notification was not escaped: %s", replayed["data"])
+	}
+
+	// Live notifications follow the replay
+	h.StartNotifications(httptest.NewRecorder(), requestAs("alice", "POST", "/api/start-notifications"))
+	defer h.notifications.Stop("alice")
+	live := readEvents(t, body, 1)[0]
+	if live["id"] != "3" || !strings.Contains(live["data"], "開始しました") {
+		t.Errorf("live event = %v", live)
+	}
+}
@@ -1,11 +1,17 @@
 package handlers
 
 import (
-	"context"
 	"htmx-demo/internal/templates"
+	"log"
 	"net/http"
+	"shared/htmx"
+
+	"github.com/a-h/templ"
 )
 
+// contentTarget is the element of the layout holding the page content
+const contentTarget = "#content"
+
 // PageHandler handles page requests
 type PageHandler struct{}
 
@@ -14,58 +20,75 @@ func NewPageHandler() *PageHandler {
 	return &PageHandler{}
 }
 
+// renderPage renders content as a page of the site. Boosted navigation
+// receives the content alone and the URL is pushed into the history.
+func renderPage(w http.ResponseWriter, r *http.Request, title string, content templ.Component) {
+	writePage(w, r, htmx.Page{Title: title, Content: content, Layout: templates.Base, Target: contentTarget})
+}
+
+// renderFragmentPage renders a fragment that has a URL of its own. htmx
+// swaps the fragment in and pushes its URL; opened directly, from a link
+// or the history, it is shown in the site layout with a link back to the
+// demo page it belongs to.
+func renderFragmentPage(w http.ResponseWriter, r *http.Request, title, demoURL, demoName string, content templ.Component) {
+	writePage(w, r, fragmentPage(title, demoURL, demoName, content))
+}
+
+// renderSearchPage renders search results like renderFragmentPage, but
+// replaces the URL in the history instead of pushing it, so searching as
+// the visitor types leaves one entry rather than one per keystroke
+func renderSearchPage(w http.ResponseWriter, r *http.Request, title string, content templ.Component) {
+	page := fragmentPage(title, "/triggers", "トリガー制御", content)
+	page.Replace = true
+	writePage(w, r, page)
+}
+
+// fragmentPage shows content in the fragment layout linking to the demo page
+func fragmentPage(title, demoURL, demoName string, content templ.Component) htmx.Page {
+	layout := func(title string) templ.Component {
+		return templates.FragmentLayout(title, demoURL, demoName)
+	}
+	return htmx.Page{Title: title, Content: content, Layout: layout, Target: contentTarget}
+}
+
+// writePage renders page, logging failures
+func writePage(w http.ResponseWriter, r *http.Request, page htmx.Page) {
+	if err := htmx.RenderPage(w, r, http.StatusOK, page); err != nil {
+		log.Printf("Failed to render %s: %v", r.URL.Path, err)
+	}
+}
+
 // Home serves the home page
 func (h *PageHandler) Home(w http.ResponseWriter, r *http.Request) {
-	err := templates.Home().Render(context.Background(), w)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	renderPage(w, r, "HTMX デモ - ホーム", templates.Home())
 }
 
 // BasicRequests serves the basic requests demo page
 func (h *PageHandler) BasicRequests(w http.ResponseWriter, r *http.Request) {
-	err := templates.BasicRequests().Render(context.Background(), w)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	renderPage(w, r, "基本リクエスト - HTMX デモ", templates.BasicRequests())
 }
 
 // Triggers serves the triggers demo page
 func (h *PageHandler) Triggers(w http.ResponseWriter, r *http.Request) {
-	err := templates.Triggers().Render(context.Background(), w)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	renderPage(w, r, "トリガー制御 - HTMX デモ", templates.Triggers())
 }
 
 // Targets serves the targets demo page
 func (h *PageHandler) Targets(w http.ResponseWriter, r *http.Request) {
-	err := templates.Targets().Render(context.Background(), w)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	renderPage(w, r, "HTMX デモ - ターゲット指定", templates.Targets())
 }
 
 // Indicators serves the indicators demo page
 func (h *PageHandler) Indicators(w http.ResponseWriter, r *http.Request) {
-	err := templates.Indicators().Render(context.Background(), w)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	renderPage(w, r, "HTMX デモ - 読み込み表示", templates.Indicators())
 }
 
 // Forms serves the forms demo page
 func (h *PageHandler) Forms(w http.ResponseWriter, r *http.Request) {
-	err := templates.Forms().Render(context.Background(), w)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	renderPage(w, r, "HTMX デモ - フォーム処理", templates.Forms(registrationForm.Fields()))
 }
 
 // Progressive serves the progressive demo page
 func (h *PageHandler) Progressive(w http.ResponseWriter, r *http.Request) {
-	err := templates.Progressive().Render(context.Background(), w)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-}
\ No newline at end of file
+	renderPage(w, r, "HTMX デモ - プログレッシブエンハンスメント", templates.Progressive())
+}
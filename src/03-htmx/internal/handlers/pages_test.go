@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPages_DualMode(t *testing.T) {
+	router := newTestRouter(t)
+
+	tests := []struct {
+		name     string
+		target   string
+		headers  map[string]string
+		document bool
+		contains string
+		push     string
+		replace  string
+		retarget string
+	}{
+		{"page", "/triggers", nil, true, "トリガー制御デモ", "", "", ""},
+		{"boosted page", "/triggers", map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, false, "トリガー制御デモ", "/triggers", "", contentTarget},
+		{"history restore", "/triggers", map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"}, true, "トリガー制御デモ", "", "", ""},
+		{"fragment", "/api/target-content?type=info", map[string]string{"HX-Request": "true"}, false, "情報", "/api/target-content?type=info", "", ""},
+		{"deep-linked fragment", "/api/target-content?type=info", nil, true, `href="/targets"`, "", "", ""},
+		{"search", "/api/search?search=go", map[string]string{"HX-Request": "true"}, false, "Go言語", "", "/api/search?search=go", ""},
+		{"deep-linked search", "/api/search?search=go", nil, true, "Go言語", "", "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.target, nil)
+		for key, value := range tt.headers {
+			req.Header.Set(key, value)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		body := rr.Body.String()
+		if rr.Code != http.StatusOK {
+			t.Errorf("%s: status %d", tt.name, rr.Code)
+		}
+		if document := strings.HasPrefix(strings.ToLower(body), "<!doctype html"); document != tt.document {
+			t.Errorf("%s: complete document = %v", tt.name, document)
+		}
+		if !strings.Contains(body, tt.contains) {
+			t.Errorf("%s: %q missing from %s", tt.name, tt.contains, body)
+		}
+		if !tt.document && !strings.HasPrefix(body, "<title>") {
+			t.Errorf("%s: fragment without title: %s", tt.name, body)
+		}
+		if got := rr.Header().Get("HX-Push-Url"); got != tt.push {
+			t.Errorf("%s: HX-Push-Url = %q, want %q", tt.name, got, tt.push)
+		}
+		if got := rr.Header().Get("HX-Replace-Url"); got != tt.replace {
+			t.Errorf("%s: HX-Replace-Url = %q, want %q", tt.name, got, tt.replace)
+		}
+		if got := rr.Header().Get("HX-Retarget"); got != tt.retarget {
+			t.Errorf("%s: HX-Retarget = %q, want %q", tt.name, got, tt.retarget)
+		}
+	}
+}
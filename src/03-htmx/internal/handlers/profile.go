@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"htmx-demo/internal/inlineedit"
+	"htmx-demo/internal/models"
+	"htmx-demo/internal/session"
+	"htmx-demo/internal/templates"
+)
+
+// ProfileEditorPrefix is where the inline editor of profiles is served
+const ProfileEditorPrefix = "/api/inline/profile/"
+
+// profileStorage keeps profiles in the visitor's demo state
+type profileStorage struct {
+	sessions *session.Store[models.DemoState]
+}
+
+func (s profileStorage) Load(ctx context.Context, id string) (models.Profile, error) {
+	var profile models.Profile
+	var exists bool
+	s.sessions.With(session.IDFromContext(ctx), func(state *models.DemoState) {
+		profile, exists = state.Profiles[id]
+	})
+	if !exists {
+		return models.Profile{}, inlineedit.ErrNotFound
+	}
+	return profile, nil
+}
+
+func (s profileStorage) Update(ctx context.Context, id string, fn func(profile *models.Profile) error) error {
+	err := inlineedit.ErrNotFound
+	s.sessions.With(session.IDFromContext(ctx), func(state *models.DemoState) {
+		profile, exists := state.Profiles[id]
+		if !exists {
+			return
+		}
+		if err = fn(&profile); err == nil {
+			state.Profiles[id] = profile
+		}
+	})
+	return err
+}
+
+// NewProfileEditor creates the click-to-edit handler of the profiles on the
+// forms page
+func NewProfileEditor(sessions *session.Store[models.DemoState]) *inlineedit.Editor[models.Profile] {
+	renderer := inlineedit.Renderer{
+		Display: templates.InlineEditDisplay,
+		Form:    templates.InlineEditForm,
+	}
+	return inlineedit.New[models.Profile](ProfileEditorPrefix, profileStorage{sessions: sessions}, renderer).Register(
+		inlineedit.Field[models.Profile]{
+			Name:       "name",
+			Label:      "名前",
+			Get:        func(p models.Profile) string { return p.Name },
+			Set:        func(p *models.Profile, value string) { p.Name = value },
+			Validators: []inlineedit.Validator{inlineedit.Required(), inlineedit.MaxLength(30)},
+		},
+		inlineedit.Field[models.Profile]{
+			Name:       "job",
+			Label:      "職業",
+			Get:        func(p models.Profile) string { return p.Job },
+			Set:        func(p *models.Profile, value string) { p.Job = value },
+			Validators: []inlineedit.Validator{inlineedit.MaxLength(50)},
+		},
+		inlineedit.Field[models.Profile]{
+			Name:       "email",
+			Label:      "メールアドレス",
+			InputType:  "email",
+			Get:        func(p models.Profile) string { return p.Email },
+			Set:        func(p *models.Profile, value string) { p.Email = value },
+			Validators: []inlineedit.Validator{inlineedit.Required(), inlineedit.Email()},
+		},
+	)
+}
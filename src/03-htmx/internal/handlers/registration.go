@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"htmx-demo/internal/templates"
+	"htmx-demo/internal/validation"
+	"net/http"
+	"shared/htmx"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// takenUsernames are the accounts that already exist in the demo
+var takenUsernames = []string{"admin", "test", "user", "demo"}
+
+// usernameTaken simulates asking a user directory whether a username is
+// registered. DemoLatency gives the validation endpoints the moment such a
+// lookup takes.
+func usernameTaken(ctx context.Context, username string) (bool, error) {
+	for _, taken := range takenUsernames {
+		if strings.EqualFold(username, taken) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// registrationForm is the registration form of the forms page. Its rules
+// serve both the per-field feedback and the submission.
+var registrationForm = validation.New(
+	validation.Field{
+		Name:        "username",
+		Label:       validation.Text{validation.Japanese: "ユーザー名", validation.English: "Username"},
+		InputType:   "text",
+		Placeholder: validation.Text{validation.Japanese: "ユーザー名を入力", validation.English: "Enter a username"},
+		Rules: []validation.Rule{
+			validation.Required(),
+			validation.MinLength(3),
+			validation.MaxLength(20),
+			validation.Unique(usernameTaken),
+		},
+		Valid: validation.Text{validation.Japanese: "使用可能なユーザー名です", validation.English: "This username is available"},
+	},
+	validation.Field{
+		Name:        "password",
+		Label:       validation.Text{validation.Japanese: "パスワード", validation.English: "Password"},
+		InputType:   "password",
+		Placeholder: validation.Text{validation.Japanese: "パスワードを入力", validation.English: "Enter a password"},
+		Rules: []validation.Rule{
+			validation.Required(),
+			validation.MinLength(8),
+			validation.ContainsDigit(),
+			validation.ContainsUpper(),
+		},
+		Valid: validation.Text{validation.Japanese: "強力なパスワードです", validation.English: "Strong password"},
+	},
+	validation.Field{
+		Name:        "password_confirm",
+		Label:       validation.Text{validation.Japanese: "パスワード確認", validation.English: "Password confirmation"},
+		InputType:   "password",
+		Placeholder: validation.Text{validation.Japanese: "パスワードを再入力", validation.English: "Enter the password again"},
+		Rules: []validation.Rule{
+			validation.Required(),
+			validation.Matches("password"),
+		},
+		Valid: validation.Text{validation.Japanese: "パスワードが一致しています", validation.English: "Passwords match"},
+	},
+)
+
+// ValidateField validates one field of the registration form as it is
+// typed. Fields depending on it, like the confirmation of the password,
+// are re-validated out of band once they have a value.
+func (h *APIHandler) ValidateField(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	lang := validation.LangFromRequest(r)
+	name := mux.Vars(r)["field"]
+
+	result, err := registrationForm.ValidateField(r.Context(), name, r.PostForm)
+	if errors.Is(err, validation.ErrUnknownField) {
+		http.NotFound(w, r)
+		return
+	}
+	if result.Value == "" {
+		// Nothing typed yet; clear any earlier feedback
+		result = validation.FieldResult{Field: result.Field}
+	}
+
+	response := htmx.NewOOBWriter(templates.FieldFeedback(result, lang))
+	for _, dependent := range registrationForm.Dependents(name) {
+		if r.PostForm.Get(dependent) == "" {
+			continue
+		}
+		dependentResult, _ := registrationForm.ValidateField(r.Context(), dependent, r.PostForm)
+		response.Swap(htmx.SwapInnerHTML, "#"+dependentResult.Field.FeedbackID(), templates.FieldFeedback(dependentResult, lang))
+	}
+	render(w, r, http.StatusOK, response)
+}
+
+// ValidateForm handles complete form validation. The summary goes to the
+// request's target and each field's feedback is updated out of band.
+func (h *APIHandler) ValidateForm(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	lang := validation.LangFromRequest(r)
+
+	result := registrationForm.Validate(r.Context(), r.PostForm)
+
+	var response *htmx.OOBWriter
+	if result.Valid() {
+		response = htmx.NewOOBWriter(templates.RegistrationComplete(r.PostForm.Get("username"), lang, time.Now()))
+	} else {
+		response = htmx.NewOOBWriter(templates.FormErrors(validation.Msg(validation.MsgInvalidForm).In(lang), result.Errors(), lang))
+	}
+	for _, field := range result.Fields {
+		response.Swap(htmx.SwapInnerHTML, "#"+field.Field.FeedbackID(), templates.FieldFeedback(field, lang))
+	}
+	render(w, r, http.StatusOK, response)
+}
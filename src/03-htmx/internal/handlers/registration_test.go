@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// validateField posts values to the per-field endpoint of field
+func validateField(h *APIHandler, field string, values url.Values) *httptest.ResponseRecorder {
+	req := mux.SetURLVars(formRequest("/api/validate/"+field, values), map[string]string{"field": field})
+	rr := httptest.NewRecorder()
+	h.ValidateField(rr, req)
+	return rr
+}
+
+func TestValidateField_Feedback(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rr := validateField(h, "username", url.Values{"username": {"admin"}})
+	if !strings.Contains(rr.Body.String(), "このユーザー名は既に使用されています") {
+		t.Errorf("taken username not reported: %s", rr.Body.String())
+	}
+
+	rr = validateField(h, "username", url.Values{"username": {"admin"}, "lang": {"en"}})
+	if !strings.Contains(rr.Body.String(), "Username is already taken") {
+		t.Errorf("English message not used: %s", rr.Body.String())
+	}
+
+	rr = validateField(h, "username", url.Values{"username": {""}})
+	if rr.Body.Len() != 0 {
+		t.Errorf("an empty field should clear its feedback: %s", rr.Body.String())
+	}
+
+	if rr = validateField(h, "email", url.Values{}); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown field: status = %d", rr.Code)
+	}
+}
+
+func TestValidateField_RevalidatesConfirmation(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rr := validateField(h, "password", url.Values{"password": {"Passw0rd"}, "password_confirm": {"Passw0rd!"}})
+	body := rr.Body.String()
+	if !strings.Contains(body, "強力なパスワードです") {
+		t.Errorf("password feedback missing: %s", body)
+	}
+	if !strings.Contains(body, `hx-swap-oob="innerHTML:#password-confirm-validation"`) || !strings.Contains(body, "パスワード確認が一致しません") {
+		t.Errorf("confirmation should be re-validated out of band: %s", body)
+	}
+
+	rr = validateField(h, "password", url.Values{"password": {"Passw0rd"}})
+	if strings.Contains(rr.Body.String(), "hx-swap-oob") {
+		t.Errorf("an empty confirmation should be left alone: %s", rr.Body.String())
+	}
+}
+
+func TestValidateForm_ReportsEveryField(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rr := httptest.NewRecorder()
+	h.ValidateForm(rr, formRequest("/api/validate-form", url.Values{"username": {"ab"}, "password": {"password"}, "lang": {"en"}}))
+	body := rr.Body.String()
+	for _, want := range []string{
+		"Validation failed",
+		"Username must be at least 3 characters",
+		"Password must contain a digit",
+		"Password confirmation is required",
+		`hx-swap-oob="innerHTML:#username-validation"`,
+		`hx-swap-oob="innerHTML:#password-confirm-validation"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("want %s in: %s", want, body)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	h.ValidateForm(rr, formRequest("/api/validate-form", url.Values{"username": {"alice"}, "password": {"Passw0rd"}, "password_confirm": {"Passw0rd"}}))
+	if !strings.Contains(rr.Body.String(), "ユーザー「alice」が正常に登録されました。") {
+		t.Errorf("registration not completed: %s", rr.Body.String())
+	}
+}
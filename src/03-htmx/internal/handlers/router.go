@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"htmx-demo/internal/autocomplete"
+	"htmx-demo/internal/chaos"
+	"htmx-demo/internal/coalesce"
+	"htmx-demo/internal/models"
+	"htmx-demo/internal/notify"
+	"htmx-demo/internal/session"
+	"htmx-demo/internal/taxonomy"
+	"htmx-demo/internal/toast"
+	"htmx-demo/internal/upload"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Services are the stores and services the demo is built on
+type Services struct {
+	SessionManager *session.Manager
+	Sessions       *session.Store[models.DemoState]
+	Notifier       *toast.Notifier
+	Notifications  *notify.Broker
+	Cities         *autocomplete.Index
+	Uploads        *upload.Store
+	UploadConfig   upload.Config
+	Taxonomy       *taxonomy.Store
+	// Chaos injects latency and faults; the admin page resets it to
+	// ChaosDefaults
+	Chaos         *chaos.Injector
+	ChaosDefaults chaos.Config
+	// AdminToken is the basic auth password of the admin pages
+	AdminToken string
+}
+
+// NewRouter builds the handlers of the demo on s and the router serving
+// them, with the middleware every route runs through
+func NewRouter(s Services) *mux.Router {
+	// A new keystroke in the search box cancels the search still running for
+	// the previous one. It runs before the chaos middleware so that injected
+	// latency is cut short as well.
+	searchRequests := coalesce.NewGroup("/api/search")
+
+	return Routes{
+		Pages:      NewPageHandler(),
+		API:        NewAPIHandler(s.Sessions, s.Notifications, s.Cities),
+		Uploads:    NewUploadHandler(s.Uploads, s.UploadConfig),
+		Profiles:   NewProfileEditor(s.Sessions),
+		Taxonomy:   NewTaxonomyHandler(s.Taxonomy),
+		Chaos:      NewChaosHandler(s.Chaos, s.ChaosDefaults),
+		Faults:     s.Chaos.Middleware,
+		AdminToken: s.AdminToken,
+	}.Router(s.SessionManager.Middleware, s.Notifier.Middleware, searchRequests.Middleware)
+}
+
+// Routes are the handlers of the demo server
+type Routes struct {
+	Pages    *PageHandler
+	API      *APIHandler
+	Uploads  *UploadHandler
+	Profiles http.Handler
+	Taxonomy *TaxonomyHandler
+	Chaos    *ChaosHandler
+	// Faults injects latency and faults into the pages and APIs of the
+	// demos, if set. The admin pages are left out, so that no rule can lock
+	// the admin out of the page that removes it.
+	Faults mux.MiddlewareFunc
+	// AdminToken is the basic auth password of the admin pages
+	AdminToken string
+}
+
+// Router registers every route of the demo. middleware runs for every
+// route, in the given order, and Faults after it for the demo routes.
+func (rt Routes) Router(middleware ...mux.MiddlewareFunc) *mux.Router {
+	router := mux.NewRouter()
+	for _, mw := range middleware {
+		router.Use(mw)
+	}
+
+	// The demos get a subrouter of their own for the fault injection
+	demo := router.NewRoute().Subrouter()
+	if rt.Faults != nil {
+		demo.Use(rt.Faults)
+	}
+
+	// Page routes
+	demo.HandleFunc("/", rt.Pages.Home).Methods("GET")
+	demo.HandleFunc("/basic-requests", rt.Pages.BasicRequests).Methods("GET")
+	demo.HandleFunc("/triggers", rt.Pages.Triggers).Methods("GET")
+	demo.HandleFunc("/targets", rt.Pages.Targets).Methods("GET")
+	demo.HandleFunc("/indicators", rt.Pages.Indicators).Methods("GET")
+	demo.HandleFunc("/forms", rt.Pages.Forms).Methods("GET")
+	demo.HandleFunc("/progressive", rt.Pages.Progressive).Methods("GET")
+
+	// API routes for HTMX
+	api := demo.PathPrefix("/api").Subrouter()
+
+	// Basic requests APIs
+	api.HandleFunc("/users", rt.API.GetUsers).Methods("GET")
+	api.HandleFunc("/users", rt.API.CreateUser).Methods("POST")
+	api.HandleFunc("/echo", rt.API.Echo).Methods("PUT", "DELETE")
+	api.HandleFunc("/search", rt.API.Search).Methods("GET", "POST")
+	api.HandleFunc("/time", rt.API.GetTime).Methods("GET")
+	api.HandleFunc("/focus-data", rt.API.FocusData).Methods("GET")
+	api.HandleFunc("/special-action", rt.API.SpecialAction).Methods("POST")
+	api.HandleFunc("/custom-response", rt.API.CustomResponse).Methods("GET")
+
+	// Target specification APIs
+	api.HandleFunc("/target-content", rt.API.TargetContent).Methods("GET")
+	api.HandleFunc("/multi-target", rt.API.MultiTarget).Methods("GET")
+	api.HandleFunc("/selector-target", rt.API.SelectorTarget).Methods("GET")
+	api.HandleFunc("/relative-target", rt.API.RelativeTarget).Methods("GET")
+	api.HandleFunc("/swap-demo", rt.API.SwapDemo).Methods("GET")
+
+	// Indicators APIs
+	api.HandleFunc("/slow-response", rt.API.SlowResponse).Methods("GET")
+	api.HandleFunc("/progress-response", rt.API.ProgressResponse).Methods("GET")
+	api.HandleFunc("/skeleton-response", rt.API.SkeletonResponse).Methods("GET")
+
+	// Forms APIs
+	api.HandleFunc("/form-submit", rt.API.FormSubmit).Methods("POST")
+	api.HandleFunc("/validate/{field}", rt.API.ValidateField).Methods("POST")
+	api.HandleFunc("/validate-form", rt.API.ValidateForm).Methods("POST")
+	api.HandleFunc("/taxonomy/options", rt.Taxonomy.Options).Methods("GET")
+	api.HandleFunc("/dynamic-form", rt.Taxonomy.DynamicForm).Methods("POST")
+	api.HandleFunc("/file-upload", rt.Uploads.FileUpload).Methods("POST")
+	api.HandleFunc("/uploads", rt.Uploads.ListUploads).Methods("GET")
+	api.HandleFunc("/uploads/{id}", rt.Uploads.DownloadUpload).Methods("GET")
+	api.HandleFunc("/uploads/{id}", rt.Uploads.DeleteUpload).Methods("DELETE")
+	demo.PathPrefix(ProfileEditorPrefix).Handler(rt.Profiles)
+	api.HandleFunc("/bulk-action", rt.API.BulkAction).Methods("POST")
+
+	// Progressive APIs
+	api.HandleFunc("/load-more", rt.API.LoadMore).Methods("GET")
+	api.HandleFunc("/live-counter", rt.API.LiveCounter).Methods("GET")
+	api.HandleFunc("/live-stats", rt.API.LiveStats).Methods("GET")
+	api.HandleFunc("/progressive-load", rt.API.ProgressiveLoad).Methods("GET")
+	api.HandleFunc("/lazy-content", rt.API.LazyContent).Methods("GET")
+	api.HandleFunc("/autocomplete", rt.API.Autocomplete).Methods("GET")
+	api.HandleFunc("/select-city", rt.API.SelectCity).Methods("GET")
+	api.HandleFunc("/tasks", rt.API.Tasks).Methods("GET")
+	api.HandleFunc("/save-order", rt.API.SaveOrder).Methods("POST")
+	api.HandleFunc("/start-notifications", rt.API.StartNotifications).Methods("POST")
+	api.HandleFunc("/stop-notifications", rt.API.StopNotifications).Methods("POST")
+	api.HandleFunc("/notifications/stream", rt.API.NotificationStream).Methods("GET")
+
+	// Admin routes
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(AdminAuth(rt.AdminToken))
+	admin.HandleFunc("/taxonomy", rt.Taxonomy.Admin).Methods("GET")
+	admin.HandleFunc("/taxonomy/nodes", rt.Taxonomy.AddNode).Methods("POST")
+	admin.HandleFunc("/taxonomy/nodes", rt.Taxonomy.RenameNode).Methods("PUT")
+	admin.HandleFunc("/taxonomy/nodes", rt.Taxonomy.RemoveNode).Methods("DELETE")
+	admin.HandleFunc("/taxonomy/reload", rt.Taxonomy.Reload).Methods("POST")
+	admin.HandleFunc("/chaos", rt.Chaos.Admin).Methods("GET")
+	admin.HandleFunc("/chaos/enabled", rt.Chaos.SetEnabled).Methods("POST")
+	admin.HandleFunc("/chaos/rules", rt.Chaos.SaveRules).Methods("PUT")
+	admin.HandleFunc("/chaos/reset", rt.Chaos.Reset).Methods("POST")
+
+	return router
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"htmx-demo/internal/autocomplete"
+	"htmx-demo/internal/chaos"
+	"htmx-demo/internal/hxcheck"
+	"htmx-demo/internal/models"
+	"htmx-demo/internal/notify"
+	"htmx-demo/internal/session"
+	"htmx-demo/internal/taxonomy"
+	"htmx-demo/internal/templates"
+	"htmx-demo/internal/toast"
+	"htmx-demo/internal/upload"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const testAdminToken = "test-token"
+
+// newTestRouter returns the demo router with in-memory state and uploads in
+// a temporary directory
+func newTestRouter(t *testing.T) *mux.Router {
+	t.Helper()
+	return NewRouter(newTestServices(t))
+}
+
+// newTestServices returns the services of newTestRouter
+func newTestServices(t *testing.T) Services {
+	t.Helper()
+	sessions, err := session.NewManager(session.NewSecret(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploadConfig := upload.Config{Dir: t.TempDir(), MaxBytes: upload.DefaultMaxBytes, AllowedTypes: upload.DefaultAllowedTypes}
+	uploads, err := upload.NewStore(uploadConfig.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	taxonomyStore, err := taxonomy.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Latency rules are left out so the tests run without delay
+	return Services{
+		SessionManager: sessions,
+		Sessions:       session.NewStore(time.Hour, models.NewDemoState),
+		Notifier:       newTestNotifier(),
+		Notifications:  notify.NewBroker(notify.DefaultConfig()),
+		Cities:         autocomplete.NewIndex(autocomplete.DefaultCities()),
+		Uploads:        uploads,
+		UploadConfig:   uploadConfig,
+		Taxonomy:       taxonomyStore,
+		Chaos:          chaos.NewInjector(chaos.Config{}),
+		ChaosDefaults:  DemoLatency(),
+		AdminToken:     testAdminToken,
+	}
+}
+
+// newTestNotifier returns a notifier keeping flash messages in memory
+func newTestNotifier() *toast.Notifier {
+	flash := session.NewStore(time.Hour, toast.NewFlash)
+	return toast.NewNotifier(flash, toast.Views{Toasts: templates.Toasts, Modal: templates.Modal})
+}
+
+func TestPages_HtmxAttributesMatchRoutes(t *testing.T) {
+	header := make(http.Header)
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:"+testAdminToken)))
+	checker := &hxcheck.Checker{Router: newTestRouter(t), Header: header}
+
+	pages, err := checker.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) < 9 {
+		t.Errorf("found only pages %v", pages)
+	}
+
+	issues, err := checker.Check(pages...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		t.Error(issue)
+	}
+}
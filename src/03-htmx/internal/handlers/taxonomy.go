@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"htmx-demo/internal/taxonomy"
+	"htmx-demo/internal/templates"
+	"log"
+	"net/http"
+	"shared/htmx"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// taxonomyErrorTarget shows refused edits on the taxonomy admin page
+const taxonomyErrorTarget = "#taxonomy-admin-error"
+
+// TaxonomyHandler serves the chained category selects of the dynamic form
+// and the admin pages editing their taxonomy
+type TaxonomyHandler struct {
+	store *taxonomy.Store
+}
+
+// NewTaxonomyHandler creates a new TaxonomyHandler
+func NewTaxonomyHandler(store *taxonomy.Store) *TaxonomyHandler {
+	return &TaxonomyHandler{store: store}
+}
+
+// Options returns the select for the level below path, the top level for
+// an empty path. A leaf or an unknown path renders nothing, which clears
+// the deeper levels.
+func (h *TaxonomyHandler) Options(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	children, err := h.store.Current().Children(path)
+	if err != nil || len(children) == 0 {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		return
+	}
+
+	render(w, r, http.StatusOK, templates.TaxonomyLevel(len(taxonomy.SplitPath(path)), children, path))
+}
+
+// selectedPath returns the deepest category chosen in the chained selects.
+// Every level submits its value as "path"; unchosen levels are empty.
+func selectedPath(values []string) string {
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i] != "" {
+			return values[i]
+		}
+	}
+	return ""
+}
+
+// DynamicForm handles dynamic form submission. The chosen category must be
+// a leaf of the current taxonomy.
+func (h *TaxonomyHandler) DynamicForm(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+	title := strings.TrimSpace(r.PostForm.Get("title"))
+
+	trail, err := h.store.Current().Resolve(selectedPath(r.PostForm["path"]))
+	switch {
+	case err != nil:
+		render(w, r, http.StatusUnprocessableEntity, templates.FormError("エラー", "選択されたカテゴリは存在しません。選択し直してください。"))
+		return
+	case len(trail) == 0 || len(trail[len(trail)-1].Children) > 0:
+		render(w, r, http.StatusUnprocessableEntity, templates.FormError("エラー", "最下層のカテゴリまで選択してください。"))
+		return
+	case title == "":
+		render(w, r, http.StatusUnprocessableEntity, templates.FormError("エラー", "タイトルを入力してください。"))
+		return
+	}
+
+	labels := make([]string, len(trail))
+	for i, node := range trail {
+		labels[i] = node.Label
+	}
+	render(w, r, http.StatusOK, templates.DynamicFormCreated(labels, title, time.Now()))
+}
+
+// === Admin ===
+
+// Admin serves the taxonomy admin page
+func (h *TaxonomyHandler) Admin(w http.ResponseWriter, r *http.Request) {
+	renderPage(w, r, "HTMX デモ - カテゴリ管理", templates.TaxonomyAdmin(h.store.Current().Roots()))
+}
+
+// AddNode adds a category below the "parent" path
+func (h *TaxonomyHandler) AddNode(w http.ResponseWriter, r *http.Request) {
+	parent, id, label := r.FormValue("parent"), strings.TrimSpace(r.FormValue("id")), r.FormValue("label")
+	h.update(w, r, func(t *taxonomy.Taxonomy) (*taxonomy.Taxonomy, error) {
+		return t.Add(parent, id, label)
+	})
+}
+
+// RenameNode changes the label of the category at "path"
+func (h *TaxonomyHandler) RenameNode(w http.ResponseWriter, r *http.Request) {
+	path, label := r.FormValue("path"), r.FormValue("label")
+	h.update(w, r, func(t *taxonomy.Taxonomy) (*taxonomy.Taxonomy, error) {
+		return t.Rename(path, label)
+	})
+}
+
+// RemoveNode removes the category at "path" with everything below it
+func (h *TaxonomyHandler) RemoveNode(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	h.update(w, r, func(t *taxonomy.Taxonomy) (*taxonomy.Taxonomy, error) {
+		return t.Remove(path)
+	})
+}
+
+// Reload reads the taxonomy file again, picking up edits made by hand
+func (h *TaxonomyHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.Reload(); err != nil {
+		log.Printf("Failed to reload taxonomy: %v", err)
+		renderAdminError(w, r, taxonomyErrorTarget, http.StatusInternalServerError, "ファイルを読み込めませんでした: "+err.Error())
+		return
+	}
+	h.renderTree(w, r, h.store.Current())
+}
+
+// update applies an edit and returns the new tree, or the reason the edit
+// was refused in the admin error area
+func (h *TaxonomyHandler) update(w http.ResponseWriter, r *http.Request, fn func(t *taxonomy.Taxonomy) (*taxonomy.Taxonomy, error)) {
+	t, err := h.store.Update(fn)
+	switch {
+	case errors.Is(err, taxonomy.ErrNotFound):
+		renderAdminError(w, r, taxonomyErrorTarget, http.StatusNotFound, "カテゴリが見つかりません。ページを再読み込みしてください。")
+	case errors.Is(err, taxonomy.ErrInvalid):
+		renderAdminError(w, r, taxonomyErrorTarget, http.StatusBadRequest, "IDは半角英小文字・数字・-・_ で、同じ階層で重複しないようにし、名前は空にできません。")
+	case err != nil:
+		log.Printf("Failed to update taxonomy: %v", err)
+		renderAdminError(w, r, taxonomyErrorTarget, http.StatusInternalServerError, "カテゴリを保存できませんでした。")
+	default:
+		h.renderTree(w, r, t)
+	}
+}
+
+// renderTree renders the tree and clears an earlier error
+func (h *TaxonomyHandler) renderTree(w http.ResponseWriter, r *http.Request, t *taxonomy.Taxonomy) {
+	render(w, r, http.StatusOK, htmx.NewOOBWriter(templates.TaxonomyTree(t.Roots())).
+		Swap(htmx.SwapInnerHTML, taxonomyErrorTarget, templ.NopComponent))
+}
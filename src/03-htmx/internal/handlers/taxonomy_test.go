@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"htmx-demo/internal/taxonomy"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newTestTaxonomyHandler returns a handler with the bundled taxonomy in
+// memory
+func newTestTaxonomyHandler(t *testing.T) *TaxonomyHandler {
+	t.Helper()
+	store, err := taxonomy.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTaxonomyHandler(store)
+}
+
+func TestTaxonomyOptions_Levels(t *testing.T) {
+	h := newTestTaxonomyHandler(t)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"", []string{`value="technology"`, `value="design"`, `hx-target="#taxonomy-level-1"`, `id="taxonomy-level-1"`}},
+		{"technology", []string{`value="technology/programming"`, `value="technology/cloud"`, `hx-target="#taxonomy-level-2"`}},
+		{"technology/programming", []string{`value="technology/programming/go"`, `hx-target="#taxonomy-level-3"`}},
+		{"technology/programming/go", nil},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		h.Options(rr, httptest.NewRequest("GET", "/api/taxonomy/options?path="+url.QueryEscape(tt.path), nil))
+
+		body := rr.Body.String()
+		if tt.want == nil && body != "" {
+			t.Errorf("leaf %q rendered: %s", tt.path, body)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("path %q: want %s in: %s", tt.path, want, body)
+			}
+		}
+	}
+}
+
+func TestDynamicForm_ValidatesPath(t *testing.T) {
+	h := newTestTaxonomyHandler(t)
+
+	tests := []struct {
+		name   string
+		form   url.Values
+		status int
+		want   string
+	}{
+		{"leaf", url.Values{"path": {"technology", "technology/programming", "technology/programming/go"}, "title": {"入門"}}, http.StatusOK, "テクノロジー › プログラミング › Go"},
+		{"unchosen deeper level", url.Values{"path": {"technology/programming", ""}, "title": {"入門"}}, http.StatusUnprocessableEntity, "最下層のカテゴリまで選択してください"},
+		{"nothing chosen", url.Values{"title": {"入門"}}, http.StatusUnprocessableEntity, "最下層のカテゴリまで選択してください"},
+		{"unknown path", url.Values{"path": {"technology/quantum"}, "title": {"入門"}}, http.StatusUnprocessableEntity, "存在しません"},
+		{"no title", url.Values{"path": {"business/sales"}}, http.StatusUnprocessableEntity, "タイトルを入力してください"},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		h.DynamicForm(rr, formRequest("/api/dynamic-form", tt.form))
+
+		if rr.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rr.Code, tt.status)
+		}
+		if !strings.Contains(rr.Body.String(), tt.want) {
+			t.Errorf("%s: want %s in: %s", tt.name, tt.want, rr.Body.String())
+		}
+	}
+}
+
+func TestTaxonomyAdmin_EditsReachTheSelects(t *testing.T) {
+	h := newTestTaxonomyHandler(t)
+
+	rr := httptest.NewRecorder()
+	h.AddNode(rr, formRequest("/admin/taxonomy/nodes", url.Values{"parent": {"business/sales"}, "id": {"b2b"}, "label": {"法人営業"}}))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `id="taxonomy-tree"`) {
+		t.Fatalf("add: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	h.Options(rr, httptest.NewRequest("GET", "/api/taxonomy/options?path=business/sales", nil))
+	if !strings.Contains(rr.Body.String(), `value="business/sales/b2b"`) {
+		t.Errorf("added category not offered: %s", rr.Body.String())
+	}
+
+	// business/sales is no longer a leaf
+	rr = httptest.NewRecorder()
+	h.DynamicForm(rr, formRequest("/api/dynamic-form", url.Values{"path": {"business/sales"}, "title": {"提案"}}))
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("submitting a category with children: status = %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	h.RemoveNode(rr, httptest.NewRequest("DELETE", "/admin/taxonomy/nodes?path=business", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("remove: %d %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	h.Options(rr, httptest.NewRequest("GET", "/api/taxonomy/options", nil))
+	if strings.Contains(rr.Body.String(), `value="business"`) {
+		t.Errorf("removed category still offered: %s", rr.Body.String())
+	}
+}
+
+func TestTaxonomyAdmin_RefusedEditsShowError(t *testing.T) {
+	h := newTestTaxonomyHandler(t)
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		handle http.HandlerFunc
+		status int
+	}{
+		{"invalid id", formRequest("/admin/taxonomy/nodes", url.Values{"parent": {""}, "id": {"Bad Id"}, "label": {"x"}}), h.AddNode, http.StatusBadRequest},
+		{"duplicate id", formRequest("/admin/taxonomy/nodes", url.Values{"parent": {"technology"}, "id": {"cloud"}, "label": {"x"}}), h.AddNode, http.StatusBadRequest},
+		{"unknown parent", formRequest("/admin/taxonomy/nodes", url.Values{"parent": {"nope"}, "id": {"a"}, "label": {"x"}}), h.AddNode, http.StatusNotFound},
+		{"empty label", formRequest("/admin/taxonomy/nodes", url.Values{"path": {"technology"}, "label": {" "}}), h.RenameNode, http.StatusBadRequest},
+		{"unknown node", httptest.NewRequest("DELETE", "/admin/taxonomy/nodes?path=nope", nil), h.RemoveNode, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		tt.handle(rr, tt.req)
+
+		if rr.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, rr.Code, tt.status)
+		}
+		if got := rr.Header().Get("HX-Retarget"); got != "#taxonomy-admin-error" {
+			t.Errorf("%s: HX-Retarget = %q", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"htmx-demo/internal/models"
+	"htmx-demo/internal/session"
+	"htmx-demo/internal/templates"
+	"htmx-demo/internal/upload"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"shared/htmx"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// maxDescriptionBytes limits the description form field
+const maxDescriptionBytes = 1 << 10
+
+// multipartOverhead allows for form fields and part headers on top of the file
+const multipartOverhead = 64 << 10
+
+// UploadHandler handles the file upload demo. Files belong to the
+// visitor's session.
+type UploadHandler struct {
+	store  *upload.Store
+	config upload.Config
+}
+
+// NewUploadHandler creates a new UploadHandler
+func NewUploadHandler(store *upload.Store, config upload.Config) *UploadHandler {
+	return &UploadHandler{store: store, config: config}
+}
+
+// FileUpload streams the "file" part of a multipart form into the store.
+// The file is never buffered in memory as a whole; its type is sniffed
+// from the content and checked against the allowlist.
+func (h *UploadHandler) FileUpload(w http.ResponseWriter, r *http.Request) {
+	owner := session.IDFromContext(r.Context())
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.MaxBytes+multipartOverhead)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		h.renderError(w, r, http.StatusBadRequest, "multipart/form-data 形式で送信してください")
+		return
+	}
+
+	var saved *models.UploadedFile
+	var description string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			h.renderUploadFailure(w, r, saved, err)
+			return
+		}
+
+		switch part.FormName() {
+		case "description":
+			value, err := io.ReadAll(io.LimitReader(part, maxDescriptionBytes))
+			if err != nil {
+				h.renderUploadFailure(w, r, saved, err)
+				return
+			}
+			description = strings.TrimSpace(string(value))
+		case "file":
+			if saved != nil {
+				continue
+			}
+			saved, err = h.saveFile(owner, part)
+			if err != nil {
+				h.renderUploadFailure(w, r, nil, err)
+				return
+			}
+		}
+		part.Close()
+	}
+
+	if saved == nil {
+		h.renderError(w, r, http.StatusBadRequest, "ファイルを選択してください")
+		return
+	}
+
+	// The description may arrive after the file, so it is attached last
+	if description != "" {
+		if saved, err = h.store.SetDescription(owner, saved.ID, description); err != nil {
+			h.renderError(w, r, http.StatusInternalServerError, "アップロードに失敗しました")
+			return
+		}
+	}
+
+	htmx.TriggerEvent(w, "uploadsChanged")
+	render(w, r, http.StatusCreated, templates.UploadResult(*saved))
+}
+
+// saveFile runs one file part through the pipeline: name sanitizing,
+// content sniffing, size limiting and content-addressed storage
+func (h *UploadHandler) saveFile(owner string, part *multipart.Part) (*models.UploadedFile, error) {
+	name, err := upload.SanitizeFilename(part.FileName())
+	if err != nil {
+		return nil, err
+	}
+
+	limited := upload.LimitReader(part, h.config.MaxBytes)
+	contentType, content, err := upload.Sniff(limited, h.config)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.store.Save(owner, models.UploadedFile{Name: name, ContentType: contentType}, content)
+}
+
+// ListUploads renders the visitor's uploaded files
+func (h *UploadHandler) ListUploads(w http.ResponseWriter, r *http.Request) {
+	files := h.store.List(session.IDFromContext(r.Context()))
+	render(w, r, http.StatusOK, templates.UploadList(files))
+}
+
+// DownloadUpload serves one of the visitor's files as an attachment
+func (h *UploadHandler) DownloadUpload(w http.ResponseWriter, r *http.Request) {
+	file, content, err := h.store.Open(session.IDFromContext(r.Context()), mux.Vars(r)["id"])
+	if errors.Is(err, upload.ErrNotFound) {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to open file", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("ETag", `"`+file.Hash+`"`)
+	http.ServeContent(w, r, "", file.UploadedAt, content)
+}
+
+// DeleteUpload deletes one of the visitor's files and renders the updated list
+func (h *UploadHandler) DeleteUpload(w http.ResponseWriter, r *http.Request) {
+	owner := session.IDFromContext(r.Context())
+	err := h.store.Delete(owner, mux.Vars(r)["id"])
+	if errors.Is(err, upload.ErrNotFound) {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to delete file", http.StatusInternalServerError)
+		return
+	}
+
+	render(w, r, http.StatusOK, templates.UploadList(h.store.List(owner)))
+}
+
+// renderUploadFailure reports a failed upload, removing a file that was
+// already stored for the same request
+func (h *UploadHandler) renderUploadFailure(w http.ResponseWriter, r *http.Request, saved *models.UploadedFile, err error) {
+	if saved != nil {
+		h.store.Delete(session.IDFromContext(r.Context()), saved.ID)
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, upload.ErrTooLarge), errors.As(err, &maxBytesErr):
+		h.renderError(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("ファイルサイズは %d バイト以下にしてください", h.config.MaxBytes))
+	case errors.Is(err, upload.ErrTypeNotAllowed):
+		h.renderError(w, r, http.StatusUnsupportedMediaType, "このファイル形式はアップロードできません")
+	case errors.Is(err, upload.ErrEmptyFile):
+		h.renderError(w, r, http.StatusBadRequest, "空のファイルはアップロードできません")
+	case errors.Is(err, upload.ErrInvalidFilename):
+		h.renderError(w, r, http.StatusBadRequest, "ファイル名が不正です")
+	default:
+		h.renderError(w, r, http.StatusInternalServerError, "アップロードに失敗しました")
+	}
+}
+
+func (h *UploadHandler) renderError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	render(w, r, status, templates.UploadError(message))
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"htmx-demo/internal/upload"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newUploadTestRouter(t *testing.T, maxBytes int64) *mux.Router {
+	t.Helper()
+	cfg := upload.Config{MaxBytes: maxBytes, AllowedTypes: []string{"text/plain", "image/png"}}
+	store, err := upload.NewStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+	h := NewUploadHandler(store, cfg)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/file-upload", h.FileUpload).Methods("POST")
+	router.HandleFunc("/api/uploads", h.ListUploads).Methods("GET")
+	router.HandleFunc("/api/uploads/{id}", h.DownloadUpload).Methods("GET")
+	router.HandleFunc("/api/uploads/{id}", h.DeleteUpload).Methods("DELETE")
+	return router
+}
+
+// multipartRequest builds an upload request from sessionID with the
+// description field after the file, as browsers send it for this form
+func multipartRequest(sessionID, filename string, content []byte, description string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", filename)
+	fw.Write(content)
+	mw.WriteField("description", description)
+	mw.Close()
+
+	req := requestAs(sessionID, "POST", "/api/file-upload")
+	req.Body = io.NopCloser(&body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func serveUpload(router http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+var uploadIDPattern = regexp.MustCompile(`/api/uploads/([0-9a-f]+)`)
+
+func TestUploadHandler(t *testing.T) {
+	router := newUploadTestRouter(t, 1024)
+
+	rr := serveUpload(router, multipartRequest("alice", "notes.txt", []byte("hello upload"), "my notes"))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("upload status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("HX-Trigger") != "uploadsChanged" {
+		t.Error("upload did not trigger a list refresh")
+	}
+	for _, want := range []string{"notes.txt", "my notes", "text/plain", "12 B"} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Errorf("upload result is missing %q: %s", want, rr.Body.String())
+		}
+	}
+
+	rr = serveUpload(router, requestAs("alice", "GET", "/api/uploads"))
+	match := uploadIDPattern.FindStringSubmatch(rr.Body.String())
+	if match == nil {
+		t.Fatalf("list has no download link: %s", rr.Body.String())
+	}
+	id := match[1]
+
+	t.Run("download", func(t *testing.T) {
+		rr := serveUpload(router, requestAs("alice", "GET", "/api/uploads/"+id))
+		if rr.Code != http.StatusOK || rr.Body.String() != "hello upload" {
+			t.Fatalf("download = %d %q", rr.Code, rr.Body.String())
+		}
+		if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename=notes.txt` {
+			t.Errorf("Content-Disposition = %q", got)
+		}
+		if rr.Header().Get("X-Content-Type-Options") != "nosniff" {
+			t.Error("download is missing nosniff")
+		}
+	})
+
+	t.Run("other sessions cannot see the file", func(t *testing.T) {
+		rr := serveUpload(router, requestAs("bob", "GET", "/api/uploads"))
+		if strings.Contains(rr.Body.String(), "notes.txt") {
+			t.Error("bob's list shows alice's file")
+		}
+		if rr := serveUpload(router, requestAs("bob", "GET", "/api/uploads/"+id)); rr.Code != http.StatusNotFound {
+			t.Errorf("bob download status = %d, want 404", rr.Code)
+		}
+	})
+
+	t.Run("rejected uploads", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			filename string
+			content  []byte
+			want     int
+		}{
+			{name: "too large", filename: "big.txt", content: bytes.Repeat([]byte("a"), 1025), want: http.StatusRequestEntityTooLarge},
+			{name: "type from content, not extension", filename: "page.txt", content: []byte("<!DOCTYPE html><script>alert(1)</script>"), want: http.StatusUnsupportedMediaType},
+			{name: "empty", filename: "empty.txt", content: nil, want: http.StatusBadRequest},
+		}
+		for _, tt := range tests {
+			rr := serveUpload(router, multipartRequest("carol", tt.filename, tt.content, ""))
+			if rr.Code != tt.want {
+				t.Errorf("%s: status = %d, want %d", tt.name, rr.Code, tt.want)
+			}
+		}
+		if rr := serveUpload(router, requestAs("carol", "GET", "/api/uploads")); strings.Contains(rr.Body.String(), "/api/uploads/") {
+			t.Errorf("rejected uploads were stored: %s", rr.Body.String())
+		}
+	})
+
+	t.Run("file names are escaped", func(t *testing.T) {
+		rr := serveUpload(router, multipartRequest("dave", `"><img src=x onerror=alert(1)>.txt`, []byte("x"), "<b>bold</b>"))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("status = %d", rr.Code)
+		}
+		if strings.Contains(rr.Body.String(), "<img") || strings.Contains(rr.Body.String(), "<b>") {
+			t.Errorf("unescaped markup in response: %s", rr.Body.String())
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if rr := serveUpload(router, requestAs("bob", "DELETE", "/api/uploads/"+id)); rr.Code != http.StatusNotFound {
+			t.Errorf("bob delete status = %d, want 404", rr.Code)
+		}
+		rr := serveUpload(router, requestAs("alice", "DELETE", "/api/uploads/"+id))
+		if rr.Code != http.StatusOK || strings.Contains(rr.Body.String(), "notes.txt") {
+			t.Errorf("delete = %d %s", rr.Code, rr.Body.String())
+		}
+		if rr := serveUpload(router, requestAs("alice", "GET", "/api/uploads/"+id)); rr.Code != http.StatusNotFound {
+			t.Errorf("deleted file download status = %d, want 404", rr.Code)
+		}
+	})
+}
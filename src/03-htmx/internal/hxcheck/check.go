@@ -0,0 +1,441 @@
+// Package hxcheck checks the htmx attributes of rendered pages against the
+// router serving them: every request URL must have a route accepting its
+// method, every target, include and indicator selector must match an
+// element, and every trigger must parse. Fragments a page loads by itself,
+// on load, reveal or polling, are fetched, swapped into the page and
+// checked in place, so selectors may name elements those fragments add.
+package hxcheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/net/html"
+)
+
+// maxFragments bounds the fragments loaded for one page, in case fragments
+// keep loading more of themselves
+const maxFragments = 100
+
+// requestAttrs are the attributes making requests, with their method
+var requestAttrs = []struct{ name, method string }{
+	{"hx-get", http.MethodGet},
+	{"hx-post", http.MethodPost},
+	{"hx-put", http.MethodPut},
+	{"hx-patch", http.MethodPatch},
+	{"hx-delete", http.MethodDelete},
+	{"sse-connect", http.MethodGet},
+}
+
+// selectorAttrs are the attributes naming elements with extended selectors
+var selectorAttrs = []string{"hx-target", "hx-include", "hx-indicator"}
+
+// Issue is a problem with an htmx attribute
+type Issue struct {
+	// Page is the page the element ends up on
+	Page string
+	// Source is the URL that rendered the element: the page or a fragment
+	Source  string
+	Element string
+	Attr    string
+	Message string
+}
+
+func (i Issue) String() string {
+	source := i.Page
+	if i.Source != i.Page {
+		source += " (" + i.Source + ")"
+	}
+	return fmt.Sprintf("%s: %s %s: %s", source, i.Element, i.Attr, i.Message)
+}
+
+// Checker checks the pages served by a router
+type Checker struct {
+	Router *mux.Router
+	// Header is sent with every request, e.g. credentials for admin pages
+	Header http.Header
+	// Timeout bounds each request; 5 seconds if zero
+	Timeout time.Duration
+}
+
+// response is a page or fragment rendered by the router
+type response struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+// html reports whether the response is a successful HTML response
+func (r response) html() bool {
+	return r.status >= 200 && r.status < 300 && strings.HasPrefix(r.contentType, "text/html")
+}
+
+// get renders target through the router. The writer cannot flush, so
+// streaming handlers like server-sent events give up instead of waiting
+// for the timeout.
+func (c *Checker) get(target string, fragment bool) response {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+	if fragment {
+		req.Header.Set("HX-Request", "true")
+	}
+	rr := httptest.NewRecorder()
+	c.Router.ServeHTTP(struct{ http.ResponseWriter }{rr}, req)
+	return response{status: rr.Code, contentType: rr.Header().Get("Content-Type"), body: rr.Body.Bytes()}
+}
+
+// Pages returns the paths of the GET routes without variables that serve a
+// complete HTML document
+func (c *Checker) Pages() ([]string, error) {
+	var candidates []string
+	err := c.Router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			return nil
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil || strings.Contains(template, "{") {
+			return nil
+		}
+		if methods, err := route.GetMethods(); err == nil && !contains(methods, http.MethodGet) {
+			return nil
+		}
+		candidates = append(candidates, template)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []string
+	for _, path := range candidates {
+		resp := c.get(path, false)
+		start := bytes.ToLower(bytes.TrimSpace(resp.body))
+		if resp.html() && bytes.HasPrefix(start, []byte("<!doctype html")) {
+			pages = append(pages, path)
+		}
+	}
+	sort.Strings(pages)
+	return pages, nil
+}
+
+// Check checks pages, or every page of Pages if none are given
+func (c *Checker) Check(pages ...string) ([]Issue, error) {
+	if len(pages) == 0 {
+		var err error
+		if pages, err = c.Pages(); err != nil {
+			return nil, err
+		}
+	}
+
+	var issues []Issue
+	for _, page := range pages {
+		pageIssues, err := c.checkPage(page)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, pageIssues...)
+	}
+	return issues, nil
+}
+
+// pageCheck is the state of checking one page
+type pageCheck struct {
+	checker *Checker
+	page    string
+	base    *url.URL
+	doc     *html.Node
+	issues  []Issue
+	// loads are the fragments the page loads by itself, not yet fetched
+	loads  []load
+	loaded map[string]bool
+}
+
+// load is a fragment an element requests by itself
+type load struct {
+	elt    *html.Node
+	target string
+}
+
+func (c *Checker) checkPage(page string) ([]Issue, error) {
+	resp := c.get(page, false)
+	if !resp.html() {
+		return nil, fmt.Errorf("page %s: status %d, content type %q", page, resp.status, resp.contentType)
+	}
+	doc, err := html.Parse(bytes.NewReader(resp.body))
+	if err != nil {
+		return nil, fmt.Errorf("page %s: %w", page, err)
+	}
+	base, err := url.Parse(page)
+	if err != nil {
+		return nil, fmt.Errorf("page %s: %w", page, err)
+	}
+
+	p := &pageCheck{checker: c, page: page, base: base, doc: doc, loaded: make(map[string]bool)}
+	p.checkTree(doc, page)
+	for len(p.loads) > 0 && len(p.loaded) < maxFragments {
+		next := p.loads[0]
+		p.loads = p.loads[1:]
+		if p.loaded[next.target] {
+			continue
+		}
+		p.loaded[next.target] = true
+		p.loadFragment(next)
+	}
+	return p.issues, nil
+}
+
+func (p *pageCheck) report(source string, n *html.Node, attrName, format string, args ...any) {
+	p.issues = append(p.issues, Issue{
+		Page:    p.page,
+		Source:  source,
+		Element: describe(n, attrName),
+		Attr:    attrName,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// checkTree checks root and the elements below it
+func (p *pageCheck) checkTree(root *html.Node, source string) {
+	if root.Type == html.ElementNode {
+		p.checkElement(root, source)
+	}
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		p.checkTree(c, source)
+	}
+}
+
+// hxAttr returns the value of an htmx attribute, written with or without
+// the data- prefix
+func hxAttr(n *html.Node, name string) (string, bool) {
+	if value, ok := lookupAttr(n, name); ok {
+		return value, true
+	}
+	return lookupAttr(n, "data-"+name)
+}
+
+// inherited returns the value of an attribute htmx inherits from
+// ancestors, like hx-target, and the element declaring it
+func inherited(n *html.Node, name string) (string, *html.Node) {
+	for ; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		if value, ok := hxAttr(n, name); ok {
+			return value, n
+		}
+	}
+	return "", nil
+}
+
+func (p *pageCheck) checkElement(n *html.Node, source string) {
+	var triggers []trigger
+	if value, ok := hxAttr(n, "hx-trigger"); ok {
+		var err error
+		if triggers, err = parseTriggers(value); err != nil {
+			p.report(source, n, "hx-trigger", "%v", err)
+		}
+		for _, t := range triggers {
+			for _, m := range t.selectors {
+				p.checkSelector(source, n, "hx-trigger", m.name+":", m.selector)
+			}
+		}
+	}
+
+	for _, name := range selectorAttrs {
+		if value, ok := hxAttr(n, name); ok {
+			p.checkSelector(source, n, name, "", value)
+		}
+	}
+
+	for _, request := range requestAttrs {
+		name, method := request.name, request.method
+		value, ok := hxAttr(n, name)
+		if !ok || value == "" {
+			// An empty URL requests the current page
+			continue
+		}
+		u, ok := p.checkRoute(source, n, name, method, value)
+		if !ok || method != http.MethodGet || name == "sse-connect" {
+			continue
+		}
+		for _, t := range triggers {
+			if t.automatic() {
+				p.loads = append(p.loads, load{elt: n, target: u.RequestURI()})
+				break
+			}
+		}
+	}
+}
+
+// checkSelector reports a selector that does not parse or matches nothing
+func (p *pageCheck) checkSelector(source string, n *html.Node, attrName, prefix, value string) {
+	if prefix == "from:" && (value == "document" || value == "window") {
+		return
+	}
+	found, err := resolve(p.doc, n, value)
+	switch {
+	case err != nil:
+		p.report(source, n, attrName, "%s%v", prefix, err)
+	case found == nil:
+		p.report(source, n, attrName, "%s%q matches no element", prefix, value)
+	}
+}
+
+// checkRoute reports a request URL without a route for its method. It
+// returns the URL resolved against the page, which is false for URLs that
+// are invalid, unrouted or on another host.
+func (p *pageCheck) checkRoute(source string, n *html.Node, attrName, method, value string) (*url.URL, bool) {
+	u, err := p.base.Parse(value)
+	if err != nil {
+		p.report(source, n, attrName, "invalid URL: %v", err)
+		return nil, false
+	}
+	if u.Host != "" {
+		return nil, false
+	}
+
+	var match mux.RouteMatch
+	req := httptest.NewRequest(method, u.RequestURI(), nil)
+	if p.checker.Router.Match(req, &match) {
+		return u, true
+	}
+	if match.MatchErr == mux.ErrMethodMismatch {
+		p.report(source, n, attrName, "the route for %s does not accept %s", u.Path, method)
+	} else {
+		p.report(source, n, attrName, "no route matches %s", u.Path)
+	}
+	return nil, false
+}
+
+// loadFragment fetches a fragment, swaps it into the page like htmx would
+// and checks it there
+func (p *pageCheck) loadFragment(l load) {
+	resp := p.checker.get(l.target, true)
+	if !resp.html() {
+		return
+	}
+
+	target := l.elt
+	if value, declaredBy := inherited(l.elt, "hx-target"); declaredBy != nil {
+		if target, _ = resolve(p.doc, declaredBy, value); target == nil {
+			// Already reported where hx-target is checked
+			return
+		}
+	}
+	swap, _ := inherited(l.elt, "hx-swap")
+	style := "innerhtml"
+	if fields := strings.Fields(swap); len(fields) > 0 {
+		style = strings.ToLower(fields[0])
+	}
+
+	context := target
+	if style == "outerhtml" || style == "beforebegin" || style == "afterend" {
+		if target.Parent == nil || target.Parent.Type != html.ElementNode {
+			return
+		}
+		context = target.Parent
+	}
+	nodes, err := html.ParseFragment(bytes.NewReader(resp.body), context)
+	if err != nil {
+		p.issues = append(p.issues, Issue{Page: p.page, Source: l.target, Message: fmt.Sprintf("unparsable fragment: %v", err)})
+		return
+	}
+
+	nodes = p.swapOOB(nodes, l.target)
+	swapInto(target, style, nodes)
+	for _, n := range nodes {
+		p.checkTree(n, l.target)
+	}
+}
+
+// swapOOB checks and removes the out-of-band elements of a fragment. The
+// rest of the fragment is returned.
+func (p *pageCheck) swapOOB(nodes []*html.Node, source string) []*html.Node {
+	var rest []*html.Node
+	for _, n := range nodes {
+		value, ok := hxAttr(n, "hx-swap-oob")
+		if n.Type != html.ElementNode || !ok {
+			rest = append(rest, n)
+			continue
+		}
+		_, selector, hasSelector := strings.Cut(value, ":")
+		if !hasSelector {
+			if attr(n, "id") == "" {
+				p.report(source, n, "hx-swap-oob", "needs an id or a selector")
+				continue
+			}
+			selector = "#" + attr(n, "id")
+		}
+		p.checkSelector(source, n, "hx-swap-oob", "", selector)
+		p.checkTree(n, source)
+	}
+	return rest
+}
+
+// swapInto inserts nodes relative to target with an htmx swap style
+func swapInto(target *html.Node, style string, nodes []*html.Node) {
+	switch style {
+	case "outerhtml":
+		for _, n := range nodes {
+			target.Parent.InsertBefore(n, target)
+		}
+		target.Parent.RemoveChild(target)
+	case "beforebegin":
+		for _, n := range nodes {
+			target.Parent.InsertBefore(n, target)
+		}
+	case "afterend":
+		next := target.NextSibling
+		for _, n := range nodes {
+			target.Parent.InsertBefore(n, next)
+		}
+	case "afterbegin":
+		first := target.FirstChild
+		for _, n := range nodes {
+			target.InsertBefore(n, first)
+		}
+	case "beforeend":
+		for _, n := range nodes {
+			target.AppendChild(n)
+		}
+	case "none", "delete":
+	default:
+		for target.FirstChild != nil {
+			target.RemoveChild(target.FirstChild)
+		}
+		for _, n := range nodes {
+			target.AppendChild(n)
+		}
+	}
+}
+
+// describe renders the start tag of n with its id and the attribute name
+func describe(n *html.Node, attrName string) string {
+	var b strings.Builder
+	b.WriteString("<" + n.Data)
+	if id := attr(n, "id"); id != "" {
+		fmt.Fprintf(&b, " id=%q", id)
+	}
+	if value, ok := hxAttr(n, attrName); ok && attrName != "" {
+		if len(value) > 60 {
+			value = value[:57] + "..."
+		}
+		fmt.Fprintf(&b, " %s=%q", attrName, value)
+	}
+	b.WriteString(">")
+	return b.String()
+}
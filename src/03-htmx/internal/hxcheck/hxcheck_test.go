@@ -0,0 +1,238 @@
+package hxcheck
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/net/html"
+)
+
+func parseDoc(t *testing.T, src string) *html.Node {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func byID(t *testing.T, doc *html.Node, id string) *html.Node {
+	t.Helper()
+	s, err := parseSelector("#" + id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := findFirst(doc, s)
+	if n == nil {
+		t.Fatalf("no element #%s", id)
+	}
+	return n
+}
+
+func TestParseSelector_Invalid(t *testing.T) {
+	for _, s := range []string{"", "#", ".a..b", "div >", "[name", "[name^^=x]", "a:not(b", "div, "} {
+		if _, err := parseSelector(s); err == nil {
+			t.Errorf("parseSelector(%q) accepted an invalid selector", s)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	doc := parseDoc(t, `<div id="outer" class="box card">
+		<form id="form" data-role="edit-form">
+			<input id="name" name="user-name" type="text">
+			<button id="button">送信</button>
+		</form>
+		<span id="status" class="status"></span>
+	</div>
+	<ul id="list"><li class="item">1</li><li class="item last">2</li></ul>`)
+	button := byID(t, doc, "button")
+
+	tests := []struct {
+		selector string
+		want     string // id of the resolved element, empty for none
+	}{
+		{"this", "button"},
+		{"#status", "status"},
+		{"div.box.card", "outer"},
+		{"#outer > form", "form"},
+		{"#outer > input", ""},
+		{"form input[name^=user]", "name"},
+		{"[data-role='edit-form']", "form"},
+		{"input:checked, #list", "name"},
+		{"li.item + li.last", ""},
+		{"closest form", "form"},
+		{"closest .card", "outer"},
+		{"closest ul", ""},
+		{"find input", ""},
+		{"next .status", "status"},
+		{"previous input", "name"},
+		{"next", ""},
+		{"previous", "name"},
+		{".missing", ""},
+	}
+	for _, tt := range tests {
+		found, err := resolve(doc, button, tt.selector)
+		if err != nil {
+			t.Errorf("resolve(%q): %v", tt.selector, err)
+			continue
+		}
+		got := ""
+		if found != nil {
+			got = attr(found, "id")
+		}
+		if got != tt.want {
+			t.Errorf("resolve(%q) = #%s, want #%s", tt.selector, got, tt.want)
+		}
+	}
+
+	if found, _ := resolve(doc, byID(t, doc, "list"), "find li.item ~ li"); found == nil || attr(found, "class") != "item last" {
+		t.Errorf("find with sibling combinator resolved %v", found)
+	}
+}
+
+func TestParseTriggers(t *testing.T) {
+	valid := []string{
+		"click",
+		"load",
+		"every 2s",
+		"keyup changed delay:500ms, search",
+		"click[ctrlKey&&shiftKey] once throttle:1s queue:last",
+		"revealed",
+		"intersect once threshold:0.5 root:#list",
+		"click from:closest form target:.item",
+		"htmx:afterSwap from:body",
+		"sse:message",
+	}
+	for _, value := range valid {
+		if _, err := parseTriggers(value); err != nil {
+			t.Errorf("parseTriggers(%q): %v", value, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"click,",
+		"every",
+		"every soon",
+		"click[ctrlKey",
+		"click delay:",
+		"click delay:fast",
+		"click queue:random",
+		"intersect threshold:2",
+		"click from:",
+		"click sometimes",
+	}
+	for _, value := range invalid {
+		if _, err := parseTriggers(value); err == nil {
+			t.Errorf("parseTriggers(%q) accepted an invalid trigger", value)
+		}
+	}
+}
+
+func TestParseTriggers_Automatic(t *testing.T) {
+	triggers, err := parseTriggers("click, load delay:1s, every 5s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var automatic []string
+	for _, tr := range triggers {
+		if tr.automatic() {
+			automatic = append(automatic, tr.event)
+		}
+	}
+	if strings.Join(automatic, ",") != "load,every" {
+		t.Errorf("automatic triggers = %v", automatic)
+	}
+}
+
+// newRouter serves the given pages and fragments as HTML
+func newRouter(routes map[string]string) *mux.Router {
+	router := mux.NewRouter()
+	for path, body := range routes {
+		router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			io.WriteString(w, body)
+		}).Methods(http.MethodGet)
+	}
+	router.HandleFunc("/api/save", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodPost)
+	router.HandleFunc("/api/items/{id}", func(w http.ResponseWriter, r *http.Request) {}).Methods(http.MethodDelete)
+	return router
+}
+
+func TestChecker_Pages(t *testing.T) {
+	checker := &Checker{Router: newRouter(map[string]string{
+		"/":          `<!DOCTYPE html><html><body></body></html>`,
+		"/about":     `<!doctype html><html><body></body></html>`,
+		"/api/part":  `<div></div>`,
+		"/items/{x}": `<!doctype html><html><body></body></html>`,
+	})}
+
+	pages, err := checker.Pages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(pages, ",") != "/,/about" {
+		t.Errorf("pages = %v", pages)
+	}
+}
+
+func TestChecker_CleanPage(t *testing.T) {
+	checker := &Checker{Router: newRouter(map[string]string{
+		"/": `<!doctype html><html><body hx-target="#result">
+			<form hx-post="/api/save" hx-include="[name='extra']">
+				<input name="extra">
+				<button hx-delete="/api/items/1" hx-target="closest form" hx-swap="outerHTML">削除</button>
+			</form>
+			<div id="result"></div>
+			<div hx-get="/api/part" hx-trigger="load"></div>
+			<a href="https://example.com" hx-get="https://example.com/elsewhere">外部</a>
+		</body></html>`,
+		// The fragment is swapped into #result, inherited from body
+		"/api/part": `<button hx-get="/api/part?again=1" hx-target="closest #result">再読込</button>`,
+	})}
+
+	issues, err := checker.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		t.Errorf("unexpected issue: %s", issue)
+	}
+}
+
+func TestChecker_ReportsIssues(t *testing.T) {
+	checker := &Checker{Router: newRouter(map[string]string{
+		"/": `<!doctype html><html><body>
+			<button hx-get="/api/missing">A</button>
+			<button hx-get="/api/save">B</button>
+			<button hx-post="/api/save" hx-target="#nowhere">C</button>
+			<input hx-get="/api/part" hx-trigger="keyup delay:soon" hx-target="this">
+			<div id="slot" hx-get="/api/part" hx-trigger="load"></div>
+		</body></html>`,
+		"/api/part": `<span hx-put="/api/items/2" hx-target="closest #slot">D</span>`,
+	})}
+
+	issues, err := checker.Check("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`/: <button hx-get="/api/missing"> hx-get: no route matches /api/missing`,
+		`/: <button hx-get="/api/save"> hx-get: the route for /api/save does not accept GET`,
+		`/: <button hx-target="#nowhere"> hx-target: "#nowhere" matches no element`,
+		`/: <input hx-trigger="keyup delay:soon"> hx-trigger: `,
+		`/ (/api/part): <span hx-put="/api/items/2"> hx-put: the route for /api/items/2 does not accept PUT`,
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for i, issue := range issues {
+		if !strings.HasPrefix(issue.String(), want[i]) {
+			t.Errorf("issue %d = %s, want prefix %s", i, issue, want[i])
+		}
+	}
+}
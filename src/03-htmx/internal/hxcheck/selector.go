@@ -0,0 +1,477 @@
+package hxcheck
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// selector is a parsed CSS selector list. It supports type, universal, id,
+// class and attribute selectors with the descendant, child and sibling
+// combinators. Pseudo-classes are parsed but match any element, so a
+// selector like input:checked resolves when an input exists.
+type selector []complexSelector
+
+// complexSelector is compounds joined by combinators; combinators[i] joins
+// compounds[i] and compounds[i+1]
+type complexSelector struct {
+	compounds   []compound
+	combinators []byte
+}
+
+type compound struct {
+	tag     string // empty for any
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	name, op, value string
+}
+
+// parseSelector parses a CSS selector list
+func parseSelector(s string) (selector, error) {
+	p := &selectorParser{s: s}
+	var list selector
+	for {
+		p.skipSpace()
+		complex, err := p.complex()
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", s, err)
+		}
+		list = append(list, complex)
+		p.skipSpace()
+		if p.done() {
+			return list, nil
+		}
+		if p.peek() != ',' {
+			return nil, fmt.Errorf("invalid selector %q: unexpected %q", s, p.peek())
+		}
+		p.pos++
+	}
+}
+
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+func (p *selectorParser) done() bool { return p.pos >= len(p.s) }
+func (p *selectorParser) peek() byte { return p.s[p.pos] }
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for !p.done() && isSpace(p.peek()) {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isNameChar(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *selectorParser) name() (string, error) {
+	start := p.pos
+	for !p.done() && (isNameChar(p.peek()) || p.peek() == '\\') {
+		if p.peek() == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		if p.done() {
+			return "", fmt.Errorf("missing name at end")
+		}
+		return "", fmt.Errorf("unexpected %q", p.peek())
+	}
+	return strings.ReplaceAll(p.s[start:min(p.pos, len(p.s))], `\`, ""), nil
+}
+
+func (p *selectorParser) complex() (complexSelector, error) {
+	var c complexSelector
+	for {
+		comp, err := p.compound()
+		if err != nil {
+			return c, err
+		}
+		c.compounds = append(c.compounds, comp)
+
+		spaced := p.skipSpace()
+		if p.done() || p.peek() == ',' {
+			return c, nil
+		}
+		switch p.peek() {
+		case '>', '+', '~':
+			c.combinators = append(c.combinators, p.peek())
+			p.pos++
+			p.skipSpace()
+		default:
+			if !spaced {
+				return c, fmt.Errorf("unexpected %q", p.peek())
+			}
+			c.combinators = append(c.combinators, ' ')
+		}
+	}
+}
+
+func (p *selectorParser) compound() (compound, error) {
+	var c compound
+	start := p.pos
+	if !p.done() && p.peek() == '*' {
+		p.pos++
+	} else if !p.done() && isNameChar(p.peek()) {
+		tag, _ := p.name()
+		c.tag = strings.ToLower(tag)
+	}
+
+	for !p.done() {
+		var err error
+		switch p.peek() {
+		case '#':
+			p.pos++
+			c.id, err = p.name()
+		case '.':
+			p.pos++
+			var class string
+			class, err = p.name()
+			c.classes = append(c.classes, class)
+		case '[':
+			var attr attrSelector
+			attr, err = p.attribute()
+			c.attrs = append(c.attrs, attr)
+		case ':':
+			err = p.pseudo()
+		default:
+			if p.pos == start {
+				return c, fmt.Errorf("unexpected %q", p.peek())
+			}
+			return c, nil
+		}
+		if err != nil {
+			return c, err
+		}
+	}
+	if p.pos == start {
+		return c, fmt.Errorf("empty selector")
+	}
+	return c, nil
+}
+
+func (p *selectorParser) attribute() (attrSelector, error) {
+	var a attrSelector
+	p.pos++ // [
+	p.skipSpace()
+	name, err := p.name()
+	if err != nil {
+		return a, err
+	}
+	a.name = strings.ToLower(name)
+	p.skipSpace()
+	if p.done() {
+		return a, fmt.Errorf("unclosed [")
+	}
+	if p.peek() == ']' {
+		p.pos++
+		return a, nil
+	}
+
+	for _, op := range []string{"=", "~=", "|=", "^=", "$=", "*="} {
+		if strings.HasPrefix(p.s[p.pos:], op) {
+			a.op = op
+			p.pos += len(op)
+			break
+		}
+	}
+	if a.op == "" {
+		return a, fmt.Errorf("unexpected %q in attribute selector", p.peek())
+	}
+	p.skipSpace()
+	if p.done() {
+		return a, fmt.Errorf("unclosed [")
+	}
+	if quote := p.peek(); quote == '"' || quote == '\'' {
+		end := strings.IndexByte(p.s[p.pos+1:], quote)
+		if end < 0 {
+			return a, fmt.Errorf("unclosed string")
+		}
+		a.value = p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+	} else if a.value, err = p.name(); err != nil {
+		return a, err
+	}
+	p.skipSpace()
+	// Case-sensitivity flags do not change whether anything matches
+	if !p.done() && (p.peek() == 'i' || p.peek() == 's') {
+		p.pos++
+		p.skipSpace()
+	}
+	if p.done() || p.peek() != ']' {
+		return a, fmt.Errorf("unclosed [")
+	}
+	p.pos++
+	return a, nil
+}
+
+// pseudo skips a pseudo-class or pseudo-element with its arguments
+func (p *selectorParser) pseudo() error {
+	for !p.done() && p.peek() == ':' {
+		p.pos++
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.done() || p.peek() != '(' {
+		return nil
+	}
+	depth := 0
+	for ; !p.done(); p.pos++ {
+		switch p.peek() {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unclosed (")
+}
+
+// matches reports whether n matches any selector of the list
+func (s selector) matches(n *html.Node) bool {
+	for _, c := range s {
+		if c.matches(n, len(c.compounds)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether n matches the selector up to compounds[i]
+func (c complexSelector) matches(n *html.Node, i int) bool {
+	if !c.compounds[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	switch c.combinators[i-1] {
+	case '>':
+		parent := n.Parent
+		return parent != nil && parent.Type == html.ElementNode && c.matches(parent, i-1)
+	case '+':
+		prev := previousElement(n)
+		return prev != nil && c.matches(prev, i-1)
+	case '~':
+		for prev := previousElement(n); prev != nil; prev = previousElement(prev) {
+			if c.matches(prev, i-1) {
+				return true
+			}
+		}
+	default:
+		for parent := n.Parent; parent != nil && parent.Type == html.ElementNode; parent = parent.Parent {
+			if c.matches(parent, i-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c compound) matches(n *html.Node) bool {
+	if n.Type != html.ElementNode || (c.tag != "" && n.Data != c.tag) {
+		return false
+	}
+	if c.id != "" && attr(n, "id") != c.id {
+		return false
+	}
+	classes := strings.Fields(attr(n, "class"))
+	for _, class := range c.classes {
+		if !contains(classes, class) {
+			return false
+		}
+	}
+	for _, a := range c.attrs {
+		if !a.matches(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a attrSelector) matches(n *html.Node) bool {
+	value, ok := lookupAttr(n, a.name)
+	if !ok {
+		return false
+	}
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return value == a.value
+	case "~=":
+		return contains(strings.Fields(value), a.value)
+	case "|=":
+		return value == a.value || strings.HasPrefix(value, a.value+"-")
+	case "^=":
+		return a.value != "" && strings.HasPrefix(value, a.value)
+	case "$=":
+		return a.value != "" && strings.HasSuffix(value, a.value)
+	case "*=":
+		return a.value != "" && strings.Contains(value, a.value)
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupAttr returns the value of attribute name of n
+func lookupAttr(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// attr returns the value of attribute name of n, empty if it is missing
+func attr(n *html.Node, name string) string {
+	value, _ := lookupAttr(n, name)
+	return value
+}
+
+func previousElement(n *html.Node) *html.Node {
+	for prev := n.PrevSibling; prev != nil; prev = prev.PrevSibling {
+		if prev.Type == html.ElementNode {
+			return prev
+		}
+	}
+	return nil
+}
+
+func nextElement(n *html.Node) *html.Node {
+	for next := n.NextSibling; next != nil; next = next.NextSibling {
+		if next.Type == html.ElementNode {
+			return next
+		}
+	}
+	return nil
+}
+
+// findFirst returns the first element below root in document order that
+// matches s
+func findFirst(root *html.Node, s selector) *html.Node {
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && s.matches(c) {
+			return c
+		}
+		if found := findFirst(c, s); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// documentOrder returns the elements below root in document order
+func documentOrder(root *html.Node) []*html.Node {
+	var order []*html.Node
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode {
+				order = append(order, c)
+			}
+			walk(c)
+		}
+	}
+	walk(root)
+	return order
+}
+
+// resolve finds the element an htmx extended selector names relative to
+// elt: this, closest, find, next and previous, or a CSS selector searched
+// in the document rooted at root. A nil element with a nil error means the
+// selector is valid but matches nothing.
+func resolve(root, elt *html.Node, value string) (*html.Node, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "":
+		return nil, fmt.Errorf("empty selector")
+	case "this":
+		return elt, nil
+	case "next":
+		return nextElement(elt), nil
+	case "previous":
+		return previousElement(elt), nil
+	}
+
+	keyword, rest, _ := strings.Cut(value, " ")
+	switch keyword {
+	case "closest", "find", "next", "previous":
+		s, err := parseSelector(rest)
+		if err != nil {
+			return nil, err
+		}
+		return resolveRelative(root, elt, keyword, s), nil
+	}
+
+	s, err := parseSelector(value)
+	if err != nil {
+		return nil, err
+	}
+	return findFirst(root, s), nil
+}
+
+func resolveRelative(root, elt *html.Node, keyword string, s selector) *html.Node {
+	switch keyword {
+	case "closest":
+		for n := elt; n != nil && n.Type == html.ElementNode; n = n.Parent {
+			if s.matches(n) {
+				return n
+			}
+		}
+	case "find":
+		return findFirst(elt, s)
+	case "next", "previous":
+		order := documentOrder(root)
+		at := -1
+		for i, n := range order {
+			if n == elt {
+				at = i
+				break
+			}
+		}
+		if keyword == "next" {
+			for _, n := range order[at+1:] {
+				if s.matches(n) {
+					return n
+				}
+			}
+			return nil
+		}
+		for i := at - 1; i >= 0; i-- {
+			if s.matches(order[i]) {
+				return order[i]
+			}
+		}
+	}
+	return nil
+}
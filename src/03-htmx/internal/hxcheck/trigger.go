@@ -0,0 +1,166 @@
+package hxcheck
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	eventPattern     = regexp.MustCompile(`^[A-Za-z_$][\w:.\-]*$`)
+	intervalPattern  = regexp.MustCompile(`^\d+(\.\d+)?(ms|s|m)?$`)
+	thresholdPattern = regexp.MustCompile(`^(0(\.\d+)?|1(\.0+)?|\.\d+)$`)
+	queueOptions     = []string{"first", "last", "all", "none"}
+)
+
+// trigger is one entry of hx-trigger
+type trigger struct {
+	event string // "every" for polling
+	// selectors are the from:, target: and root: modifiers, which must
+	// resolve like hx-target does
+	selectors []selectorModifier
+}
+
+type selectorModifier struct {
+	name, selector string
+}
+
+// automatic reports whether the trigger fires without the visitor doing
+// anything
+func (t trigger) automatic() bool {
+	switch t.event {
+	case "load", "revealed", "intersect", "every":
+		return true
+	}
+	return false
+}
+
+// parseTriggers parses an hx-trigger value the way htmx 1.9 does: a comma
+// separated list of "every <interval> [filter]" or
+// "<event>[filter] <modifiers>"
+func parseTriggers(value string) ([]trigger, error) {
+	specs, err := splitTopLevel(value, ',')
+	if err != nil {
+		return nil, err
+	}
+	triggers := make([]trigger, 0, len(specs))
+	for _, spec := range specs {
+		t, err := parseTrigger(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers, nil
+}
+
+// splitTopLevel splits s at sep outside of [filters] and quotes
+func splitTopLevel(s string, sep byte) ([]string, error) {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			if depth == 0 {
+				return nil, fmt.Errorf("unbalanced ] in %q", s)
+			}
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	if depth != 0 || quote != 0 {
+		return nil, fmt.Errorf("unclosed filter in %q", s)
+	}
+	return append(parts, s[start:]), nil
+}
+
+func parseTrigger(spec string) (trigger, error) {
+	var t trigger
+	if spec == "" {
+		return t, fmt.Errorf("empty trigger")
+	}
+
+	// The event name runs up to the first space or filter
+	end := strings.IndexAny(spec, " \t\n[")
+	if end < 0 {
+		end = len(spec)
+	}
+	t.event, spec = spec[:end], spec[end:]
+	if t.event == "every" {
+		spec = strings.TrimSpace(spec)
+		interval, rest, _ := strings.Cut(spec, " ")
+		if !intervalPattern.MatchString(interval) {
+			return t, fmt.Errorf("invalid polling interval %q", interval)
+		}
+		spec = rest
+	} else if !eventPattern.MatchString(t.event) {
+		return t, fmt.Errorf("invalid event name %q", t.event)
+	}
+
+	spec = strings.TrimLeft(spec, " \t\n")
+	if strings.HasPrefix(spec, "[") {
+		close := strings.LastIndex(spec, "]")
+		if close < 0 {
+			return t, fmt.Errorf("unclosed filter in %q", spec)
+		}
+		if strings.TrimSpace(spec[1:close]) == "" {
+			return t, fmt.Errorf("empty filter for %s", t.event)
+		}
+		spec = spec[close+1:]
+	}
+
+	tokens := strings.Fields(spec)
+	for i := 0; i < len(tokens); i++ {
+		name, arg, hasArg := strings.Cut(tokens[i], ":")
+		if t.event == "every" {
+			return t, fmt.Errorf("unexpected %q after a polling trigger", tokens[i])
+		}
+		switch name {
+		case "once", "changed", "consume":
+			if hasArg {
+				return t, fmt.Errorf("modifier %s takes no value", name)
+			}
+		case "delay", "throttle":
+			if !intervalPattern.MatchString(arg) {
+				return t, fmt.Errorf("invalid %s %q", name, arg)
+			}
+		case "queue":
+			if !contains(queueOptions, arg) {
+				return t, fmt.Errorf("invalid queue %q: must be one of %s", arg, strings.Join(queueOptions, ", "))
+			}
+		case "threshold":
+			if !thresholdPattern.MatchString(arg) {
+				return t, fmt.Errorf("invalid threshold %q", arg)
+			}
+		case "from", "target", "root":
+			if arg == "" {
+				return t, fmt.Errorf("modifier %s needs a selector", name)
+			}
+			// Relative selectors take the following token as theirs
+			switch arg {
+			case "closest", "find", "next", "previous":
+				if i+1 >= len(tokens) {
+					return t, fmt.Errorf("%s:%s needs a selector", name, arg)
+				}
+				i++
+				arg += " " + tokens[i]
+			}
+			t.selectors = append(t.selectors, selectorModifier{name: name, selector: arg})
+		default:
+			return t, fmt.Errorf("unknown trigger modifier %q", tokens[i])
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,237 @@
+// Package inlineedit serves click-to-edit fields for any record type. A
+// record type registers its editable fields with accessors and validators;
+// the editor then serves the display fragment, the edit form and the save
+// endpoint for every field, loading and updating records through a Storage.
+package inlineedit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// ErrNotFound is returned by a Storage for an unknown record id
+var ErrNotFound = errors.New("record not found")
+
+// Storage loads and updates records by id. Update runs fn on the stored
+// record and stores the result unless fn fails, with no other update of the
+// record in between, so concurrent edits of different fields are all kept.
+type Storage[R any] interface {
+	Load(ctx context.Context, id string) (R, error)
+	Update(ctx context.Context, id string, fn func(record *R) error) error
+}
+
+// Field is an editable field of records of type R
+type Field[R any] struct {
+	// Name identifies the field in URLs
+	Name string
+	// Label is shown to the visitor
+	Label string
+	// InputType is the type of the input element; "text" if empty
+	InputType string
+	// Get reads the field from a record
+	Get func(record R) string
+	// Set writes a validated value to a record
+	Set func(record *R, value string)
+	// Validators run in order on the submitted value, after surrounding
+	// whitespace is trimmed
+	Validators []Validator
+}
+
+// View is what the fragments render for one field of one record
+type View struct {
+	Label     string
+	InputType string
+	Value     string
+	// Errors are the validation messages of a rejected value
+	Errors []string
+	// DisplayURL renders the stored value; cancelling an edit requests it
+	DisplayURL string
+	// EditURL renders the edit form
+	EditURL string
+	// SaveURL accepts the form's "value"
+	SaveURL string
+}
+
+// Renderer renders the fragments of a field. The display fragment must
+// request EditURL when clicked and the form must post to SaveURL.
+type Renderer struct {
+	Display func(view View) templ.Component
+	Form    func(view View) templ.Component
+}
+
+// Editor serves the fields of records of type R under a path prefix:
+//
+//	GET  {prefix}{id}/{field}       display fragment
+//	GET  {prefix}{id}/{field}/edit  edit form
+//	POST {prefix}{id}/{field}       validate and save; display fragment or
+//	                                the form with errors (422)
+type Editor[R any] struct {
+	prefix   string
+	storage  Storage[R]
+	renderer Renderer
+	fields   map[string]Field[R]
+}
+
+// New creates an editor serving under prefix, which must end with a slash
+func New[R any](prefix string, storage Storage[R], renderer Renderer) *Editor[R] {
+	if !strings.HasSuffix(prefix, "/") {
+		panic(fmt.Sprintf("inlineedit: prefix %q must end with a slash", prefix))
+	}
+	return &Editor[R]{
+		prefix:   prefix,
+		storage:  storage,
+		renderer: renderer,
+		fields:   make(map[string]Field[R]),
+	}
+}
+
+// Register adds editable fields. It panics on a duplicate or incomplete
+// field, as registration happens at startup.
+func (e *Editor[R]) Register(fields ...Field[R]) *Editor[R] {
+	for _, f := range fields {
+		if f.Name == "" || f.Get == nil || f.Set == nil {
+			panic(fmt.Sprintf("inlineedit: field %q needs a name, Get and Set", f.Name))
+		}
+		if _, exists := e.fields[f.Name]; exists {
+			panic(fmt.Sprintf("inlineedit: field %q registered twice", f.Name))
+		}
+		if f.InputType == "" {
+			f.InputType = "text"
+		}
+		e.fields[f.Name] = f
+	}
+	return e
+}
+
+// Prefix returns the path prefix the editor serves
+func (e *Editor[R]) Prefix() string {
+	return e.prefix
+}
+
+// DisplayURL returns the URL of the display fragment of a field, for
+// pages that load it
+func (e *Editor[R]) DisplayURL(id, field string) string {
+	return e.prefix + url.PathEscape(id) + "/" + url.PathEscape(field)
+}
+
+// ServeHTTP implements http.Handler
+func (e *Editor[R]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rest, ok := strings.CutPrefix(r.URL.EscapedPath(), e.prefix)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "edit") {
+		http.NotFound(w, r)
+		return
+	}
+	id, err1 := url.PathUnescape(parts[0])
+	name, err2 := url.PathUnescape(parts[1])
+	field, exists := e.fields[name]
+	if err1 != nil || err2 != nil || id == "" || !exists {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		e.edit(w, r, id, field)
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		e.display(w, r, id, field)
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		e.save(w, r, id, field)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (e *Editor[R]) view(id string, field Field[R], value string) View {
+	display := e.DisplayURL(id, field.Name)
+	return View{
+		Label:      field.Label,
+		InputType:  field.InputType,
+		Value:      value,
+		DisplayURL: display,
+		EditURL:    display + "/edit",
+		SaveURL:    display,
+	}
+}
+
+// load reads a record, writing the error response if it cannot
+func (e *Editor[R]) load(w http.ResponseWriter, r *http.Request, id string) (R, bool) {
+	record, err := e.storage.Load(r.Context(), id)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return record, false
+	}
+	if err != nil {
+		log.Printf("inlineedit: failed to load %q: %v", id, err)
+		http.Error(w, "Failed to load record", http.StatusInternalServerError)
+		return record, false
+	}
+	return record, true
+}
+
+func (e *Editor[R]) display(w http.ResponseWriter, r *http.Request, id string, field Field[R]) {
+	record, ok := e.load(w, r, id)
+	if !ok {
+		return
+	}
+	render(w, r, http.StatusOK, e.renderer.Display(e.view(id, field, field.Get(record))))
+}
+
+func (e *Editor[R]) edit(w http.ResponseWriter, r *http.Request, id string, field Field[R]) {
+	record, ok := e.load(w, r, id)
+	if !ok {
+		return
+	}
+	render(w, r, http.StatusOK, e.renderer.Form(e.view(id, field, field.Get(record))))
+}
+
+func (e *Editor[R]) save(w http.ResponseWriter, r *http.Request, id string, field Field[R]) {
+	value := strings.TrimSpace(r.FormValue("value"))
+
+	var errs []string
+	for _, validate := range field.Validators {
+		if err := validate(value); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		view := e.view(id, field, value)
+		view.Errors = errs
+		render(w, r, http.StatusUnprocessableEntity, e.renderer.Form(view))
+		return
+	}
+
+	var record R
+	err := e.storage.Update(r.Context(), id, func(stored *R) error {
+		field.Set(stored, value)
+		record = *stored
+		return nil
+	})
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("inlineedit: failed to save %q: %v", id, err)
+		http.Error(w, "Failed to save record", http.StatusInternalServerError)
+		return
+	}
+	render(w, r, http.StatusOK, e.renderer.Display(e.view(id, field, field.Get(record))))
+}
+
+func render(w http.ResponseWriter, r *http.Request, status int, component templ.Component) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	component.Render(r.Context(), w)
+}
@@ -0,0 +1,199 @@
+package inlineedit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+type contact struct {
+	Name  string
+	Email string
+}
+
+// memoryStorage counts saves so tests can tell a rejected value from one
+// that was stored
+type memoryStorage struct {
+	mutex   sync.Mutex
+	records map[string]contact
+	saves   int
+}
+
+func (s *memoryStorage) Load(_ context.Context, id string) (contact, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	record, exists := s.records[id]
+	if !exists {
+		return contact{}, ErrNotFound
+	}
+	return record, nil
+}
+
+func (s *memoryStorage) Update(_ context.Context, id string, fn func(record *contact) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	record, exists := s.records[id]
+	if !exists {
+		return ErrNotFound
+	}
+	if err := fn(&record); err != nil {
+		return err
+	}
+	s.records[id] = record
+	s.saves++
+	return nil
+}
+
+// textRenderer renders views as plain text, which is enough to tell the
+// fragments apart
+var textRenderer = Renderer{
+	Display: func(view View) templ.Component {
+		return templ.Raw("display:" + view.Value + " edit:" + view.EditURL)
+	},
+	Form: func(view View) templ.Component {
+		return templ.Raw("form:" + view.Value + " save:" + view.SaveURL + " errors:" + strings.Join(view.Errors, ";"))
+	},
+}
+
+func newTestEditor() (*Editor[contact], *memoryStorage) {
+	storage := &memoryStorage{records: map[string]contact{"42": {Name: "Ada", Email: "ada@example.com"}}}
+	editor := New[contact]("/contacts/", storage, textRenderer).Register(
+		Field[contact]{
+			Name:       "name",
+			Get:        func(c contact) string { return c.Name },
+			Set:        func(c *contact, value string) { c.Name = value },
+			Validators: []Validator{Required(), MaxLength(5)},
+		},
+		Field[contact]{
+			Name:       "email",
+			InputType:  "email",
+			Get:        func(c contact) string { return c.Email },
+			Set:        func(c *contact, value string) { c.Email = value },
+			Validators: []Validator{Email()},
+		},
+	)
+	return editor, storage
+}
+
+func serve(editor http.Handler, method, target string, form url.Values) *httptest.ResponseRecorder {
+	var req *http.Request
+	if form == nil {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	rr := httptest.NewRecorder()
+	editor.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestEditor_DisplayAndEdit(t *testing.T) {
+	editor, _ := newTestEditor()
+
+	rr := serve(editor, "GET", "/contacts/42/name", nil)
+	if rr.Code != http.StatusOK || rr.Body.String() != "display:Ada edit:/contacts/42/name/edit" {
+		t.Errorf("display = %d %q", rr.Code, rr.Body.String())
+	}
+
+	rr = serve(editor, "GET", "/contacts/42/email/edit", nil)
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Body.String(), "form:ada@example.com save:/contacts/42/email") {
+		t.Errorf("edit = %d %q", rr.Code, rr.Body.String())
+	}
+}
+
+func TestEditor_Save(t *testing.T) {
+	editor, storage := newTestEditor()
+
+	rr := serve(editor, "POST", "/contacts/42/name", url.Values{"value": {"  Grace "}})
+	if rr.Code != http.StatusOK || !strings.HasPrefix(rr.Body.String(), "display:Grace ") {
+		t.Errorf("save = %d %q", rr.Code, rr.Body.String())
+	}
+	if storage.records["42"].Name != "Grace" || storage.records["42"].Email != "ada@example.com" {
+		t.Errorf("stored %+v", storage.records["42"])
+	}
+
+	// Cancelling re-reads the stored value
+	rr = serve(editor, "GET", "/contacts/42/name", nil)
+	if !strings.HasPrefix(rr.Body.String(), "display:Grace ") {
+		t.Errorf("display after save = %q", rr.Body.String())
+	}
+}
+
+func TestEditor_ConcurrentSavesKeepEveryField(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		editor, storage := newTestEditor()
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() { defer wg.Done(); serve(editor, "POST", "/contacts/42/name", url.Values{"value": {"Grace"}}) }()
+		go func() {
+			defer wg.Done()
+			serve(editor, "POST", "/contacts/42/email", url.Values{"value": {"grace@example.com"}})
+		}()
+		wg.Wait()
+
+		if got := storage.records["42"]; got.Name != "Grace" || got.Email != "grace@example.com" {
+			t.Fatalf("an update was lost: %+v", got)
+		}
+	}
+}
+
+func TestEditor_SaveRejectsInvalidValue(t *testing.T) {
+	editor, storage := newTestEditor()
+
+	tests := []struct {
+		target string
+		value  string
+		errors int
+	}{
+		{"/contacts/42/name", "", 1},
+		{"/contacts/42/name", "Margaret", 1},
+		{"/contacts/42/email", "not an address", 1},
+		{"/contacts/42/email", "Ada <ada@example.com>", 1},
+	}
+	for _, tt := range tests {
+		rr := serve(editor, "POST", tt.target, url.Values{"value": {tt.value}})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s %q: status = %d", tt.target, tt.value, rr.Code)
+		}
+		_, errs, _ := strings.Cut(rr.Body.String(), "errors:")
+		if got := len(strings.Split(errs, ";")); errs == "" || got != tt.errors {
+			t.Errorf("%s %q: errors = %q", tt.target, tt.value, errs)
+		}
+		if !strings.HasPrefix(rr.Body.String(), "form:"+tt.value+" ") {
+			t.Errorf("%s %q: form should keep the submitted value: %q", tt.target, tt.value, rr.Body.String())
+		}
+	}
+	if storage.saves != 0 {
+		t.Errorf("rejected values were saved %d times", storage.saves)
+	}
+}
+
+func TestEditor_NotFound(t *testing.T) {
+	editor, _ := newTestEditor()
+
+	for _, target := range []string{"/contacts/7/name", "/contacts/42/phone", "/contacts/42", "/contacts/42/name/delete", "/other/42/name"} {
+		if rr := serve(editor, "GET", target, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", target, rr.Code)
+		}
+	}
+	if rr := serve(editor, "DELETE", "/contacts/42/name", nil); rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE = %d, want 405", rr.Code)
+	}
+}
+
+func TestEditor_RegisterPanicsOnDuplicate(t *testing.T) {
+	editor, _ := newTestEditor()
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	editor.Register(Field[contact]{Name: "name", Get: func(c contact) string { return c.Name }, Set: func(*contact, string) {}})
+}
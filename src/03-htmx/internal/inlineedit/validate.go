@@ -0,0 +1,57 @@
+package inlineedit
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Validator checks a submitted value. The error's message is shown next to
+// the input, so it should be written for the visitor.
+type Validator func(value string) error
+
+// Required rejects empty values
+func Required() Validator {
+	return func(value string) error {
+		if value == "" {
+			return errors.New("入力してください")
+		}
+		return nil
+	}
+}
+
+// MaxLength rejects values longer than n characters
+func MaxLength(n int) Validator {
+	return func(value string) error {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Errorf("%d文字以内で入力してください", n)
+		}
+		return nil
+	}
+}
+
+// Email rejects values that are not a bare email address. Empty values pass;
+// combine with Required to make the field mandatory.
+func Email() Validator {
+	return func(value string) error {
+		if value == "" {
+			return nil
+		}
+		if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+			return errors.New("メールアドレスの形式が正しくありません")
+		}
+		return nil
+	}
+}
+
+// Pattern rejects non-empty values that do not match re
+func Pattern(re *regexp.Regexp, message string) Validator {
+	return func(value string) error {
+		if value != "" && !re.MatchString(value) {
+			return errors.New(message)
+		}
+		return nil
+	}
+}
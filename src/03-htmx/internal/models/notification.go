@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Notification is one entry of a visitor's notification log. ID increases
+// per visitor and is used as the SSE event id.
+type Notification struct {
+	ID      uint64
+	Message string
+	At      time.Time
+}
@@ -0,0 +1,8 @@
+package models
+
+// Profile is the record edited inline on the forms page
+type Profile struct {
+	Name  string
+	Job   string
+	Email string
+}
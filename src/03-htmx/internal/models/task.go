@@ -0,0 +1,33 @@
+package models
+
+import (
+	"fmt"
+	"htmx-demo/internal/ordering"
+	"sort"
+)
+
+// Task is an item of the sortable task list. Position is a fractional index
+// key; the list is shown sorted by it.
+type Task struct {
+	ID       string
+	Title    string
+	Position string
+}
+
+// newTasks returns the task list of a new session
+func newTasks() []Task {
+	titles := []string{"📝 タスク 1: プロジェクト計画", "💻 タスク 2: 開発作業", "🧪 タスク 3: テスト実行", "🚀 タスク 4: デプロイ"}
+	keys := ordering.Keys(len(titles))
+	tasks := make([]Task, len(titles))
+	for i, title := range titles {
+		tasks[i] = Task{ID: fmt.Sprint(i + 1), Title: title, Position: keys[i]}
+	}
+	return tasks
+}
+
+// SortedTasks returns a copy of the tasks in list order
+func (s *DemoState) SortedTasks() []Task {
+	tasks := append([]Task(nil), s.Tasks...)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Position < tasks[j].Position })
+	return tasks
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// UploadedFile describes a file stored by the upload pipeline
+type UploadedFile struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Hash        string    `json:"hash"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
@@ -17,4 +17,31 @@ type SearchResult struct {
 type TimeResponse struct {
 	CurrentTime string `json:"current_time"`
 	Timestamp   int64  `json:"timestamp"`
-}
\ No newline at end of file
+}
+// DemoState is the demo data of one visitor's session
+type DemoState struct {
+	Users   []User
+	Counter int
+	Tasks   []Task
+	// Profiles are keyed by id
+	Profiles map[string]Profile
+	// TaskVersion increases with every saved reorder, so a reorder based
+	// on an older list is detected
+	TaskVersion int
+}
+
+// NewDemoState returns the initial state of a new session
+func NewDemoState() *DemoState {
+	now := time.Now()
+	return &DemoState{
+		Users: []User{
+			{ID: "1", Name: "田中太郎", Email: "tanaka@example.com", CreatedAt: now},
+			{ID: "2", Name: "佐藤花子", Email: "sato@example.com", CreatedAt: now},
+			{ID: "3", Name: "鈴木一郎", Email: "suzuki@example.com", CreatedAt: now},
+		},
+		Tasks: newTasks(),
+		Profiles: map[string]Profile{
+			"1": {Name: "田中太郎", Job: "ソフトウェアエンジニア", Email: "tanaka@example.com"},
+		},
+	}
+}
@@ -0,0 +1,279 @@
+package notify
+
+import (
+	"context"
+	"htmx-demo/internal/models"
+	"sync"
+	"time"
+)
+
+const (
+	// StartedMessage and StoppedMessage are logged when a schedule changes
+	StartedMessage = "通知システムを開始しました"
+	StoppedMessage = "通知システムを停止しました"
+)
+
+// DefaultMessages are the notifications produced by the schedule, in turn
+var DefaultMessages = []string{
+	"新しいユーザーが登録しました",
+	"システムの健全性チェック完了",
+	"データベースバックアップ完了",
+	"新しいメッセージが届きました",
+	"セキュリティスキャン完了",
+}
+
+// Config configures a Broker
+type Config struct {
+	// Interval is the time between scheduled notifications
+	Interval time.Duration
+	// HistorySize is the number of notifications kept for replay
+	HistorySize int
+	// ClientBuffer is the number of notifications queued per subscriber
+	// before it is dropped
+	ClientBuffer int
+	// Messages are produced by the schedule in turn
+	Messages []string
+}
+
+// DefaultConfig returns the configuration used by the demo
+func DefaultConfig() Config {
+	return Config{
+		Interval:     3 * time.Second,
+		HistorySize:  10,
+		ClientBuffer: 16,
+		Messages:     DefaultMessages,
+	}
+}
+
+// Broker produces notifications per topic, one topic per visitor session,
+// and fans them out to subscribers. Each topic keeps a short history so a
+// reconnecting client can catch up from the last event it received.
+type Broker struct {
+	mutex  sync.Mutex
+	topics map[string]*topic
+	config Config
+	now    func() time.Time
+}
+
+// topic is guarded by the broker's mutex
+type topic struct {
+	lastID      uint64
+	history     []models.Notification
+	subscribers map[*Subscription]struct{}
+	stop        chan struct{} // nil while the schedule is stopped
+	lastSeen    time.Time
+}
+
+// Subscription receives the notifications of one topic. C is closed when
+// the subscriber falls behind by more than the client buffer; the client
+// is expected to reconnect and replay from the last id it received.
+type Subscription struct {
+	C <-chan models.Notification
+
+	ch     chan models.Notification
+	broker *Broker
+	topic  string
+}
+
+// NewBroker creates a broker with the given configuration
+func NewBroker(config Config) *Broker {
+	return &Broker{
+		topics: make(map[string]*topic),
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// topicLocked returns topic name, creating it if needed. The caller must
+// hold the mutex.
+func (b *Broker) topicLocked(name string) *topic {
+	t, exists := b.topics[name]
+	if !exists {
+		t = &topic{subscribers: make(map[*Subscription]struct{})}
+		b.topics[name] = t
+	}
+	t.lastSeen = b.now()
+	return t
+}
+
+// Start starts the schedule of a topic. It returns false if the schedule
+// was already running.
+func (b *Broker) Start(name string) bool {
+	b.mutex.Lock()
+	t := b.topicLocked(name)
+	if t.stop != nil {
+		b.mutex.Unlock()
+		return false
+	}
+	stop := make(chan struct{})
+	t.stop = stop
+	b.publishLocked(t, StartedMessage)
+	b.mutex.Unlock()
+
+	go b.run(name, stop)
+	return true
+}
+
+// Stop stops the schedule of a topic. It returns false if the schedule was
+// not running.
+func (b *Broker) Stop(name string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	t := b.topicLocked(name)
+	if t.stop == nil {
+		return false
+	}
+	close(t.stop)
+	t.stop = nil
+	b.publishLocked(t, StoppedMessage)
+	return true
+}
+
+// Running reports whether the schedule of a topic is running
+func (b *Broker) Running(name string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	t, exists := b.topics[name]
+	return exists && t.stop != nil
+}
+
+// run publishes the configured messages in turn until stop is closed
+func (b *Broker) run(name string, stop chan struct{}) {
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		b.mutex.Lock()
+		t, exists := b.topics[name]
+		// The schedule may have been stopped while waiting for the lock
+		if exists && t.stop == stop && len(b.config.Messages) > 0 {
+			b.publishLocked(t, b.config.Messages[i%len(b.config.Messages)])
+		}
+		b.mutex.Unlock()
+	}
+}
+
+// Publish adds a notification to a topic and sends it to its subscribers
+func (b *Broker) Publish(name, message string) models.Notification {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.publishLocked(b.topicLocked(name), message)
+}
+
+func (b *Broker) publishLocked(t *topic, message string) models.Notification {
+	t.lastID++
+	n := models.Notification{ID: t.lastID, Message: message, At: b.now()}
+
+	t.history = append(t.history, n)
+	if len(t.history) > b.config.HistorySize {
+		t.history = t.history[len(t.history)-b.config.HistorySize:]
+	}
+
+	for sub := range t.subscribers {
+		select {
+		case sub.ch <- n:
+		default:
+			// Dropping a slow subscriber keeps one client from holding
+			// up the others; it catches up from history on reconnect
+			delete(t.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+	return n
+}
+
+// History returns the notifications kept for a topic, oldest first
+func (b *Broker) History(name string) []models.Notification {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	t := b.topicLocked(name)
+	return append([]models.Notification(nil), t.history...)
+}
+
+// Subscribe subscribes to a topic and returns the kept notifications after
+// lastID, which the subscriber should deliver before reading C. A lastID
+// of 0, or one the topic has not reached (e.g. after a server restart),
+// replays the whole history.
+func (b *Broker) Subscribe(name string, lastID uint64) (*Subscription, []models.Notification) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	t := b.topicLocked(name)
+	if lastID > t.lastID {
+		lastID = 0
+	}
+
+	var replay []models.Notification
+	for _, n := range t.history {
+		if n.ID > lastID {
+			replay = append(replay, n)
+		}
+	}
+
+	ch := make(chan models.Notification, b.config.ClientBuffer)
+	sub := &Subscription{C: ch, ch: ch, broker: b, topic: name}
+	t.subscribers[sub] = struct{}{}
+	return sub, replay
+}
+
+// Close unsubscribes. It is safe to call after the subscription was dropped.
+func (s *Subscription) Close() {
+	b := s.broker
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	t, exists := b.topics[s.topic]
+	if !exists {
+		return
+	}
+	if _, subscribed := t.subscribers[s]; subscribed {
+		delete(t.subscribers, s)
+		close(s.ch)
+	}
+	t.lastSeen = b.now()
+}
+
+// Cleanup removes topics without subscribers that have not been used for
+// idle, stopping their schedules, and returns how many were removed
+func (b *Broker) Cleanup(idle time.Duration) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+	removed := 0
+	for name, t := range b.topics {
+		if len(t.subscribers) > 0 || now.Sub(t.lastSeen) < idle {
+			continue
+		}
+		if t.stop != nil {
+			close(t.stop)
+		}
+		delete(b.topics, name)
+		removed++
+	}
+	return removed
+}
+
+// RunCleanup removes idle topics every interval until ctx is done
+func (b *Broker) RunCleanup(ctx context.Context, interval, idle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.Cleanup(idle)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	return Config{
+		Interval:     10 * time.Millisecond,
+		HistorySize:  3,
+		ClientBuffer: 4,
+		Messages:     []string{"first", "second"},
+	}
+}
+
+func receive(t *testing.T, sub *Subscription) string {
+	t.Helper()
+	select {
+	case n, ok := <-sub.C:
+		if !ok {
+			t.Fatal("subscription was closed")
+		}
+		return n.Message
+	case <-time.After(time.Second):
+		t.Fatal("no notification received")
+		return ""
+	}
+}
+
+func TestBroker_Schedule(t *testing.T) {
+	b := NewBroker(testConfig())
+	sub, _ := b.Subscribe("alice", 0)
+	defer sub.Close()
+
+	if !b.Start("alice") || b.Start("alice") {
+		t.Fatal("Start should only succeed while stopped")
+	}
+	for _, want := range []string{StartedMessage, "first", "second", "first"} {
+		if got := receive(t, sub); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+
+	if !b.Stop("alice") || b.Running("alice") {
+		t.Fatal("Stop should stop the schedule")
+	}
+	// A tick may have been published before Stop took the lock
+	for receive(t, sub) != StoppedMessage {
+	}
+	select {
+	case n := <-sub.C:
+		t.Errorf("notification after stop: %+v", n)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBroker_TopicsAreSeparate(t *testing.T) {
+	b := NewBroker(testConfig())
+	bob, _ := b.Subscribe("bob", 0)
+	defer bob.Close()
+
+	b.Publish("alice", "for alice")
+	select {
+	case n := <-bob.C:
+		t.Errorf("bob received alice's notification: %+v", n)
+	default:
+	}
+	if history := b.History("bob"); len(history) != 0 {
+		t.Errorf("bob's history = %+v", history)
+	}
+}
+
+func TestBroker_Replay(t *testing.T) {
+	b := NewBroker(testConfig())
+	for _, message := range []string{"1", "2", "3", "4"} {
+		b.Publish("alice", message)
+	}
+
+	tests := []struct {
+		name   string
+		lastID uint64
+		want   []string
+	}{
+		{name: "new client gets the kept history", lastID: 0, want: []string{"2", "3", "4"}},
+		{name: "reconnect resumes after last id", lastID: 2, want: []string{"3", "4"}},
+		{name: "up to date", lastID: 4, want: nil},
+		{name: "unknown id replays everything", lastID: 99, want: []string{"2", "3", "4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, replay := b.Subscribe("alice", tt.lastID)
+			defer sub.Close()
+
+			if len(replay) != len(tt.want) {
+				t.Fatalf("replay = %+v, want %v", replay, tt.want)
+			}
+			for i, n := range replay {
+				if n.Message != tt.want[i] {
+					t.Errorf("replay[%d] = %q, want %q", i, n.Message, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	b := NewBroker(testConfig())
+	slow, _ := b.Subscribe("alice", 0)
+	fast, _ := b.Subscribe("alice", 0)
+
+	for i := 0; i < 5; i++ {
+		b.Publish("alice", "message")
+		receive(t, fast)
+	}
+
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != testConfig().ClientBuffer {
+		t.Errorf("slow subscriber received %d, want its buffer of %d", received, testConfig().ClientBuffer)
+	}
+	slow.Close()
+
+	b.Publish("alice", "still delivered")
+	if got := receive(t, fast); got != "still delivered" {
+		t.Errorf("fast subscriber got %q", got)
+	}
+	fast.Close()
+}
+
+func TestBroker_Cleanup(t *testing.T) {
+	now := time.Now()
+	b := NewBroker(testConfig())
+	b.now = func() time.Time { return now }
+
+	b.Start("idle")
+	b.Publish("listening", "hello")
+	sub, _ := b.Subscribe("listening", 0)
+	defer sub.Close()
+
+	now = now.Add(time.Hour)
+	if removed := b.Cleanup(time.Minute); removed != 1 {
+		t.Errorf("removed = %d, want 1", removed)
+	}
+	if b.Running("idle") {
+		t.Error("idle topic's schedule is still running")
+	}
+	if history := b.History("listening"); len(history) != 1 {
+		t.Errorf("topic with a subscriber was removed: %+v", history)
+	}
+}
@@ -0,0 +1,108 @@
+// Package ordering keeps user-sorted lists in order with fractional index
+// keys. Every item stores a key and the list is sorted by key, so moving an
+// item only assigns it a new key between its new neighbours and leaves the
+// other items untouched.
+package ordering
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// digits are the base 62 digits of a key, in ascending byte order so keys
+// compare with plain string comparison
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// ErrInvalidKey is returned for keys that are not made of digits or end in
+// the zero digit, which would leave no room before them
+var ErrInvalidKey = errors.New("invalid order key")
+
+// KeyBetween returns a key that sorts after a and before b. An empty a
+// means the start of the list and an empty b the end.
+func KeyBetween(a, b string) (string, error) {
+	if err := validate(a); err != nil {
+		return "", err
+	}
+	if err := validate(b); err != nil {
+		return "", err
+	}
+	if b != "" && a >= b {
+		return "", fmt.Errorf("order key %q is not before %q", a, b)
+	}
+	return midpoint(a, b), nil
+}
+
+// Keys returns n ascending keys for a new list
+func Keys(n int) []string {
+	keys := make([]string, n)
+	prev := ""
+	for i := range keys {
+		prev = midpoint(prev, "")
+		keys[i] = prev
+	}
+	return keys
+}
+
+func validate(key string) error {
+	if key == "" {
+		return nil
+	}
+	if strings.HasSuffix(key, "0") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// midpoint returns a key between a and b, read as base 62 fractions
+// 0.a and 0.b; an empty b stands for 1
+func midpoint(a, b string) string {
+	if b != "" {
+		// Keep the common prefix, reading a as padded with zeros
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(tail(a, n), b[n:])
+		}
+	}
+
+	low := 0
+	if a != "" {
+		low = strings.IndexByte(digits, a[0])
+	}
+	high := len(digits)
+	if b != "" {
+		high = strings.IndexByte(digits, b[0])
+	}
+	if high-low > 1 {
+		return string(digits[(low+high)/2])
+	}
+
+	// The first digits are adjacent. A longer b is greater than its first
+	// digit, which is then between a and b; otherwise continue after a.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[low]) + midpoint(tail(a, 1), "")
+}
+
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return digits[0]
+}
+
+func tail(key string, n int) string {
+	if n < len(key) {
+		return key[n:]
+	}
+	return ""
+}
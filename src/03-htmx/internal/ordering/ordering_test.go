@@ -0,0 +1,129 @@
+package ordering
+
+import (
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestKeyBetween(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"", ""},
+		{"", "1"},
+		{"", "01"},
+		{"V", ""},
+		{"z", ""},
+		{"zz", ""},
+		{"V", "W"},
+		{"V", "V1"},
+		{"V1", "W"},
+		{"a", "a01"},
+		{"Vz", "W"},
+	}
+	for _, tt := range tests {
+		key, err := KeyBetween(tt.a, tt.b)
+		if err != nil {
+			t.Errorf("KeyBetween(%q, %q): %v", tt.a, tt.b, err)
+			continue
+		}
+		if key <= tt.a || (tt.b != "" && key >= tt.b) || validate(key) != nil {
+			t.Errorf("KeyBetween(%q, %q) = %q", tt.a, tt.b, key)
+		}
+	}
+}
+
+func TestKeyBetween_Invalid(t *testing.T) {
+	if _, err := KeyBetween("W", "V"); err == nil {
+		t.Error("expected an error for keys out of order")
+	}
+	if _, err := KeyBetween("V", "V"); err == nil {
+		t.Error("expected an error for equal keys")
+	}
+	if _, err := KeyBetween("V0", ""); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for a trailing zero, got %v", err)
+	}
+	if _, err := KeyBetween("", "V-"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for a non-digit, got %v", err)
+	}
+}
+
+func TestKeyBetween_RepeatedInsertsStayOrdered(t *testing.T) {
+	// Always inserting at the same place is the worst case for key length
+	low, high := "V", "W"
+	for i := 0; i < 100; i++ {
+		key, err := KeyBetween(low, high)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key <= low || key >= high {
+			t.Fatalf("%q is not between %q and %q", key, low, high)
+		}
+		high = key
+	}
+}
+
+func TestKeys(t *testing.T) {
+	keys := Keys(20)
+	if !sort.StringsAreSorted(keys) {
+		t.Errorf("keys are not ascending: %v", keys)
+	}
+}
+
+// apply returns the ids of keys sorted by key after applying changed
+func apply(keys, changed map[string]string) []string {
+	merged := make(map[string]string)
+	for id, key := range keys {
+		merged[id] = key
+	}
+	for id, key := range changed {
+		merged[id] = key
+	}
+	var ids []string
+	for id := range merged {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return merged[ids[i]] < merged[ids[j]] })
+	return ids
+}
+
+func TestReorder(t *testing.T) {
+	initial := Keys(5)
+	keys := map[string]string{"a": initial[0], "b": initial[1], "c": initial[2], "d": initial[3], "e": initial[4]}
+
+	tests := []struct {
+		order   []string
+		changes int
+	}{
+		{[]string{"a", "b", "c", "d", "e"}, 0},
+		{[]string{"e", "a", "b", "c", "d"}, 1},
+		{[]string{"b", "c", "d", "e", "a"}, 1},
+		{[]string{"a", "d", "b", "c", "e"}, 1},
+		{[]string{"b", "a", "d", "c", "e"}, 2},
+		{[]string{"e", "d", "c", "b", "a"}, 4},
+	}
+	for _, tt := range tests {
+		changed, err := Reorder(keys, tt.order)
+		if err != nil {
+			t.Fatalf("Reorder(%v): %v", tt.order, err)
+		}
+		if len(changed) != tt.changes {
+			t.Errorf("Reorder(%v) changed %d keys, want %d", tt.order, len(changed), tt.changes)
+		}
+		got := apply(keys, changed)
+		for i := range got {
+			if got[i] != tt.order[i] {
+				t.Errorf("Reorder(%v) sorts as %v", tt.order, got)
+				break
+			}
+		}
+	}
+}
+
+func TestReorder_Mismatch(t *testing.T) {
+	keys := map[string]string{"a": "F", "b": "V"}
+	for _, order := range [][]string{{"a"}, {"a", "a"}, {"a", "c"}, {"a", "b", "c"}} {
+		if _, err := Reorder(keys, order); !errors.Is(err, ErrMismatch) {
+			t.Errorf("Reorder(%v) = %v, want ErrMismatch", order, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package ordering
+
+import (
+	"errors"
+	"sort"
+)
+
+// ErrMismatch is returned when a submitted order does not contain exactly
+// the items of the list
+var ErrMismatch = errors.New("submitted items do not match the list")
+
+// Reorder returns the new keys needed to put the items of a list, given as
+// item id to key, in the order of ids. Only the items outside the longest
+// run already in ascending key order are re-keyed, so dragging one item to
+// a new place changes one key.
+func Reorder(keys map[string]string, ids []string) (map[string]string, error) {
+	if len(ids) != len(keys) {
+		return nil, ErrMismatch
+	}
+	seen := make(map[string]bool, len(ids))
+	current := make([]string, len(ids))
+	for i, id := range ids {
+		key, exists := keys[id]
+		if !exists || seen[id] {
+			return nil, ErrMismatch
+		}
+		seen[id] = true
+		current[i] = key
+	}
+
+	keep := longestIncreasing(current)
+	changed := make(map[string]string)
+	prev := ""
+	for i, id := range ids {
+		if keep[i] {
+			prev = current[i]
+			continue
+		}
+
+		next := ""
+		for j := i + 1; j < len(ids); j++ {
+			if keep[j] {
+				next = current[j]
+				break
+			}
+		}
+		key, err := KeyBetween(prev, next)
+		if err != nil {
+			return nil, err
+		}
+		changed[id] = key
+		prev = key
+	}
+	return changed, nil
+}
+
+// longestIncreasing marks a longest strictly increasing subsequence of keys
+func longestIncreasing(keys []string) []bool {
+	// tails[k] is the index ending the smallest known run of length k+1
+	var tails []int
+	prev := make([]int, len(keys))
+	for i, key := range keys {
+		k := sort.Search(len(tails), func(k int) bool { return keys[tails[k]] >= key })
+		prev[i] = -1
+		if k > 0 {
+			prev[i] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	keep := make([]bool, len(keys))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			keep[i] = true
+		}
+	}
+	return keep
+}
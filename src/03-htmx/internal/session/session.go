@@ -0,0 +1,119 @@
+package session
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CookieName is the name of the session cookie
+const CookieName = "htmx_demo_session"
+
+// MinSecretLength is the minimum length of the cookie signing secret
+const MinSecretLength = 32
+
+type contextKey struct{}
+
+// Manager issues session IDs in signed cookies
+type Manager struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewManager creates a session manager. Cookies are signed with secret and
+// expire after ttl without a request, like the entries of a Store.
+func NewManager(secret []byte, ttl time.Duration) (*Manager, error) {
+	if len(secret) < MinSecretLength {
+		return nil, fmt.Errorf("session secret must be at least %d bytes", MinSecretLength)
+	}
+	return &Manager{secret: secret, ttl: ttl}, nil
+}
+
+// NewSecret generates a random signing secret. Sessions signed with it do
+// not survive a restart.
+func NewSecret() []byte {
+	secret := make([]byte, MinSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("failed to generate session secret: %v", err))
+	}
+	return secret
+}
+
+// Middleware puts the visitor's session ID into the request context,
+// starting a new session when the request has no cookie or its signature
+// does not verify. The cookie is issued again on every request, so that it
+// expires together with the session entries, which every request keeps
+// alive.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := ""
+		if cookie, err := r.Cookie(CookieName); err == nil {
+			id, _ = m.verify(cookie.Value)
+		}
+		if id == "" {
+			id = newID()
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     CookieName,
+			Value:    m.sign(id),
+			Path:     "/",
+			MaxAge:   int(m.ttl.Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		next.ServeHTTP(w, r.WithContext(WithID(r.Context(), id)))
+	})
+}
+
+// WithID returns a copy of ctx carrying the session ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// IDFromContext returns the session ID set by the middleware, or ""
+func IDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// sign returns the cookie value "<id>.<signature>"
+func (m *Manager) sign(id string) string {
+	return id + "." + m.signature(id)
+}
+
+// verify returns the session ID of a cookie value with a valid signature
+func (m *Manager) verify(value string) (string, error) {
+	id, signature, found := strings.Cut(value, ".")
+	if !found || id == "" {
+		return "", errors.New("malformed session cookie")
+	}
+	if !hmac.Equal([]byte(signature), []byte(m.signature(id))) {
+		return "", errors.New("invalid session cookie signature")
+	}
+	return id, nil
+}
+
+func (m *Manager) signature(id string) string {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newID returns a random session ID
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate session ID: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
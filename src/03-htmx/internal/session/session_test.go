@@ -0,0 +1,174 @@
+package session
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	m, err := NewManager([]byte(strings.Repeat("s", MinSecretLength)), time.Hour)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return m
+}
+
+// serve runs one request through the middleware and returns the session ID
+// seen by the handler and the cookie issued, if any
+func serve(m *Manager, cookie *http.Cookie) (string, *http.Cookie) {
+	var id string
+	handler := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = IDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	for _, c := range rr.Result().Cookies() {
+		if c.Name == CookieName {
+			return id, c
+		}
+	}
+	return id, nil
+}
+
+func TestManager(t *testing.T) {
+	if _, err := NewManager([]byte("short"), time.Hour); err == nil {
+		t.Error("short secrets should be rejected")
+	}
+
+	m := newTestManager(t)
+	id, cookie := serve(m, nil)
+	if id == "" || cookie == nil {
+		t.Fatalf("first request: id=%q cookie=%v", id, cookie)
+	}
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie attributes = %+v", cookie)
+	}
+
+	t.Run("valid cookie keeps the session", func(t *testing.T) {
+		again, reissued := serve(m, cookie)
+		if again != id {
+			t.Errorf("session ID = %q, want %q", again, id)
+		}
+		// The expiry slides with every request, like that of the store
+		if reissued == nil || reissued.Value != cookie.Value || reissued.MaxAge != int(time.Hour.Seconds()) {
+			t.Errorf("reissued cookie = %+v, want %+v", reissued, cookie)
+		}
+	})
+
+	t.Run("tampered cookie starts a new session", func(t *testing.T) {
+		forged := &http.Cookie{Name: CookieName, Value: "someone-else." + strings.SplitN(cookie.Value, ".", 2)[1]}
+		got, reissued := serve(m, forged)
+		if got == "someone-else" || got == id || reissued == nil {
+			t.Errorf("forged cookie accepted: id=%q reissued=%v", got, reissued)
+		}
+
+		for _, value := range []string{"", "no-signature", "." + id} {
+			if got, _ := serve(m, &http.Cookie{Name: CookieName, Value: value}); got == id {
+				t.Errorf("malformed cookie %q resolved to the original session", value)
+			}
+		}
+	})
+
+	t.Run("cookie from another secret is rejected", func(t *testing.T) {
+		other, _ := NewManager([]byte(strings.Repeat("o", MinSecretLength)), time.Hour)
+		if got, _ := serve(other, cookie); got == id {
+			t.Error("cookie verified with a different secret")
+		}
+	})
+}
+
+type counterState struct {
+	count int
+}
+
+func TestStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore(time.Minute, func() *counterState { return &counterState{} })
+	store.now = func() time.Time { return now }
+
+	increment := func(id string) int {
+		var count int
+		store.With(id, func(s *counterState) {
+			s.count++
+			count = s.count
+		})
+		return count
+	}
+
+	t.Run("sessions are isolated", func(t *testing.T) {
+		increment("a")
+		increment("a")
+		if got := increment("b"); got != 1 {
+			t.Errorf("session b count = %d, want 1", got)
+		}
+		if got := increment("a"); got != 3 {
+			t.Errorf("session a count = %d, want 3", got)
+		}
+		if got := increment(""); got != 1 || increment("") != 1 {
+			t.Error("requests without a session must not share state")
+		}
+	})
+
+	t.Run("idle sessions expire", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		increment("a") // keeps a alive
+		now = now.Add(45 * time.Second)
+
+		if removed := store.Cleanup(); removed != 1 {
+			t.Errorf("Cleanup removed %d sessions, want 1", removed)
+		}
+		if store.Len() != 1 {
+			t.Errorf("Len = %d, want 1", store.Len())
+		}
+		if got := increment("a"); got != 5 {
+			t.Errorf("active session lost its state: count = %d", got)
+		}
+
+		now = now.Add(2 * time.Minute)
+		if got := increment("a"); got != 1 {
+			t.Errorf("expired session kept its state: count = %d", got)
+		}
+	})
+
+	t.Run("expired sessions are reported", func(t *testing.T) {
+		var expired []string
+		store.OnExpire(func(id string) { expired = append(expired, id) })
+
+		increment("d")
+		increment("e")
+		now = now.Add(2 * time.Minute)
+		increment("d") // expires on access
+		store.Cleanup()
+
+		sort.Strings(expired)
+		if fmt.Sprint(expired) != "[a d e]" {
+			t.Errorf("expired = %v, want [a d e]", expired)
+		}
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func() { defer wg.Done(); increment("c") }()
+			go func() { defer wg.Done(); store.Cleanup() }()
+		}
+		wg.Wait()
+		if got := increment("c"); got != 51 {
+			t.Errorf("count = %d, want 51", got)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps per-session state of type T in memory. State expires after
+// ttl without access and is recreated on the next request.
+type Store[T any] struct {
+	mutex    sync.Mutex
+	entries  map[string]*entry[T]
+	ttl      time.Duration
+	newState func() *T
+	now      func() time.Time
+	onExpire []func(id string)
+}
+
+// entry has its own lock so one visitor's request never waits for another's
+type entry[T any] struct {
+	mutex     sync.Mutex
+	state     *T
+	expiresAt time.Time
+}
+
+// NewStore creates a store that initializes new sessions with newState
+func NewStore[T any](ttl time.Duration, newState func() *T) *Store[T] {
+	return &Store[T]{
+		entries:  make(map[string]*entry[T]),
+		ttl:      ttl,
+		newState: newState,
+		now:      time.Now,
+	}
+}
+
+// With runs fn with exclusive access to the state of session id. Requests
+// without a session get a throwaway state.
+func (s *Store[T]) With(id string, fn func(state *T)) {
+	if id == "" {
+		fn(s.newState())
+		return
+	}
+
+	e := s.acquire(id)
+	defer e.mutex.Unlock()
+	fn(e.state)
+}
+
+// acquire returns the locked entry of session id, creating it if it does
+// not exist or has expired
+func (s *Store[T]) acquire(id string) *entry[T] {
+	s.mutex.Lock()
+	now := s.now()
+	e, exists := s.entries[id]
+	expired := exists && now.After(e.expiresAt)
+	if !exists || expired {
+		e = &entry[T]{state: s.newState()}
+		s.entries[id] = e
+	}
+	e.expiresAt = now.Add(s.ttl)
+	hooks := s.onExpire
+	s.mutex.Unlock()
+
+	if expired {
+		runHooks(hooks, []string{id})
+	}
+	e.mutex.Lock()
+	return e
+}
+
+// OnExpire registers fn to be called with the ID of every session whose
+// state expires, so data kept elsewhere for the session can go with it
+func (s *Store[T]) OnExpire(fn func(id string)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.onExpire = append(s.onExpire, fn)
+}
+
+func runHooks(hooks []func(id string), ids []string) {
+	for _, id := range ids {
+		for _, fn := range hooks {
+			fn(id)
+		}
+	}
+}
+
+// Len returns the number of stored sessions, expired ones included
+func (s *Store[T]) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.entries)
+}
+
+// Cleanup removes expired sessions and returns how many were removed
+func (s *Store[T]) Cleanup() int {
+	s.mutex.Lock()
+	now := s.now()
+	var removed []string
+	for id, e := range s.entries {
+		if now.After(e.expiresAt) {
+			delete(s.entries, id)
+			removed = append(removed, id)
+		}
+	}
+	hooks := s.onExpire
+	s.mutex.Unlock()
+
+	runHooks(hooks, removed)
+	return len(removed)
+}
+
+// RunCleanup removes expired sessions every interval until ctx is done
+func (s *Store[T]) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup()
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package taxonomy
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+//go:embed default.json
+var defaultData []byte
+
+// Default returns the taxonomy bundled with the demo
+func Default() *Taxonomy {
+	t, err := Decode(bytes.NewReader(defaultData), JSON)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Store holds the current taxonomy. With a file, JSON or YAML, it loads
+// the tree from the file, writes edits back to it and can reload it when it
+// changes on disk; without one, edits last until the server stops.
+type Store struct {
+	mutex   sync.RWMutex
+	current *Taxonomy
+	file    string
+	modTime time.Time
+}
+
+// NewStore creates a store backed by file. A missing file is created with
+// the default taxonomy; an empty file name keeps the taxonomy in memory.
+func NewStore(file string) (*Store, error) {
+	s := &Store{current: Default(), file: file}
+	if file == "" {
+		return s, nil
+	}
+
+	if _, err := os.Stat(file); errors.Is(err, fs.ErrNotExist) {
+		if err := s.write(s.current); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Current returns the current taxonomy
+func (s *Store) Current() *Taxonomy {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.current
+}
+
+// Reload reads the file again. On error the current taxonomy is kept.
+func (s *Store) Reload() error {
+	if s.file == "" {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	f, err := os.Open(s.file)
+	if err != nil {
+		return fmt.Errorf("failed to open taxonomy: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to open taxonomy: %w", err)
+	}
+	t, err := Decode(f, FormatOf(s.file))
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", s.file, err)
+	}
+	s.current = t
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// Update replaces the taxonomy with the result of fn and saves it. The
+// taxonomy is unchanged if fn or saving fails.
+func (s *Store) Update(fn func(t *Taxonomy) (*Taxonomy, error)) (*Taxonomy, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	t, err := fn(s.current)
+	if err != nil {
+		return nil, err
+	}
+	if s.file != "" {
+		if err := s.write(t); err != nil {
+			return nil, err
+		}
+	}
+	s.current = t
+	return t, nil
+}
+
+// write saves t to the file through a temporary file, so a concurrent
+// reload never reads a partial tree. The caller must hold the mutex or
+// have the store to itself.
+func (s *Store) write(t *Taxonomy) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.file), ".taxonomy-*")
+	if err != nil {
+		return fmt.Errorf("failed to save taxonomy: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := t.Encode(tmp, FormatOf(s.file)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save taxonomy: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save taxonomy: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.file); err != nil {
+		return fmt.Errorf("failed to save taxonomy: %w", err)
+	}
+
+	if info, err := os.Stat(s.file); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// changed reports whether the file was modified since it was last read or
+// written
+func (s *Store) changed() bool {
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return false
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return !info.ModTime().Equal(s.modTime)
+}
+
+// Watch reloads the file every interval when it was edited by hand, until
+// ctx is done. Invalid edits are logged and the last good tree is kept.
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	if s.file == "" {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !s.changed() {
+				continue
+			}
+			if err := s.Reload(); err != nil {
+				log.Printf("Keeping the current taxonomy: %v", err)
+				// Do not retry the same broken file every tick
+				s.mutex.Lock()
+				if info, err := os.Stat(s.file); err == nil {
+					s.modTime = info.ModTime()
+				}
+				s.mutex.Unlock()
+				continue
+			}
+			log.Printf("Reloaded taxonomy from %s", s.file)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package taxonomy holds a category tree of arbitrary depth for chained
+// selects. A Taxonomy is immutable; edits return a modified copy, so
+// readers can keep using the tree they got while an admin edits it.
+package taxonomy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Separator joins node ids into a path
+const Separator = "/"
+
+var (
+	// ErrNotFound is returned for a path that does not exist
+	ErrNotFound = errors.New("category not found")
+	// ErrInvalid is returned for a tree or node that breaks the rules
+	ErrInvalid = errors.New("invalid taxonomy")
+)
+
+// idPattern keeps ids usable in paths, URLs and form values
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Node is a category. Nodes without children are leaves.
+type Node struct {
+	ID       string  `json:"id" yaml:"id"`
+	Label    string  `json:"label" yaml:"label"`
+	Children []*Node `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// Taxonomy is a tree of categories
+type Taxonomy struct {
+	roots []*Node
+}
+
+// Format is the encoding of a taxonomy file
+type Format int
+
+const (
+	JSON Format = iota
+	YAML
+)
+
+// FormatOf returns the format of file by its extension; JSON unless it
+// ends in .yaml or .yml
+func FormatOf(file string) Format {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return YAML
+	}
+	return JSON
+}
+
+// Decode reads and validates a list of root nodes
+func Decode(r io.Reader, format Format) (*Taxonomy, error) {
+	var roots []*Node
+	var err error
+	if format == YAML {
+		err = yaml.NewDecoder(r).Decode(&roots)
+	} else {
+		err = json.NewDecoder(r).Decode(&roots)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err := validate(roots, ""); err != nil {
+		return nil, err
+	}
+	return &Taxonomy{roots: roots}, nil
+}
+
+func validate(nodes []*Node, parent string) error {
+	seen := make(map[string]bool)
+	for _, n := range nodes {
+		if n == nil {
+			return fmt.Errorf("%w: empty node under %q", ErrInvalid, parent)
+		}
+		if err := validateNode(n.ID, n.Label); err != nil {
+			return fmt.Errorf("%w (under %q)", err, parent)
+		}
+		if seen[n.ID] {
+			return fmt.Errorf("%w: duplicate id %q under %q", ErrInvalid, n.ID, parent)
+		}
+		seen[n.ID] = true
+		if err := validate(n.Children, JoinPath(parent, n.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateNode(id, label string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w: id %q must be lowercase letters, digits, - or _", ErrInvalid, id)
+	}
+	if strings.TrimSpace(label) == "" {
+		return fmt.Errorf("%w: id %q has no label", ErrInvalid, id)
+	}
+	return nil
+}
+
+// Encode writes the tree in the format Decode reads
+func (t *Taxonomy) Encode(w io.Writer, format Format) error {
+	if format == YAML {
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(t.roots); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(t.roots)
+}
+
+// JoinPath appends id to path
+func JoinPath(path, id string) string {
+	if path == "" {
+		return id
+	}
+	return path + Separator + id
+}
+
+// SplitPath splits a path into ids; the empty path is the root
+func SplitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, Separator)
+}
+
+// Roots returns the top-level categories
+func (t *Taxonomy) Roots() []*Node {
+	return t.roots
+}
+
+// Resolve returns the nodes along path, from the root down
+func (t *Taxonomy) Resolve(path string) ([]*Node, error) {
+	var trail []*Node
+	level := t.roots
+	for _, id := range SplitPath(path) {
+		n := find(level, id)
+		if n == nil {
+			return nil, fmt.Errorf("%w: %q", ErrNotFound, path)
+		}
+		trail = append(trail, n)
+		level = n.Children
+	}
+	return trail, nil
+}
+
+// Children returns the options below path; the empty path gives the roots
+func (t *Taxonomy) Children(path string) ([]*Node, error) {
+	trail, err := t.Resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(trail) == 0 {
+		return t.roots, nil
+	}
+	return trail[len(trail)-1].Children, nil
+}
+
+func find(nodes []*Node, id string) *Node {
+	for _, n := range nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// edit copies the nodes along path and calls fn with the copied children
+// slice of the node at path, the roots for the empty path
+func (t *Taxonomy) edit(path string, fn func(children *[]*Node) error) (*Taxonomy, error) {
+	roots := append([]*Node(nil), t.roots...)
+	level := &roots
+	for _, id := range SplitPath(path) {
+		i := -1
+		for j, n := range *level {
+			if n.ID == id {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrNotFound, path)
+		}
+		copied := *(*level)[i]
+		copied.Children = append([]*Node(nil), copied.Children...)
+		(*level)[i] = &copied
+		level = &copied.Children
+	}
+	if err := fn(level); err != nil {
+		return nil, err
+	}
+	return &Taxonomy{roots: roots}, nil
+}
+
+// Add returns a copy with a new leaf id under parent
+func (t *Taxonomy) Add(parent, id, label string) (*Taxonomy, error) {
+	if err := validateNode(id, label); err != nil {
+		return nil, err
+	}
+	return t.edit(parent, func(children *[]*Node) error {
+		if find(*children, id) != nil {
+			return fmt.Errorf("%w: %q already exists", ErrInvalid, JoinPath(parent, id))
+		}
+		*children = append(*children, &Node{ID: id, Label: strings.TrimSpace(label)})
+		return nil
+	})
+}
+
+// Rename returns a copy with the node at path relabelled
+func (t *Taxonomy) Rename(path, label string) (*Taxonomy, error) {
+	ids := SplitPath(path)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, path)
+	}
+	if err := validateNode(ids[len(ids)-1], label); err != nil {
+		return nil, err
+	}
+	parent := strings.Join(ids[:len(ids)-1], Separator)
+	return t.edit(parent, func(children *[]*Node) error {
+		for i, n := range *children {
+			if n.ID == ids[len(ids)-1] {
+				renamed := *n
+				renamed.Label = strings.TrimSpace(label)
+				(*children)[i] = &renamed
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %q", ErrNotFound, path)
+	})
+}
+
+// Remove returns a copy without the node at path and its descendants
+func (t *Taxonomy) Remove(path string) (*Taxonomy, error) {
+	ids := SplitPath(path)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, path)
+	}
+	parent := strings.Join(ids[:len(ids)-1], Separator)
+	return t.edit(parent, func(children *[]*Node) error {
+		for i, n := range *children {
+			if n.ID == ids[len(ids)-1] {
+				*children = append((*children)[:i:i], (*children)[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %q", ErrNotFound, path)
+	})
+}
@@ -0,0 +1,236 @@
+package taxonomy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func labels(nodes []*Node) string {
+	var s []string
+	for _, n := range nodes {
+		s = append(s, n.Label)
+	}
+	return strings.Join(s, ",")
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tests := []struct{ name, data string }{
+		{"not a list", `{"id": "a", "label": "A"}`},
+		{"bad id", `[{"id": "A B", "label": "A"}]`},
+		{"no label", `[{"id": "a", "label": " "}]`},
+		{"duplicate id", `[{"id": "a", "label": "A", "children": [{"id": "b", "label": "B"}, {"id": "b", "label": "B"}]}]`},
+		{"null node", `[null]`},
+	}
+	for _, tt := range tests {
+		if _, err := Decode(strings.NewReader(tt.data), JSON); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestDecode_YAML(t *testing.T) {
+	data := `
+- id: a
+  label: A
+  children:
+    - id: b
+      label: B
+`
+	tx, err := Decode(strings.NewReader(data), YAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	children, err := tx.Children("a")
+	if err != nil || labels(children) != "B" {
+		t.Errorf("Children(a) = %q, %v", labels(children), err)
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Encode(&buf, YAML); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Decode(&buf, YAML)
+	if err != nil {
+		t.Fatalf("encoded YAML does not decode: %v", err)
+	}
+	if trail, err := again.Resolve("a/b"); err != nil || len(trail) != 2 {
+		t.Errorf("Resolve(a/b) after round trip = %v, %v", trail, err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tx := Default()
+
+	trail, err := tx.Resolve("technology/programming/go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := labels(trail); got != "テクノロジー,プログラミング,Go" {
+		t.Errorf("trail = %q", got)
+	}
+
+	for _, path := range []string{"technology/go", "unknown", "technology//go", "technology/"} {
+		if _, err := tx.Resolve(path); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Resolve(%q): expected ErrNotFound, got %v", path, err)
+		}
+	}
+
+	roots, err := tx.Children("")
+	if err != nil || labels(roots) != "テクノロジー,ビジネス,デザイン" {
+		t.Errorf("Children(\"\") = %q, %v", labels(roots), err)
+	}
+}
+
+func TestEdits_LeaveOriginalUnchanged(t *testing.T) {
+	original := Default()
+
+	added, err := original.Add("business/sales", "b2b", "法人営業")
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed, err := added.Rename("business", "ビジネス全般")
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed, err := renamed.Remove("technology/programming")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := removed.Resolve("business/sales/b2b"); err != nil {
+		t.Errorf("added node missing: %v", err)
+	}
+	if trail, _ := removed.Resolve("business"); trail[0].Label != "ビジネス全般" {
+		t.Errorf("label = %q", trail[0].Label)
+	}
+	if _, err := removed.Resolve("technology/programming/go"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("removed subtree still resolves: %v", err)
+	}
+
+	if _, err := original.Resolve("business/sales/b2b"); err == nil {
+		t.Error("Add changed the original")
+	}
+	if trail, _ := original.Resolve("business"); trail[0].Label != "ビジネス" {
+		t.Error("Rename changed the original")
+	}
+	if _, err := original.Resolve("technology/programming/go"); err != nil {
+		t.Error("Remove changed the original")
+	}
+}
+
+func TestEdits_Invalid(t *testing.T) {
+	tx := Default()
+
+	if _, err := tx.Add("technology", "cloud", "クラウド"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("duplicate id: expected ErrInvalid, got %v", err)
+	}
+	if _, err := tx.Add("technology", "a/b", "A"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("id with separator: expected ErrInvalid, got %v", err)
+	}
+	if _, err := tx.Add("unknown", "a", "A"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown parent: expected ErrNotFound, got %v", err)
+	}
+	if _, err := tx.Rename("technology", ""); !errors.Is(err, ErrInvalid) {
+		t.Errorf("empty label: expected ErrInvalid, got %v", err)
+	}
+	if _, err := tx.Remove(""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("remove root: expected ErrNotFound, got %v", err)
+	}
+	if _, err := tx.Remove("technology/unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("remove unknown: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_PersistsEdits(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "taxonomy.yaml")
+
+	store, err := NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Fatalf("missing file was not created: %v", err)
+	}
+	if _, err := store.Update(func(tx *Taxonomy) (*Taxonomy, error) {
+		return tx.Add("", "science", "サイエンス")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Current().Resolve("science"); err != nil {
+		t.Errorf("edit was not saved: %v", err)
+	}
+}
+
+func TestStore_FailedUpdateKeepsTree(t *testing.T) {
+	store, err := NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := store.Current()
+	if _, err := store.Update(func(tx *Taxonomy) (*Taxonomy, error) {
+		return tx.Remove("unknown")
+	}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if store.Current() != before {
+		t.Error("failed update replaced the taxonomy")
+	}
+}
+
+func TestStore_WatchReloadsEditedFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "taxonomy.json")
+	if err := os.WriteFile(file, []byte(`[{"id": "a", "label": "A"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewStore(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go store.Watch(ctx, 10*time.Millisecond)
+
+	write := func(data string, at time.Time) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Make the change visible on filesystems with coarse timestamps
+		if err := os.Chtimes(file, at, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if labels(store.Current().Roots()) == want {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("roots = %q, want %q", labels(store.Current().Roots()), want)
+	}
+
+	write(`[{"id": "b", "label": "B"}]`, time.Now().Add(time.Hour))
+	waitFor("B")
+
+	// A broken edit is ignored until the file is fixed
+	write(`[{"id": "c"`, time.Now().Add(2*time.Hour))
+	time.Sleep(50 * time.Millisecond)
+	waitFor("B")
+	write(`[{"id": "c", "label": "C"}]`, time.Now().Add(3*time.Hour))
+	waitFor("C")
+}
@@ -0,0 +1,196 @@
+package toast
+
+import (
+	"htmx-demo/internal/session"
+	"log"
+	"net/http"
+	"shared/htmx"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// Elements of the layout the notifications are swapped into
+const (
+	ToastsTarget = "#toasts"
+	ModalTarget  = "#modal"
+)
+
+// EventNotify carries the notifications of responses that are not swapped,
+// such as errors. Its detail is a Payload.
+const EventNotify = "notify"
+
+// Payload is the detail of EventNotify
+type Payload struct {
+	Toasts []Toast `json:"toasts,omitempty"`
+	Modal  *Modal  `json:"modal,omitempty"`
+}
+
+// Flash keeps the notifications of a visitor that no response delivered,
+// e.g. those of a form post answered with a redirect, for the next page
+type Flash struct {
+	Toasts []Toast
+	Modal  *Modal
+}
+
+// NewFlash returns an empty Flash for a new session
+func NewFlash() *Flash {
+	return &Flash{}
+}
+
+// Views render delivered notifications
+type Views struct {
+	Toasts func(toasts []Toast) templ.Component
+	Modal  func(modal Modal) templ.Component
+}
+
+// Notifier delivers the queue of each request:
+//   - successful htmx responses carry it as out-of-band swaps into
+//     ToastsTarget and ModalTarget
+//   - other htmx responses, which htmx does not swap, trigger EventNotify
+//   - redirects, including HX-Redirect and HX-Location, and responses that
+//     are not HTML keep it as flash for the next page, whose layout
+//     renders it by taking the queue from the context
+type Notifier struct {
+	flash *session.Store[Flash]
+	views Views
+}
+
+// NewNotifier creates a Notifier keeping flash messages in flash
+func NewNotifier(flash *session.Store[Flash], views Views) *Notifier {
+	return &Notifier{flash: flash, views: views}
+}
+
+// Middleware gives each request a queue. It must run after the session
+// middleware.
+func (n *Notifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, queue := NewContext(r.Context())
+		r = r.WithContext(ctx)
+		n.restore(r, queue)
+
+		rw := &responseWriter{ResponseWriter: w, notifier: n, r: r, queue: queue}
+		next.ServeHTTP(rw, r)
+		rw.finish()
+	})
+}
+
+// restore moves the flash of the visitor into queue
+func (n *Notifier) restore(r *http.Request, queue *Queue) {
+	id := session.IDFromContext(r.Context())
+	if id == "" {
+		return
+	}
+	n.flash.With(id, func(flash *Flash) {
+		for _, t := range flash.Toasts {
+			queue.Add(t)
+		}
+		if flash.Modal != nil {
+			queue.Open(*flash.Modal)
+		}
+		*flash = Flash{}
+	})
+}
+
+// save keeps what is left in queue as flash of the visitor
+func (n *Notifier) save(r *http.Request, queue *Queue) {
+	id := session.IDFromContext(r.Context())
+	toasts, modal := queue.Take()
+	if id == "" {
+		return
+	}
+	n.flash.With(id, func(flash *Flash) {
+		flash.Toasts = append(flash.Toasts, toasts...)
+		if modal != nil {
+			flash.Modal = modal
+		}
+	})
+}
+
+// responseWriter picks how the queue is delivered once the handler starts
+// its response
+type responseWriter struct {
+	http.ResponseWriter
+	notifier    *Notifier
+	r           *http.Request
+	queue       *Queue
+	wroteHeader bool
+	// oob appends the queue to the body when the handler returns
+	oob bool
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.deliver(status)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// deliver decides how the queue reaches the visitor while headers can
+// still be set
+func (w *responseWriter) deliver(status int) {
+	if !htmx.IsHTMX(w.r) || htmx.HistoryRestore(w.r) {
+		return
+	}
+	header := w.Header()
+	if header.Get(htmx.HeaderRedirect) != "" || header.Get(htmx.HeaderLocation) != "" || header.Get(htmx.HeaderRefresh) == "true" {
+		// The page is left; the flash is shown on the next one
+		return
+	}
+	html := strings.HasPrefix(header.Get("Content-Type"), "text/html")
+	if status >= 200 && status < 300 && status != http.StatusNoContent && html {
+		w.oob = true
+		return
+	}
+
+	toasts, modal := w.queue.Take()
+	if len(toasts) == 0 && modal == nil {
+		return
+	}
+	if err := htmx.TriggerEventWithDetail(w, EventNotify, Payload{Toasts: toasts, Modal: modal}); err != nil {
+		log.Printf("Failed to send notifications: %v", err)
+	}
+}
+
+// finish delivers the rest of the queue after the handler returned
+func (w *responseWriter) finish() {
+	if w.queue.Empty() {
+		return
+	}
+	if !w.wroteHeader && htmx.IsHTMX(w.r) {
+		// The handler left the body empty; the notifications are the body
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.oob {
+		w.notifier.save(w.r, w.queue)
+		return
+	}
+
+	toasts, modal := w.queue.Take()
+	oob := htmx.NewOOBWriter(nil)
+	if len(toasts) > 0 {
+		oob.Swap(htmx.SwapBeforeEnd, ToastsTarget, w.notifier.views.Toasts(toasts))
+	}
+	if modal != nil {
+		oob.Swap(htmx.SwapInnerHTML, ModalTarget, w.notifier.views.Modal(*modal))
+	}
+	if err := oob.Render(w.r.Context(), w.ResponseWriter); err != nil {
+		log.Printf("Failed to render notifications: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package toast lets handlers tell the visitor how an action went. Toasts
+// are short notifications stacked in a corner of the page; a modal is a
+// dialog the server opens. Handlers append to the queue of their request
+// and the Notifier middleware delivers it with the response.
+package toast
+
+import (
+	"context"
+	"sync"
+)
+
+// Level is the kind of a toast
+type Level string
+
+const (
+	Info    Level = "info"
+	Success Level = "success"
+	Error   Level = "error"
+)
+
+// Action is a link offered with a notification
+type Action struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// Toast is a short notification
+type Toast struct {
+	Level   Level   `json:"level"`
+	Message string  `json:"message"`
+	Action  *Action `json:"action,omitempty"`
+}
+
+// WithAction returns t offering a link labeled label to url
+func (t Toast) WithAction(label, url string) Toast {
+	t.Action = &Action{Label: label, URL: url}
+	return t
+}
+
+// NewInfo returns an info toast
+func NewInfo(message string) Toast { return Toast{Level: Info, Message: message} }
+
+// NewSuccess returns a success toast
+func NewSuccess(message string) Toast { return Toast{Level: Success, Message: message} }
+
+// NewError returns an error toast
+func NewError(message string) Toast { return Toast{Level: Error, Message: message} }
+
+// Modal is a dialog opened by the server
+type Modal struct {
+	Title   string  `json:"title"`
+	Message string  `json:"message"`
+	Action  *Action `json:"action,omitempty"`
+}
+
+// Queue collects the notifications of a request. A nil queue discards
+// them, so handlers work without the middleware, e.g. in tests.
+type Queue struct {
+	mu     sync.Mutex
+	toasts []Toast
+	modal  *Modal
+}
+
+// Add appends a toast
+func (q *Queue) Add(t Toast) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.toasts = append(q.toasts, t)
+}
+
+// Open opens a modal, replacing one opened earlier in the request
+func (q *Queue) Open(m Modal) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.modal = &m
+}
+
+// Take removes and returns the queued notifications. modal is nil if none
+// was opened.
+func (q *Queue) Take() (toasts []Toast, modal *Modal) {
+	if q == nil {
+		return nil, nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	toasts, modal = q.toasts, q.modal
+	q.toasts, q.modal = nil, nil
+	return toasts, modal
+}
+
+// Empty reports whether nothing is queued
+func (q *Queue) Empty() bool {
+	if q == nil {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.toasts) == 0 && q.modal == nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying a new queue
+func NewContext(ctx context.Context) (context.Context, *Queue) {
+	q := &Queue{}
+	return context.WithValue(ctx, contextKey{}, q), q
+}
+
+// FromContext returns the queue of the request, or nil outside the
+// middleware
+func FromContext(ctx context.Context) *Queue {
+	q, _ := ctx.Value(contextKey{}).(*Queue)
+	return q
+}
+
+// Add appends a toast to the queue of the request
+func Add(ctx context.Context, t Toast) {
+	FromContext(ctx).Add(t)
+}
+
+// Open opens a modal with the response to the request
+func Open(ctx context.Context, m Modal) {
+	FromContext(ctx).Open(m)
+}
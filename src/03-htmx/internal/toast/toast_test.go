@@ -0,0 +1,148 @@
+package toast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"htmx-demo/internal/session"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// testViews render notifications as plain markers
+var testViews = Views{
+	Toasts: func(toasts []Toast) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			for _, t := range toasts {
+				fmt.Fprintf(w, "<toast %s>%s</toast>", t.Level, t.Message)
+			}
+			return nil
+		})
+	},
+	Modal: func(m Modal) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "<modal>%s</modal>", m.Title)
+			return err
+		})
+	},
+}
+
+// serve runs handler behind the notifier for one visitor
+func serve(n *Notifier, req *http.Request, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	req = req.WithContext(session.WithID(req.Context(), "visitor"))
+	rr := httptest.NewRecorder()
+	n.Middleware(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func htmxRequest(method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	req.Header.Set("HX-Request", "true")
+	return req
+}
+
+func newTestNotifier() *Notifier {
+	return NewNotifier(session.NewStore(time.Hour, NewFlash), testViews)
+}
+
+func TestNotifier_SwapsIntoSuccessfulResponses(t *testing.T) {
+	n := newTestNotifier()
+	rr := serve(n, htmxRequest("POST", "/save"), func(w http.ResponseWriter, r *http.Request) {
+		Add(r.Context(), NewSuccess("保存しました"))
+		Open(r.Context(), Modal{Title: "確認"})
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<p>saved</p>")
+	})
+
+	body := rr.Body.String()
+	for _, want := range []string{
+		"<p>saved</p>",
+		`<div hx-swap-oob="beforeend:#toasts"><toast success>保存しました</toast></div>`,
+		`<div hx-swap-oob="innerHTML:#modal"><modal>確認</modal></div>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("want %s in: %s", want, body)
+		}
+	}
+	if got := rr.Header().Get("HX-Trigger"); got != "" {
+		t.Errorf("HX-Trigger = %q", got)
+	}
+}
+
+func TestNotifier_EmptyResponseCarriesNotifications(t *testing.T) {
+	n := newTestNotifier()
+	rr := serve(n, htmxRequest("POST", "/delete"), func(w http.ResponseWriter, r *http.Request) {
+		Add(r.Context(), NewInfo("何も選択されていません"))
+	})
+
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "<toast info>何も選択されていません</toast>") {
+		t.Errorf("status %d, body %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestNotifier_TriggersEventForErrors(t *testing.T) {
+	n := newTestNotifier()
+	rr := serve(n, htmxRequest("POST", "/save"), func(w http.ResponseWriter, r *http.Request) {
+		Add(r.Context(), NewError("失敗しました").WithAction("再試行", "/retry"))
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	})
+
+	var events map[string]Payload
+	if err := json.Unmarshal([]byte(rr.Header().Get("HX-Trigger")), &events); err != nil {
+		t.Fatalf("HX-Trigger = %q: %v", rr.Header().Get("HX-Trigger"), err)
+	}
+	toasts := events[EventNotify].Toasts
+	if len(toasts) != 1 || toasts[0].Level != Error || toasts[0].Action == nil || toasts[0].Action.URL != "/retry" {
+		t.Errorf("payload = %+v", events[EventNotify])
+	}
+	if rr.Body.Len() != 0 {
+		t.Errorf("body = %s", rr.Body.String())
+	}
+}
+
+func TestNotifier_FlashSurvivesRedirects(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *http.Request
+		redirect func(w http.ResponseWriter, r *http.Request)
+	}{
+		{"form post", httptest.NewRequest("POST", "/submit", nil), func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/form", http.StatusSeeOther)
+		}},
+		{"HX-Redirect", htmxRequest("POST", "/submit"), func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("HX-Redirect", "/form")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newTestNotifier()
+			rr := serve(n, tt.req, func(w http.ResponseWriter, r *http.Request) {
+				Add(r.Context(), NewSuccess("送信しました"))
+				tt.redirect(w, r)
+			})
+			if strings.Contains(rr.Body.String(), "送信しました") || rr.Header().Get("HX-Trigger") != "" {
+				t.Fatalf("delivered with the redirect: %v %s", rr.Header(), rr.Body.String())
+			}
+
+			// The next page renders the layout, which takes the queue
+			var shown []Toast
+			next := func(w http.ResponseWriter, r *http.Request) {
+				shown, _ = FromContext(r.Context()).Take()
+			}
+			serve(n, httptest.NewRequest("GET", "/form", nil), next)
+			if len(shown) != 1 || shown[0].Message != "送信しました" {
+				t.Fatalf("next page got %+v", shown)
+			}
+			serve(n, httptest.NewRequest("GET", "/form", nil), next)
+			if len(shown) != 0 {
+				t.Errorf("flash shown twice: %+v", shown)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package upload
+
+import (
+	"fmt"
+	"mime"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Defaults used when the environment does not configure uploads
+const (
+	DefaultDir      = "./data/uploads"
+	DefaultMaxBytes = 10 << 20
+)
+
+// DefaultAllowedTypes matches the file input's accept="image/*,.pdf,.txt"
+var DefaultAllowedTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain"}
+
+// Config configures the upload pipeline
+type Config struct {
+	Dir          string
+	MaxBytes     int64
+	AllowedTypes []string
+}
+
+// ConfigFromEnv reads UPLOAD_DIR, UPLOAD_MAX_BYTES and UPLOAD_ALLOWED_TYPES
+// (a comma-separated list of MIME types)
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{Dir: DefaultDir, MaxBytes: DefaultMaxBytes, AllowedTypes: DefaultAllowedTypes}
+
+	if dir := os.Getenv("UPLOAD_DIR"); dir != "" {
+		cfg.Dir = dir
+	}
+
+	if maxBytes := os.Getenv("UPLOAD_MAX_BYTES"); maxBytes != "" {
+		n, err := strconv.ParseInt(maxBytes, 10, 64)
+		if err != nil || n <= 0 {
+			return cfg, fmt.Errorf("invalid UPLOAD_MAX_BYTES %q: must be a positive integer", maxBytes)
+		}
+		cfg.MaxBytes = n
+	}
+
+	if types := os.Getenv("UPLOAD_ALLOWED_TYPES"); types != "" {
+		cfg.AllowedTypes = nil
+		for _, t := range strings.Split(types, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				cfg.AllowedTypes = append(cfg.AllowedTypes, t)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// Allows reports whether contentType is on the allowlist. Parameters such
+// as charset are ignored.
+func (c Config) Allows(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range c.AllowedTypes {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
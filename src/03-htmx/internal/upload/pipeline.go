@@ -0,0 +1,91 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Pipeline errors
+var (
+	ErrTooLarge        = errors.New("file exceeds the maximum upload size")
+	ErrTypeNotAllowed  = errors.New("file type is not allowed")
+	ErrEmptyFile       = errors.New("file is empty")
+	ErrInvalidFilename = errors.New("invalid file name")
+)
+
+// sniffLen is the number of bytes http.DetectContentType looks at
+const sniffLen = 512
+
+// Sniff detects the content type of r from its content, ignoring what the
+// client declared, and rejects types that are not allowed. The returned
+// reader yields the complete content including the sniffed bytes.
+func Sniff(r io.Reader, cfg Config) (string, io.Reader, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", nil, err
+	}
+	if n == 0 {
+		return "", nil, ErrEmptyFile
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !cfg.Allows(contentType) {
+		return contentType, nil, ErrTypeNotAllowed
+	}
+	return contentType, io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// LimitReader returns a reader that fails with ErrTooLarge once more than
+// max bytes have been read
+func LimitReader(r io.Reader, max int64) io.Reader {
+	return &limitedReader{r: r, remaining: max}
+}
+
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrTooLarge
+	}
+	// Read one byte past the limit to tell "exactly max" from "too large"
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
+
+// SanitizeFilename reduces a client-supplied name to a safe base name
+func SanitizeFilename(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = filepath.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" || name == ".." {
+		return "", ErrInvalidFilename
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	return name, nil
+}
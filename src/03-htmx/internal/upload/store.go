@@ -0,0 +1,240 @@
+package upload
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"htmx-demo/internal/models"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned for unknown files and files of other owners
+var ErrNotFound = errors.New("file not found")
+
+// Store keeps uploaded content on the local filesystem, addressed by its
+// SHA-256 so identical uploads share one blob. File records are kept in
+// memory per owner (the visitor's session) and go with the session; a blob
+// is removed when its last record is deleted. Blobs left on disk by an
+// earlier run have no records and are removed by CollectGarbage.
+type Store struct {
+	dir   string
+	mutex sync.RWMutex
+	files map[string]*storedFile
+	refs  map[string]int
+}
+
+type storedFile struct {
+	owner string
+	file  models.UploadedFile
+}
+
+// NewStore creates a store rooted at dir, creating it if needed
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "tmp"), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	return &Store{
+		dir:   dir,
+		files: make(map[string]*storedFile),
+		refs:  make(map[string]int),
+	}, nil
+}
+
+// Save streams content to disk and records it for owner. Name, Description
+// and ContentType are taken from file; ID, Hash, Size and UploadedAt are set
+// by the store.
+func (s *Store) Save(owner string, file models.UploadedFile, content io.Reader) (*models.UploadedFile, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, "tmp"), "upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file.ID = newFileID()
+	file.Hash = hex.EncodeToString(hasher.Sum(nil))
+	file.Size = size
+	file.UploadedAt = time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.refs[file.Hash] == 0 {
+		blob := s.blobPath(file.Hash)
+		if err := os.MkdirAll(filepath.Dir(blob), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create blob directory: %w", err)
+		}
+		if err := os.Rename(tmp.Name(), blob); err != nil {
+			return nil, fmt.Errorf("failed to store file: %w", err)
+		}
+	}
+	s.refs[file.Hash]++
+	s.files[file.ID] = &storedFile{owner: owner, file: file}
+
+	saved := file
+	return &saved, nil
+}
+
+// SetDescription updates the description of one of owner's files
+func (s *Store) SetDescription(owner, id, description string) (*models.UploadedFile, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, ok := s.files[id]
+	if !ok || stored.owner != owner {
+		return nil, ErrNotFound
+	}
+	stored.file.Description = description
+	file := stored.file
+	return &file, nil
+}
+
+// List returns owner's files, newest first
+func (s *Store) List(owner string) []models.UploadedFile {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	files := []models.UploadedFile{}
+	for _, stored := range s.files {
+		if stored.owner == owner {
+			files = append(files, stored.file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].UploadedAt.Equal(files[j].UploadedAt) {
+			return files[i].ID > files[j].ID
+		}
+		return files[i].UploadedAt.After(files[j].UploadedAt)
+	})
+	return files
+}
+
+// Open returns one of owner's files and its content. The caller closes it.
+func (s *Store) Open(owner, id string) (*models.UploadedFile, *os.File, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	stored, ok := s.files[id]
+	if !ok || stored.owner != owner {
+		return nil, nil, ErrNotFound
+	}
+	content, err := os.Open(s.blobPath(stored.file.Hash))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	file := stored.file
+	return &file, content, nil
+}
+
+// Delete removes one of owner's files, and its blob if no other file shares it
+func (s *Store) Delete(owner, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, ok := s.files[id]
+	if !ok || stored.owner != owner {
+		return ErrNotFound
+	}
+	delete(s.files, id)
+	return s.release(stored.file.Hash)
+}
+
+// DeleteOwner removes all files of owner, e.g. when the session expires,
+// and the blobs no other file shares. It returns how many files were removed.
+func (s *Store) DeleteOwner(owner string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	removed := 0
+	var errs []error
+	for id, stored := range s.files {
+		if stored.owner != owner {
+			continue
+		}
+		delete(s.files, id)
+		removed++
+		if err := s.release(stored.file.Hash); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// CollectGarbage removes blobs no file refers to, such as those of an
+// earlier run, and returns how many were removed
+func (s *Store) CollectGarbage() (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	shards, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read upload directory: %w", err)
+	}
+	removed := 0
+	var errs []error
+	for _, shard := range shards {
+		if !shard.IsDir() || len(shard.Name()) != 2 {
+			continue
+		}
+		blobs, err := os.ReadDir(filepath.Join(s.dir, shard.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, blob := range blobs {
+			hash := blob.Name()
+			if !strings.HasPrefix(hash, shard.Name()) || s.refs[hash] > 0 {
+				continue
+			}
+			if err := os.Remove(s.blobPath(hash)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			removed++
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// release drops a reference to a blob, removing it with its last reference.
+// The caller must hold the write lock.
+func (s *Store) release(hash string) error {
+	s.refs[hash]--
+	if s.refs[hash] > 0 {
+		return nil
+	}
+	delete(s.refs, hash)
+	if err := os.Remove(s.blobPath(hash)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove file: %w", err)
+	}
+	return nil
+}
+
+// blobPath shards blobs by the first two hex digits of their hash
+func (s *Store) blobPath(hash string) string {
+	return filepath.Join(s.dir, hash[:2], hash)
+}
+
+func newFileID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate file ID: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,197 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"htmx-demo/internal/models"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestSniff(t *testing.T) {
+	cfg := Config{AllowedTypes: []string{"image/png", "text/plain"}}
+
+	tests := []struct {
+		name     string
+		content  []byte
+		wantType string
+		wantErr  error
+	}{
+		{name: "png", content: append(pngHeader, make([]byte, 1000)...), wantType: "image/png"},
+		{name: "text with charset", content: []byte("hello"), wantType: "text/plain; charset=utf-8"},
+		{name: "html disguised as text", content: []byte("<html><script>alert(1)</script>"), wantErr: ErrTypeNotAllowed},
+		{name: "empty", content: nil, wantErr: ErrEmptyFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contentType, r, err := Sniff(bytes.NewReader(tt.content), cfg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if contentType != tt.wantType {
+				t.Errorf("type = %q, want %q", contentType, tt.wantType)
+			}
+			got, _ := io.ReadAll(r)
+			if !bytes.Equal(got, tt.content) {
+				t.Errorf("sniffing lost content: got %d bytes, want %d", len(got), len(tt.content))
+			}
+		})
+	}
+}
+
+func TestLimitReader(t *testing.T) {
+	if got, err := io.ReadAll(LimitReader(strings.NewReader("12345"), 5)); err != nil || string(got) != "12345" {
+		t.Errorf("exactly max: %q, %v", got, err)
+	}
+	if _, err := io.ReadAll(LimitReader(strings.NewReader("123456"), 5)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("over max: error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := map[string]string{
+		"report.pdf":                  "report.pdf",
+		"../../etc/passwd":            "passwd",
+		`C:\Users\me\photo.png`:       "photo.png",
+		"evil\x00name\n.txt":          "evilname.txt",
+		"<script>alert(1)</script>.t": "script>.t",
+	}
+	for in, want := range tests {
+		if got, err := SanitizeFilename(in); err != nil || got != want {
+			t.Errorf("SanitizeFilename(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "..", "/", "  "} {
+		if _, err := SanitizeFilename(in); err == nil {
+			t.Errorf("SanitizeFilename(%q) should fail", in)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatalf("NewStore: %v", err)
+	}
+
+	save := func(owner, name, content string) *models.UploadedFile {
+		t.Helper()
+		file, err := store.Save(owner, models.UploadedFile{Name: name, ContentType: "text/plain"}, strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		return file
+	}
+
+	a := save("alice", "a.txt", "same content")
+	b := save("bob", "b.txt", "same content")
+
+	t.Run("identical content shares one blob", func(t *testing.T) {
+		if a.Hash != b.Hash || a.Size != int64(len("same content")) {
+			t.Errorf("a=%+v b=%+v", a, b)
+		}
+		blob := filepath.Join(dir, a.Hash[:2], a.Hash)
+		if _, err := os.Stat(blob); err != nil {
+			t.Errorf("blob missing: %v", err)
+		}
+	})
+
+	t.Run("files belong to their owner", func(t *testing.T) {
+		if files := store.List("alice"); len(files) != 1 || files[0].ID != a.ID {
+			t.Errorf("alice's files = %+v", files)
+		}
+		if _, _, err := store.Open("alice", b.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("alice opened bob's file: %v", err)
+		}
+		if err := store.Delete("alice", b.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("alice deleted bob's file: %v", err)
+		}
+	})
+
+	t.Run("blob is removed with its last file", func(t *testing.T) {
+		blob := filepath.Join(dir, a.Hash[:2], a.Hash)
+		if err := store.Delete("alice", a.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		_, content, err := store.Open("bob", b.ID)
+		if err != nil {
+			t.Fatalf("bob's file lost with alice's: %v", err)
+		}
+		got, _ := io.ReadAll(content)
+		content.Close()
+		if string(got) != "same content" {
+			t.Errorf("content = %q", got)
+		}
+
+		if err := store.Delete("bob", b.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := os.Stat(blob); !os.IsNotExist(err) {
+			t.Errorf("blob survived its last file: %v", err)
+		}
+	})
+
+	t.Run("files go with their owner", func(t *testing.T) {
+		c := save("carol", "c.txt", "carol's content")
+		save("carol", "d.txt", "shared content")
+		e := save("dave", "e.txt", "shared content")
+
+		if removed, err := store.DeleteOwner("carol"); err != nil || removed != 2 {
+			t.Fatalf("DeleteOwner = %d, %v; want 2", removed, err)
+		}
+		if files := store.List("carol"); len(files) != 0 {
+			t.Errorf("carol's files survived: %+v", files)
+		}
+		if _, err := os.Stat(filepath.Join(dir, c.Hash[:2], c.Hash)); !os.IsNotExist(err) {
+			t.Errorf("blob of carol's file survived: %v", err)
+		}
+		if _, content, err := store.Open("dave", e.ID); err != nil {
+			t.Errorf("shared blob removed with carol's files: %v", err)
+		} else {
+			content.Close()
+		}
+	})
+
+	t.Run("garbage collection removes unreferenced blobs", func(t *testing.T) {
+		orphan := strings.Repeat("ab", 32)
+		if err := os.MkdirAll(filepath.Join(dir, "ab"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "ab", orphan), []byte("left by an earlier run"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		kept := store.List("dave")[0]
+
+		if removed, err := store.CollectGarbage(); err != nil || removed != 1 {
+			t.Fatalf("CollectGarbage = %d, %v; want 1", removed, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "ab", orphan)); !os.IsNotExist(err) {
+			t.Errorf("orphaned blob survived: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, kept.Hash[:2], kept.Hash)); err != nil {
+			t.Errorf("referenced blob removed: %v", err)
+		}
+	})
+
+	t.Run("failed saves leave nothing behind", func(t *testing.T) {
+		_, err := store.Save("alice", models.UploadedFile{Name: "big.txt"}, LimitReader(strings.NewReader("too large"), 3))
+		if !errors.Is(err, ErrTooLarge) {
+			t.Fatalf("error = %v, want ErrTooLarge", err)
+		}
+		if files := store.List("alice"); len(files) != 0 {
+			t.Errorf("failed save was recorded: %+v", files)
+		}
+		if tmp, _ := os.ReadDir(filepath.Join(dir, "tmp")); len(tmp) != 0 {
+			t.Errorf("temporary files left behind: %d", len(tmp))
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package validation
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Lang is a language messages are rendered in
+type Lang string
+
+const (
+	Japanese Lang = "ja"
+	English  Lang = "en"
+)
+
+// LangFromRequest returns the language of a request: the "lang" form value
+// if it names a supported language, otherwise the first supported language
+// of Accept-Language, otherwise Japanese
+func LangFromRequest(r *http.Request) Lang {
+	switch Lang(r.FormValue("lang")) {
+	case Japanese:
+		return Japanese
+	case English:
+		return English
+	}
+
+	for _, tag := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ = strings.Cut(strings.TrimSpace(tag), ";")
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		switch Lang(base) {
+		case Japanese:
+			return Japanese
+		case English:
+			return English
+		}
+	}
+	return Japanese
+}
+
+// Text is a text in every supported language
+type Text map[Lang]string
+
+// In returns the text in lang, falling back to Japanese
+func (t Text) In(lang Lang) string {
+	if s, ok := t[lang]; ok {
+		return s
+	}
+	return t[Japanese]
+}
+
+// Message keys
+const (
+	MsgRequired    = "required"
+	MsgMinLength   = "minLength"
+	MsgMaxLength   = "maxLength"
+	MsgDigit       = "digit"
+	MsgUpper       = "upper"
+	MsgMatch       = "match"
+	MsgTaken       = "taken"
+	MsgCheckFailed = "checkFailed"
+	MsgValid       = "valid"
+	MsgInvalidForm = "invalidForm"
+)
+
+// catalog holds the format of every message per language. Arguments of
+// type Text are rendered in the same language.
+var catalog = map[Lang]map[string]string{
+	Japanese: {
+		MsgRequired:    "%sを入力してください",
+		MsgMinLength:   "%sは%d文字以上である必要があります",
+		MsgMaxLength:   "%sは%d文字以内で入力してください",
+		MsgDigit:       "%sには数字を含めてください",
+		MsgUpper:       "%sには大文字を含めてください",
+		MsgMatch:       "%sが一致しません",
+		MsgTaken:       "この%sは既に使用されています",
+		MsgCheckFailed: "%sを確認できませんでした。しばらくしてから再度お試しください",
+		MsgValid:       "%sに問題はありません",
+		MsgInvalidForm: "バリデーションエラー",
+	},
+	English: {
+		MsgRequired:    "%s is required",
+		MsgMinLength:   "%s must be at least %d characters",
+		MsgMaxLength:   "%s must be at most %d characters",
+		MsgDigit:       "%s must contain a digit",
+		MsgUpper:       "%s must contain an uppercase letter",
+		MsgMatch:       "%s does not match",
+		MsgTaken:       "%s is already taken",
+		MsgCheckFailed: "Could not check %s; please try again later",
+		MsgValid:       "%s looks good",
+		MsgInvalidForm: "Validation failed",
+	},
+}
+
+// Message is a message to the visitor, rendered in their language
+type Message struct {
+	Key  string
+	Args []any
+}
+
+// Msg returns the message key formatted with args
+func Msg(key string, args ...any) Message {
+	return Message{Key: key, Args: args}
+}
+
+// In renders the message in lang
+func (m Message) In(lang Lang) string {
+	format, ok := catalog[lang][m.Key]
+	if !ok {
+		if format, ok = catalog[Japanese][m.Key]; !ok {
+			return m.Key
+		}
+	}
+
+	args := make([]any, len(m.Args))
+	for i, arg := range m.Args {
+		if text, ok := arg.(Text); ok {
+			arg = text.In(lang)
+		}
+		args[i] = arg
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rule checks the value of one field. Rules other than Required are not
+// run on empty values.
+type Rule struct {
+	check    func(ctx context.Context, field Field, value string, values url.Values) (*Message, error)
+	required bool
+	async    bool
+	// matches is the field a cross-field rule compares against
+	matches string
+}
+
+func reject(key string, args ...any) *Message {
+	m := Msg(key, args...)
+	return &m
+}
+
+// Required rejects an empty value
+func Required() Rule {
+	return Rule{
+		required: true,
+		check: func(_ context.Context, field Field, value string, _ url.Values) (*Message, error) {
+			if value == "" {
+				return reject(MsgRequired, field.Label), nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+// MinLength rejects values shorter than n characters
+func MinLength(n int) Rule {
+	return Rule{check: func(_ context.Context, field Field, value string, _ url.Values) (*Message, error) {
+		if utf8.RuneCountInString(value) < n {
+			return reject(MsgMinLength, field.Label, n), nil
+		}
+		return nil, nil
+	}}
+}
+
+// MaxLength rejects values longer than n characters
+func MaxLength(n int) Rule {
+	return Rule{check: func(_ context.Context, field Field, value string, _ url.Values) (*Message, error) {
+		if utf8.RuneCountInString(value) > n {
+			return reject(MsgMaxLength, field.Label, n), nil
+		}
+		return nil, nil
+	}}
+}
+
+// ContainsDigit rejects values without a digit
+func ContainsDigit() Rule {
+	return Rule{check: func(_ context.Context, field Field, value string, _ url.Values) (*Message, error) {
+		if !strings.ContainsFunc(value, unicode.IsDigit) {
+			return reject(MsgDigit, field.Label), nil
+		}
+		return nil, nil
+	}}
+}
+
+// ContainsUpper rejects values without an uppercase letter
+func ContainsUpper() Rule {
+	return Rule{check: func(_ context.Context, field Field, value string, _ url.Values) (*Message, error) {
+		if !strings.ContainsFunc(value, unicode.IsUpper) {
+			return reject(MsgUpper, field.Label), nil
+		}
+		return nil, nil
+	}}
+}
+
+// Matches rejects values that differ from the value of field other, like a
+// password confirmation. Changing other re-validates this field.
+func Matches(other string) Rule {
+	return Rule{
+		matches: other,
+		check: func(_ context.Context, field Field, value string, values url.Values) (*Message, error) {
+			if value != values.Get(other) {
+				return reject(MsgMatch, field.Label), nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+// Unique rejects values for which exists reports true, e.g. a username
+// that is already registered. exists may be slow, so it only runs once the
+// other rules of the field pass.
+func Unique(exists func(ctx context.Context, value string) (bool, error)) Rule {
+	return Rule{
+		async: true,
+		check: func(ctx context.Context, field Field, value string, _ url.Values) (*Message, error) {
+			taken, err := exists(ctx, value)
+			if err != nil {
+				return nil, err
+			}
+			if taken {
+				return reject(MsgTaken, field.Label), nil
+			}
+			return nil, nil
+		},
+	}
+}
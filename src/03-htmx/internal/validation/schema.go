@@ -0,0 +1,183 @@
+// Package validation defines forms once as a schema of fields and rules.
+// The same schema validates a single field while the visitor types and the
+// whole form on submit, so both report the same messages, in Japanese or
+// English.
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+)
+
+// ErrUnknownField is returned when validating a field the schema lacks
+var ErrUnknownField = errors.New("unknown field")
+
+// Field is a field of a form
+type Field struct {
+	Name        string
+	Label       Text
+	InputType   string
+	Placeholder Text
+	Rules       []Rule
+	// Valid is shown when the field passes; a generic message if empty
+	Valid Text
+}
+
+// FeedbackID returns the id of the element showing the field's messages
+func (f Field) FeedbackID() string {
+	return strings.ReplaceAll(f.Name, "_", "-") + "-validation"
+}
+
+// ValidText returns the message shown in lang when the field passes
+func (f Field) ValidText(lang Lang) string {
+	if len(f.Valid) == 0 {
+		return Msg(MsgValid, f.Label).In(lang)
+	}
+	return f.Valid.In(lang)
+}
+
+// FieldResult is the outcome of validating one field
+type FieldResult struct {
+	Field  Field
+	Value  string
+	Errors []Message
+}
+
+// Valid reports whether the field passed
+func (r FieldResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Result is the outcome of validating a whole form
+type Result struct {
+	Fields []FieldResult
+}
+
+// Valid reports whether every field passed
+func (r Result) Valid() bool {
+	for _, field := range r.Fields {
+		if !field.Valid() {
+			return false
+		}
+	}
+	return true
+}
+
+// Errors returns the messages of every field, in field order
+func (r Result) Errors() []Message {
+	var errs []Message
+	for _, field := range r.Fields {
+		errs = append(errs, field.Errors...)
+	}
+	return errs
+}
+
+// Schema is a form definition
+type Schema struct {
+	fields     []Field
+	index      map[string]int
+	dependents map[string][]string
+}
+
+// New creates a schema. It panics on duplicate field names or a Matches
+// rule naming an unknown field, as schemas are defined at startup.
+func New(fields ...Field) *Schema {
+	s := &Schema{
+		fields:     fields,
+		index:      make(map[string]int),
+		dependents: make(map[string][]string),
+	}
+	for i, field := range fields {
+		if _, exists := s.index[field.Name]; exists {
+			panic(fmt.Sprintf("validation: field %q defined twice", field.Name))
+		}
+		s.index[field.Name] = i
+	}
+	for _, field := range fields {
+		for _, rule := range field.Rules {
+			if rule.matches == "" {
+				continue
+			}
+			if _, exists := s.index[rule.matches]; !exists {
+				panic(fmt.Sprintf("validation: field %q matches unknown field %q", field.Name, rule.matches))
+			}
+			s.dependents[rule.matches] = append(s.dependents[rule.matches], field.Name)
+		}
+	}
+	return s
+}
+
+// Fields returns the fields in form order
+func (s *Schema) Fields() []Field {
+	return append([]Field(nil), s.fields...)
+}
+
+// Field returns the field called name
+func (s *Schema) Field(name string) (Field, bool) {
+	i, exists := s.index[name]
+	if !exists {
+		return Field{}, false
+	}
+	return s.fields[i], true
+}
+
+// Dependents returns the fields whose rules read the value of name, which
+// should be re-validated when it changes
+func (s *Schema) Dependents(name string) []string {
+	return append([]string(nil), s.dependents[name]...)
+}
+
+// ValidateField validates one field of the submitted values
+func (s *Schema) ValidateField(ctx context.Context, name string, values url.Values) (FieldResult, error) {
+	field, exists := s.Field(name)
+	if !exists {
+		return FieldResult{}, fmt.Errorf("%w: %q", ErrUnknownField, name)
+	}
+	return validateField(ctx, field, values), nil
+}
+
+// Validate validates every field of the submitted values
+func (s *Schema) Validate(ctx context.Context, values url.Values) Result {
+	result := Result{Fields: make([]FieldResult, len(s.fields))}
+	for i, field := range s.fields {
+		result.Fields[i] = validateField(ctx, field, values)
+	}
+	return result
+}
+
+func validateField(ctx context.Context, field Field, values url.Values) FieldResult {
+	result := FieldResult{Field: field, Value: values.Get(field.Name)}
+
+	run := func(rule Rule) {
+		if result.Value == "" && !rule.required {
+			return
+		}
+		message, err := rule.check(ctx, field, result.Value, values)
+		if err != nil {
+			log.Printf("validation: failed to check %s: %v", field.Name, err)
+			message = reject(MsgCheckFailed, field.Label)
+		}
+		if message != nil {
+			result.Errors = append(result.Errors, *message)
+		}
+	}
+
+	for _, rule := range field.Rules {
+		if !rule.async {
+			run(rule)
+		}
+	}
+	// Slow checks are pointless for a value that is already rejected
+	if len(result.Errors) == 0 {
+		for _, rule := range field.Rules {
+			if rule.async {
+				run(rule)
+			}
+		}
+	}
+	return result
+}
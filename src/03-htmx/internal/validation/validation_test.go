@@ -0,0 +1,162 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func testSchema(exists func(ctx context.Context, value string) (bool, error)) *Schema {
+	return New(
+		Field{
+			Name:  "username",
+			Label: Text{Japanese: "ユーザー名", English: "Username"},
+			Rules: []Rule{Required(), MinLength(3), Unique(exists)},
+		},
+		Field{
+			Name:  "password",
+			Label: Text{Japanese: "パスワード", English: "Password"},
+			Rules: []Rule{Required(), MinLength(8), ContainsDigit(), ContainsUpper()},
+		},
+		Field{
+			Name:  "password_confirm",
+			Label: Text{Japanese: "パスワード確認", English: "Password confirmation"},
+			Rules: []Rule{Required(), Matches("password")},
+		},
+	)
+}
+
+func messages(result FieldResult, lang Lang) []string {
+	var texts []string
+	for _, m := range result.Errors {
+		texts = append(texts, m.In(lang))
+	}
+	return texts
+}
+
+func TestValidateField(t *testing.T) {
+	lookups := 0
+	schema := testSchema(func(_ context.Context, value string) (bool, error) {
+		lookups++
+		return value == "admin", nil
+	})
+
+	tests := []struct {
+		field  string
+		values url.Values
+		want   []string
+	}{
+		{"username", url.Values{}, []string{"ユーザー名を入力してください"}},
+		{"username", url.Values{"username": {"ab"}}, []string{"ユーザー名は3文字以上である必要があります"}},
+		{"username", url.Values{"username": {"admin"}}, []string{"このユーザー名は既に使用されています"}},
+		{"username", url.Values{"username": {"alice"}}, nil},
+		{"password", url.Values{"password": {"short"}}, []string{
+			"パスワードは8文字以上である必要があります",
+			"パスワードには数字を含めてください",
+			"パスワードには大文字を含めてください",
+		}},
+		{"password", url.Values{"password": {"Passw0rd"}}, nil},
+		{"password_confirm", url.Values{"password": {"Passw0rd"}, "password_confirm": {"Passw0rd!"}}, []string{"パスワード確認が一致しません"}},
+		{"password_confirm", url.Values{"password": {"Passw0rd"}, "password_confirm": {"Passw0rd"}}, nil},
+	}
+	for _, tt := range tests {
+		result, err := schema.ValidateField(context.Background(), tt.field, tt.values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := messages(result, Japanese); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %v: got %q, want %q", tt.field, tt.values, got, tt.want)
+		}
+	}
+
+	// Only the two usernames passing the other rules were looked up
+	if lookups != 2 {
+		t.Errorf("availability was checked %d times, want 2", lookups)
+	}
+
+	if _, err := schema.ValidateField(context.Background(), "email", url.Values{}); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("expected ErrUnknownField, got %v", err)
+	}
+}
+
+func TestValidate_SameMessagesAsPerField(t *testing.T) {
+	schema := testSchema(func(context.Context, string) (bool, error) { return false, nil })
+	values := url.Values{"username": {"ab"}, "password": {"password1"}, "password_confirm": {"password"}}
+
+	result := schema.Validate(context.Background(), values)
+	if result.Valid() {
+		t.Fatal("expected the form to be invalid")
+	}
+	for _, fieldResult := range result.Fields {
+		single, _ := schema.ValidateField(context.Background(), fieldResult.Field.Name, values)
+		if !reflect.DeepEqual(messages(single, English), messages(fieldResult, English)) {
+			t.Errorf("%s: form %q, field %q", fieldResult.Field.Name, messages(fieldResult, English), messages(single, English))
+		}
+	}
+	want := []string{
+		"Username must be at least 3 characters",
+		"Password must contain an uppercase letter",
+		"Password confirmation does not match",
+	}
+	var got []string
+	for _, m := range result.Errors() {
+		got = append(got, m.In(English))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestValidateField_FailedLookup(t *testing.T) {
+	schema := testSchema(func(context.Context, string) (bool, error) { return false, errors.New("directory unavailable") })
+
+	result, _ := schema.ValidateField(context.Background(), "username", url.Values{"username": {"alice"}})
+	if got := messages(result, English); !reflect.DeepEqual(got, []string{"Could not check Username; please try again later"}) {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestDependents(t *testing.T) {
+	schema := testSchema(nil)
+	if got := schema.Dependents("password"); !reflect.DeepEqual(got, []string{"password_confirm"}) {
+		t.Errorf("got %v", got)
+	}
+	if got := schema.Dependents("username"); len(got) != 0 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestNew_PanicsOnUnknownMatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	New(Field{Name: "confirm", Rules: []Rule{Matches("missing")}})
+}
+
+func TestLangFromRequest(t *testing.T) {
+	tests := []struct {
+		target         string
+		acceptLanguage string
+		want           Lang
+	}{
+		{"/", "", Japanese},
+		{"/", "en-US,en;q=0.9", English},
+		{"/", "fr-FR, en;q=0.5", English},
+		{"/", "ja,en;q=0.8", Japanese},
+		{"/?lang=en", "ja", English},
+		{"/?lang=ja", "en", Japanese},
+		{"/?lang=fr", "en", English},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.target, nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+		if got := LangFromRequest(req); got != tt.want {
+			t.Errorf("%s %q: got %s, want %s", tt.target, tt.acceptLanguage, got, tt.want)
+		}
+	}
+}
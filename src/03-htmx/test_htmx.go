@@ -2,20 +2,27 @@ package main
 
 import (
 	"fmt"
+	"htmx-demo/internal/autocomplete"
 	"htmx-demo/internal/handlers"
+	"htmx-demo/internal/models"
+	"htmx-demo/internal/notify"
+	"htmx-demo/internal/session"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 func main() {
 	// Initialize handlers
+	sessionManager, _ := session.NewManager(session.NewSecret(), time.Hour)
 	pageHandler := handlers.NewPageHandler()
-	apiHandler := handlers.NewAPIHandler()
+	apiHandler := handlers.NewAPIHandler(session.NewStore(time.Hour, models.NewDemoState), notify.NewBroker(notify.DefaultConfig()), autocomplete.NewIndex(autocomplete.DefaultCities()))
 
 	// Setup router
 	router := mux.NewRouter()
+	router.Use(sessionManager.Middleware)
 	router.HandleFunc("/", pageHandler.Home).Methods("GET")
 	router.HandleFunc("/basic-requests", pageHandler.BasicRequests).Methods("GET")
 	router.HandleFunc("/triggers", pageHandler.Triggers).Methods("GET")
@@ -5,4 +5,7 @@ go 1.24.3
 require (
 	github.com/a-h/templ v0.3.887
 	github.com/gorilla/mux v1.8.1
+	shared v0.0.0-00010101000000-000000000000
 )
+
+replace shared => ../shared
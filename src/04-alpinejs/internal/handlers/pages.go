@@ -1,12 +1,17 @@
 package handlers
 
 import (
-	"context"
-	"log"
 	"alpinejs-demo/internal/templates"
+	"log"
 	"net/http"
+	"shared/htmx"
+
+	"github.com/a-h/templ"
 )
 
+// contentTarget is the element of the layout holding the page content
+const contentTarget = "#content"
+
 // PageHandler handles page requests
 type PageHandler struct{}
 
@@ -15,93 +20,49 @@ func NewPageHandler() *PageHandler {
 	return &PageHandler{}
 }
 
-// Home serves the home page
-func (h *PageHandler) Home(w http.ResponseWriter, r *http.Request) {
-	log.Printf("Home page accessed: %s %s", r.Method, r.URL.Path)
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err := templates.Home().Render(context.Background(), w)
-	if err != nil {
-		log.Printf("Error rendering home template: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// renderPage renders content inside the site layout. Boosted navigation
+// receives the content alone and the URL is pushed into the history.
+func renderPage(w http.ResponseWriter, r *http.Request, name, title string, content templ.Component) {
+	log.Printf("%s page accessed: %s %s", name, r.Method, r.URL.Path)
+	page := htmx.Page{Title: title, Content: content, Layout: templates.Base, Target: contentTarget}
+	if err := htmx.RenderPage(w, r, http.StatusOK, page); err != nil {
+		log.Printf("Error rendering %s template: %v", name, err)
 		return
 	}
-	log.Printf("Home page rendered successfully")
+	log.Printf("%s page rendered successfully", name)
+}
+
+// Home serves the home page
+func (h *PageHandler) Home(w http.ResponseWriter, r *http.Request) {
+	renderPage(w, r, "Home", "Alpine.js デモ - ホーム", templates.Home())
 }
 
 // BasicState serves the basic state management demo
 func (h *PageHandler) BasicState(w http.ResponseWriter, r *http.Request) {
-	log.Printf("BasicState page accessed: %s %s", r.Method, r.URL.Path)
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err := templates.BasicState().Render(context.Background(), w)
-	if err != nil {
-		log.Printf("Error rendering BasicState template: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	log.Printf("BasicState page rendered successfully")
+	renderPage(w, r, "BasicState", "基本状態管理 - Alpine.js デモ", templates.BasicState())
 }
 
 // TodoApp serves the TODO application demo
 func (h *PageHandler) TodoApp(w http.ResponseWriter, r *http.Request) {
-	log.Printf("TodoApp page accessed: %s %s", r.Method, r.URL.Path)
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err := templates.TodoApp().Render(context.Background(), w)
-	if err != nil {
-		log.Printf("Error rendering TodoApp template: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	log.Printf("TodoApp page rendered successfully")
+	renderPage(w, r, "TodoApp", "TODOアプリ - Alpine.js デモ", templates.TodoApp())
 }
 
 // EventHandling serves the event handling demo
 func (h *PageHandler) EventHandling(w http.ResponseWriter, r *http.Request) {
-	log.Printf("EventHandling page accessed: %s %s", r.Method, r.URL.Path)
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err := templates.EventHandling().Render(context.Background(), w)
-	if err != nil {
-		log.Printf("Error rendering EventHandling template: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	log.Printf("EventHandling page rendered successfully")
+	renderPage(w, r, "EventHandling", "Alpine.js デモ - イベント処理", templates.EventHandling())
 }
 
 // GlobalState serves the global state demo
 func (h *PageHandler) GlobalState(w http.ResponseWriter, r *http.Request) {
-	log.Printf("GlobalState page accessed: %s %s", r.Method, r.URL.Path)
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err := templates.GlobalState().Render(context.Background(), w)
-	if err != nil {
-		log.Printf("Error rendering GlobalState template: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	log.Printf("GlobalState page rendered successfully")
+	renderPage(w, r, "GlobalState", "Alpine.js デモ - グローバル状態", templates.GlobalState())
 }
 
 // HTMXIntegration serves the HTMX integration demo
 func (h *PageHandler) HTMXIntegration(w http.ResponseWriter, r *http.Request) {
-	log.Printf("HTMXIntegration page accessed: %s %s", r.Method, r.URL.Path)
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err := templates.HTMXIntegration().Render(context.Background(), w)
-	if err != nil {
-		log.Printf("Error rendering HTMXIntegration template: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	log.Printf("HTMXIntegration page rendered successfully")
+	renderPage(w, r, "HTMXIntegration", "Alpine.js デモ - HTMX統合", templates.HTMXIntegration())
 }
 
 // AdvancedPatterns serves the advanced patterns demo
 func (h *PageHandler) AdvancedPatterns(w http.ResponseWriter, r *http.Request) {
-	log.Printf("AdvancedPatterns page accessed: %s %s", r.Method, r.URL.Path)
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	err := templates.AdvancedPatterns().Render(context.Background(), w)
-	if err != nil {
-		log.Printf("Error rendering AdvancedPatterns template: %v", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	log.Printf("AdvancedPatterns page rendered successfully")
-}
\ No newline at end of file
+	renderPage(w, r, "AdvancedPatterns", "Alpine.js デモ - 応用パターン", templates.AdvancedPatterns())
+}
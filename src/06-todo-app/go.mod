@@ -3,8 +3,10 @@ module todo-app
 go 1.24.3
 
 require (
+	github.com/a-h/templ v0.3.887
 	github.com/gorilla/mux v1.8.1
 	github.com/mattn/go-sqlite3 v1.14.22
+	shared v0.0.0-00010101000000-000000000000
 )
 
-require github.com/a-h/templ v0.3.887 // indirect
+replace shared => ../shared
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"shared/datatable"
+	"todo-app/internal/models"
+	"todo-app/internal/templates"
+
+	"github.com/a-h/templ"
+)
+
+// TODO一覧表の列。タイトルの絞り込みは説明文も検索する
+var todoTable = &datatable.Table[models.Todo]{
+	ID:  "todo-table",
+	URL: "/table",
+	Columns: []datatable.Column[models.Todo]{
+		{Key: "id", Label: "ID", Text: func(t models.Todo) string { return strconv.Itoa(t.ID) }, Class: "text-right tabular-nums"},
+		{Key: "title", Label: "タイトル", Text: func(t models.Todo) string { return t.Title }, Sortable: true, Filterable: true},
+		{
+			Key: "status", Label: "状態", Text: todoStatus, Filterable: true,
+			Options: []datatable.Option{{Value: "active", Label: "未完了"}, {Value: "completed", Label: "完了"}, {Value: "overdue", Label: "期限切れ"}},
+		},
+		{
+			Key: "priority", Label: "優先度", Sortable: true, Filterable: true,
+			Cell:    func(t models.Todo) templ.Component { return templates.PriorityBadge(t.Priority) },
+			Options: []datatable.Option{{Value: "high", Label: "高"}, {Value: "medium", Label: "中"}, {Value: "low", Label: "低"}},
+		},
+		{Key: "due_date", Label: "期限", Text: todoDueDate, Sortable: true},
+		{Key: "created_at", Label: "作成日時", Text: func(t models.Todo) string { return t.CreatedAt.Format("2006-01-02 15:04") }, Sortable: true},
+	},
+	PageSizes: []int{10, 25, 50},
+	Sort:      "created_at",
+	Order:     datatable.Desc,
+	Empty:     "該当するTODOがありません。",
+}
+
+func todoStatus(t models.Todo) string {
+	if t.Completed {
+		return "完了"
+	}
+	return "未完了"
+}
+
+func todoDueDate(t models.Todo) string {
+	if t.DueDate == nil {
+		return "—"
+	}
+	return t.DueDate.Format("2006-01-02")
+}
+
+// 一覧表の状態をリポジトリの取得条件に置き換えるデータソース
+type todoSource struct {
+	repo *models.TodoRepository
+}
+
+func (s todoSource) Query(ctx context.Context, q datatable.Query) (datatable.Result[models.Todo], error) {
+	todos, total, err := s.repo.Page(ctx, models.TodoQuery{
+		Filter:   q.Filter("status"),
+		Search:   q.Filter("title"),
+		Priority: q.Filter("priority"),
+		Sort:     q.Sort,
+		Desc:     q.Order == datatable.Desc,
+		Limit:    q.PageSize,
+		Offset:   q.Offset(),
+	})
+	if err != nil {
+		return datatable.Result[models.Todo]{}, err
+	}
+	return datatable.Result[models.Todo]{Rows: todos, Total: total}, nil
+}
+
+// TODO一覧表の表示。並べ替え・絞り込み・ページ送りは一覧表だけを差し替える
+func (h *TodoHandler) Table(w http.ResponseWriter, r *http.Request) {
+	view, err := todoTable.Load(r.Context(), todoSource{repo: h.repo}, r.URL.Query())
+	if err != nil {
+		h.sendError(w, "データの取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	if err := datatable.Render(w, r, view, templates.TablePage(view)); err != nil {
+		h.sendError(w, "テンプレートの実行に失敗しました", http.StatusInternalServerError)
+	}
+}
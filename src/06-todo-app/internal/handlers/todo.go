@@ -6,9 +6,11 @@ import (
 	"strings"
 	"time"
 
+	"shared/htmx"
 	"todo-app/internal/models"
 	"todo-app/internal/templates"
 
+	"github.com/a-h/templ"
 	"github.com/gorilla/mux"
 )
 
@@ -40,8 +42,8 @@ func (h *TodoHandler) Index(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// HTMXリクエストの場合は部分的なHTMLを返す
-	if r.Header.Get("HX-Request") == "true" {
-		target := r.Header.Get("HX-Target")
+	if htmx.IsHTMX(r) {
+		target := htmx.Target(r)
 		if target == "todo-list" || target == "#todo-list" {
 			w.Header().Set("Content-Type", "text/html")
 			err = templates.TodoList(todos).Render(r.Context(), w)
@@ -116,12 +118,8 @@ func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 新しいTODOアイテムのHTMLを返す
-	w.Header().Set("HX-Trigger", "todoAdded")
-	w.Header().Set("Content-Type", "text/html")
-	err = templates.TodoItem(*createdTodo).Render(r.Context(), w)
-	if err != nil {
-		h.sendError(w, "テンプレートの実行に失敗しました", http.StatusInternalServerError)
-	}
+	htmx.TriggerEvent(w, "todoAdded")
+	h.renderWithStats(w, r, templates.TodoItem(*createdTodo))
 }
 
 // TODO更新
@@ -198,12 +196,8 @@ func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 更新されたTODOアイテムのHTMLを返す
-	w.Header().Set("HX-Trigger", "todoUpdated")
-	w.Header().Set("Content-Type", "text/html")
-	err = templates.TodoItem(*updatedTodo).Render(r.Context(), w)
-	if err != nil {
-		h.sendError(w, "テンプレートの実行に失敗しました", http.StatusInternalServerError)
-	}
+	htmx.TriggerEvent(w, "todoUpdated")
+	h.renderWithStats(w, r, templates.TodoItem(*updatedTodo))
 }
 
 // TODO削除
@@ -228,9 +222,9 @@ func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 削除成功のレスポンス（空のレスポンス）
-	w.Header().Set("HX-Trigger", "todoDeleted")
-	w.WriteHeader(http.StatusOK)
+	// 削除成功のレスポンス（アイテムは空に置き換わり、統計情報のみ更新）
+	htmx.TriggerEvent(w, "todoDeleted")
+	h.renderWithStats(w, r, nil)
 }
 
 // 完了状態の切り替え
@@ -263,12 +257,8 @@ func (h *TodoHandler) ToggleCompleted(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 更新されたTODOアイテムのHTMLを返す
-	w.Header().Set("HX-Trigger", "todoToggled")
-	w.Header().Set("Content-Type", "text/html")
-	err = templates.TodoItem(*todo).Render(r.Context(), w)
-	if err != nil {
-		h.sendError(w, "テンプレートの実行に失敗しました", http.StatusInternalServerError)
-	}
+	htmx.TriggerEvent(w, "todoToggled")
+	h.renderWithStats(w, r, templates.TodoItem(*todo))
 }
 
 // 編集フォーム表示
@@ -331,10 +321,27 @@ func (h *TodoHandler) Show(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// 変更後のHTMLと、Out of Band スワップで更新する統計情報をまとめて返す
+func (h *TodoHandler) renderWithStats(w http.ResponseWriter, r *http.Request, main templ.Component) {
+	stats, err := h.repo.GetStats()
+	if err != nil {
+		h.sendError(w, "統計情報の取得に失敗しました", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	err = htmx.NewOOBWriter(main).
+		Swap(htmx.SwapInnerHTML, "#todo-stats", templates.TodoStats(stats)).
+		Render(r.Context(), w)
+	if err != nil {
+		h.sendError(w, "テンプレートの実行に失敗しました", http.StatusInternalServerError)
+	}
+}
+
 // エラーレスポンスの送信
 func (h *TodoHandler) sendError(w http.ResponseWriter, message string, status int) {
-	w.Header().Set("HX-Retarget", "#error-message")
-	w.Header().Set("HX-Reswap", "innerHTML")
+	htmx.Retarget(w, "#error-message")
+	htmx.Reswap(w, htmx.SwapInnerHTML)
 	w.WriteHeader(status)
 	errorHTML := `<div class="bg-red-100 dark:bg-red-900 border border-red-400 dark:border-red-600 text-red-700 dark:text-red-300 px-4 py-3 rounded relative" x-data x-init="setTimeout(() => $el.remove(), 5000)">` + message + `</div>`
 	w.Write([]byte(errorHTML))
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -62,38 +63,106 @@ func (r *TodoRepository) InitSchema() error {
 
 // 検索とフィルタリング
 func (r *TodoRepository) List(filter string, search string) ([]Todo, error) {
+	where, args := todoConditions(filter, search, "")
 	query := `
 	SELECT id, title, description, completed, priority, due_date, created_at, updated_at 
 	FROM todos 
-	WHERE 1=1
-	`
+	WHERE ` + where + " ORDER BY completed ASC, priority DESC, created_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTodos(rows)
+}
+
+// TodoQuery は一覧表の1ページ分の取得条件
+type TodoQuery struct {
+	Filter   string // List と同じ: active, completed, overdue
+	Search   string
+	Priority string
+	Sort     string // title, priority, due_date, created_at
+	Desc     bool
+	Limit    int
+	Offset   int
+}
+
+// 並べ替えに使える列。優先度は高・中・低の順になるよう数値に置き換える
+var todoSortColumns = map[string]string{
+	"title":      "title",
+	"priority":   "CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 ELSE 1 END",
+	"due_date":   "due_date",
+	"created_at": "created_at",
+}
+
+// 条件に合うTODOの1ページ分と、条件に合う総件数を取得
+func (r *TodoRepository) Page(ctx context.Context, q TodoQuery) ([]Todo, int, error) {
+	where, args := todoConditions(q.Filter, q.Search, q.Priority)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM todos WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	order := "created_at"
+	if column, ok := todoSortColumns[q.Sort]; ok {
+		order = column
+	}
+	if q.Desc {
+		order += " DESC"
+	}
+	query := `
+	SELECT id, title, description, completed, priority, due_date, created_at, updated_at 
+	FROM todos 
+	WHERE ` + where + " ORDER BY " + order + ", id LIMIT ? OFFSET ?"
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, q.Limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	todos, err := scanTodos(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return todos, total, nil
+}
+
+// 絞り込み条件のWHERE句と引数
+func todoConditions(filter, search, priority string) (string, []interface{}) {
+	where := "1=1"
 	args := []interface{}{}
 
 	// フィルター条件の追加
 	switch filter {
 	case "active":
-		query += " AND completed = FALSE"
+		where += " AND completed = FALSE"
 	case "completed":
-		query += " AND completed = TRUE"
+		where += " AND completed = TRUE"
 	case "overdue":
-		query += " AND due_date < datetime('now') AND completed = FALSE"
+		where += " AND due_date < datetime('now') AND completed = FALSE"
 	}
 
 	// 検索条件の追加
 	if search != "" {
-		query += " AND (title LIKE ? OR description LIKE ?)"
+		where += " AND (title LIKE ? OR description LIKE ?)"
 		searchPattern := "%" + search + "%"
 		args = append(args, searchPattern, searchPattern)
 	}
 
-	query += " ORDER BY completed ASC, priority DESC, created_at DESC"
-
-	rows, err := r.db.Query(query, args...)
-	if err != nil {
-		return nil, err
+	if priority != "" {
+		where += " AND priority = ?"
+		args = append(args, priority)
 	}
-	defer rows.Close()
 
+	return where, args
+}
+
+// 検索結果の行をTODOに変換
+func scanTodos(rows *sql.Rows) ([]Todo, error) {
 	var todos []Todo
 	for rows.Next() {
 		var todo Todo
@@ -108,7 +177,7 @@ func (r *TodoRepository) List(filter string, search string) ([]Todo, error) {
 		todos = append(todos, todo)
 	}
 
-	return todos, nil
+	return todos, rows.Err()
 }
 
 // TODO作成
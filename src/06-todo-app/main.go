@@ -39,6 +39,7 @@ func main() {
 	r.HandleFunc("/todos/{id:[0-9]+}", todoHandler.Show).Methods("GET")
 	r.HandleFunc("/todos/{id:[0-9]+}/edit", todoHandler.Edit).Methods("GET")
 	r.HandleFunc("/todos/{id:[0-9]+}/toggle", todoHandler.ToggleCompleted).Methods("PATCH")
+	r.HandleFunc("/table", todoHandler.Table).Methods("GET")
 
 	// 静的ファイルの配信
 	r.PathPrefix("/static/").Handler(http.StripPrefix("/static/", http.FileServer(http.Dir("static/"))))
@@ -53,6 +54,7 @@ func main() {
 	fmt.Println("  • 優先度と期限の設定")
 	fmt.Println("  • フィルタリング（全て・未完了・完了・期限切れ）")
 	fmt.Println("  • リアルタイム検索")
+	fmt.Println("  • 一覧表（並べ替え・絞り込み・ページ送り）")
 	fmt.Println("  • ダークモード対応")
 	fmt.Println("  • レスポンシブデザイン")
 	fmt.Println("🛑 終了するには Ctrl+C を押してください")
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/gorilla/mux"
+	"shared/htmx"
 	"test-debug-demo/internal/logger"
 	"test-debug-demo/internal/models"
 	"test-debug-demo/internal/templates"
@@ -65,7 +66,7 @@ func (h *TodoHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// HTMXリクエストの場合はパーシャルHTMLを返す
-	if r.Header.Get("HX-Request") == "true" {
+	if htmx.IsHTMX(r) {
 		w.Header().Set("Content-Type", "text/html")
 		err = templates.TodoList(todos).Render(r.Context(), w)
 		if err != nil {
@@ -109,7 +110,7 @@ func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	if len(errors) > 0 {
 		// HTMXリクエストの場合はエラー表示
-		if r.Header.Get("HX-Request") == "true" {
+		if htmx.IsHTMX(r) {
 			w.WriteHeader(http.StatusBadRequest)
 			w.Header().Set("Content-Type", "text/html")
 			err = templates.ValidationErrors(errors).Render(r.Context(), w)
@@ -147,7 +148,7 @@ func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 			"error":       err.Error(),
 		}, getTraceID(r.Context()))
 
-		if r.Header.Get("HX-Request") == "true" {
+		if htmx.IsHTMX(r) {
 			w.WriteHeader(http.StatusInternalServerError)
 			w.Header().Set("Content-Type", "text/html")
 			fmt.Fprintf(w, `<div class="bg-red-100 border border-red-400 text-red-700 px-4 py-3 rounded">TODOの作成に失敗しました</div>`)
@@ -165,7 +166,7 @@ func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}, getTraceID(r.Context()))
 
 	// HTMXリクエストの場合は新しいTODOアイテムを返す
-	if r.Header.Get("HX-Request") == "true" {
+	if htmx.IsHTMX(r) {
 		// 作成されたTODOを取得
 		createdTodo, err := h.repo.GetByID(id)
 		if err != nil {
@@ -178,7 +179,7 @@ func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 
 		w.Header().Set("Content-Type", "text/html")
-		w.Header().Set("HX-Trigger", "todoCreated")
+		htmx.TriggerEvent(w, "todoCreated")
 		err = templates.TodoItem(createdTodo).Render(r.Context(), w)
 		if err != nil {
 			h.logger.Error("Template render failed", map[string]interface{}{
@@ -253,9 +254,9 @@ func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}, getTraceID(r.Context()))
 
 	// HTMXリクエストの場合は更新されたアイテムを返す
-	if r.Header.Get("HX-Request") == "true" {
+	if htmx.IsHTMX(r) {
 		w.Header().Set("Content-Type", "text/html")
-		w.Header().Set("HX-Trigger", "todoUpdated")
+		htmx.TriggerEvent(w, "todoUpdated")
 		err = templates.TodoItem(todo).Render(r.Context(), w)
 		if err != nil {
 			h.logger.Error("Template render failed", map[string]interface{}{
@@ -297,8 +298,8 @@ func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}, getTraceID(r.Context()))
 
 	// HTMXリクエストの場合は空のレスポンス（要素が削除される）
-	if r.Header.Get("HX-Request") == "true" {
-		w.Header().Set("HX-Trigger", "todoDeleted")
+	if htmx.IsHTMX(r) {
+		htmx.TriggerEvent(w, "todoDeleted")
 		w.WriteHeader(http.StatusOK)
 		return
 	}
@@ -340,9 +341,9 @@ func (h *TodoHandler) ToggleComplete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// HTMXリクエストの場合は更新されたアイテムを返す
-	if r.Header.Get("HX-Request") == "true" {
+	if htmx.IsHTMX(r) {
 		w.Header().Set("Content-Type", "text/html")
-		w.Header().Set("HX-Trigger", "todoToggled")
+		htmx.TriggerEvent(w, "todoToggled")
 		err = templates.TodoItem(todo).Render(r.Context(), w)
 		if err != nil {
 			h.logger.Error("Template render failed", map[string]interface{}{
@@ -8,6 +8,8 @@ import (
 	"runtime/debug"
 	"strings"
 	"time"
+
+	"shared/htmx"
 )
 
 type debugResponseWriter struct {
@@ -158,10 +160,10 @@ func ErrorHandler(isDev bool) func(http.Handler) http.Handler {
 						appErr.Error(), r.Method, r.URL.Path, appErr.StackTrace)
 
 					// エラーレスポンス
-					if r.Header.Get("HX-Request") == "true" {
+					if htmx.IsHTMX(r) {
 						// HTMXエラーレスポンス
-						w.Header().Set("HX-Retarget", "#error-container")
-						w.Header().Set("HX-Reswap", "innerHTML")
+						htmx.Retarget(w, "#error-container")
+						htmx.Reswap(w, htmx.SwapInnerHTML)
 					}
 
 					w.WriteHeader(appErr.StatusCode)
@@ -245,9 +247,9 @@ func RequestLogger(isDev bool) func(http.Handler) http.Handler {
 				logEntry += fmt.Sprintf(" | UserAgent: %s | RemoteAddr: %s", 
 					r.UserAgent(), r.RemoteAddr)
 				
-				if r.Header.Get("HX-Request") == "true" {
+				if htmx.IsHTMX(r) {
 					logEntry += " | HTMX: true"
-					if target := r.Header.Get("HX-Target"); target != "" {
+					if target := htmx.Target(r); target != "" {
 						logEntry += fmt.Sprintf(" | Target: %s", target)
 					}
 				}
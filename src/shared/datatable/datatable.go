@@ -0,0 +1,389 @@
+// Package datatable renders paged, sortable and filterable tables on the
+// server. A Table describes the columns of a row type and reads its state,
+// i.e. page, page size, sort and filters, from the query string, so every
+// view of a table has a URL of its own. Rows come from any Source.
+//
+// The controls of a rendered table request the same URL with htmx and swap
+// in the results alone; without JavaScript they are plain links and a GET
+// form.
+package datatable
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"shared/htmx"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// Query string parameters of a table. Filters use FilterPrefix followed by
+// the column key.
+const (
+	ParamPage     = "page"
+	ParamPageSize = "page_size"
+	ParamSort     = "sort"
+	ParamOrder    = "order"
+	FilterPrefix  = "filter_"
+)
+
+// MaxPage is the highest page a query asks for, so that the offset of a page
+// cannot overflow. Load shows the last page for pages past the end anyway.
+const MaxPage = 1000000
+
+// Order is a sort direction
+type Order string
+
+const (
+	Asc  Order = "asc"
+	Desc Order = "desc"
+)
+
+// Query is the state of a table requested by a visitor. Sort and Filters
+// only name columns allowing it.
+type Query struct {
+	Page     int // 1-based
+	PageSize int
+	Sort     string // column key, "" for the order of the source
+	Order    Order
+	Filters  map[string]string // non-empty values by column key
+}
+
+// Offset is the number of rows before the page
+func (q Query) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// Filter returns the filter value of the column key, or ""
+func (q Query) Filter(key string) string {
+	return q.Filters[key]
+}
+
+// Values encodes q as query string parameters
+func (q Query) Values() url.Values {
+	values := url.Values{}
+	if q.Page > 1 {
+		values.Set(ParamPage, strconv.Itoa(q.Page))
+	}
+	values.Set(ParamPageSize, strconv.Itoa(q.PageSize))
+	if q.Sort != "" {
+		values.Set(ParamSort, q.Sort)
+		values.Set(ParamOrder, string(q.Order))
+	}
+	for key, value := range q.Filters {
+		values.Set(FilterPrefix+key, value)
+	}
+	return values
+}
+
+// Result is a page of rows and the number of rows matching the filters
+type Result[T any] struct {
+	Rows  []T
+	Total int
+}
+
+// Source provides the rows of a table. It applies the filters, sort and
+// page of the query itself, typically in a database query.
+type Source[T any] interface {
+	Query(ctx context.Context, q Query) (Result[T], error)
+}
+
+// SourceFunc adapts a function to a Source
+type SourceFunc[T any] func(ctx context.Context, q Query) (Result[T], error)
+
+// Query calls f
+func (f SourceFunc[T]) Query(ctx context.Context, q Query) (Result[T], error) {
+	return f(ctx, q)
+}
+
+// Option is a choice of a filter rendered as a select
+type Option struct {
+	Value string
+	Label string
+}
+
+// Column describes a column of rows of type T
+type Column[T any] struct {
+	Key   string // names the column in the query string
+	Label string
+	// Text formats the value of a row; Cell replaces it for cells with
+	// markup
+	Text func(row T) string
+	Cell func(row T) templ.Component
+	// Class is added to the cells of the column, e.g. for alignment
+	Class    string
+	Sortable bool
+	// Filterable columns get a text input, or a select with Options
+	Filterable bool
+	Options    []Option
+}
+
+// Table describes a table of rows of type T
+type Table[T any] struct {
+	// ID identifies the table in the page; its results are swapped into
+	// the element ID + "-results"
+	ID string
+	// URL serves the table, usually the page containing it
+	URL     string
+	Columns []Column[T]
+	// PageSizes offered to the visitor; the first is the default
+	PageSizes []int
+	// Sort and Order apply when the query string names no sortable column
+	Sort  string
+	Order Order
+	// Empty is shown when no row matches
+	Empty string
+}
+
+// DefaultPageSizes are used by tables without PageSizes
+var DefaultPageSizes = []int{10, 25, 50}
+
+func (t *Table[T]) pageSizes() []int {
+	if len(t.PageSizes) == 0 {
+		return DefaultPageSizes
+	}
+	return t.PageSizes
+}
+
+func (t *Table[T]) column(key string) (Column[T], bool) {
+	for _, c := range t.Columns {
+		if c.Key == key {
+			return c, true
+		}
+	}
+	return Column[T]{}, false
+}
+
+// Query reads the state of the table from values. Unknown columns, page
+// sizes that are not offered and malformed pages fall back to the defaults;
+// pages above MaxPage become MaxPage.
+func (t *Table[T]) Query(values url.Values) Query {
+	sizes := t.pageSizes()
+	q := Query{Page: 1, PageSize: sizes[0], Sort: t.Sort, Order: t.Order}
+
+	if page, err := strconv.Atoi(values.Get(ParamPage)); err == nil && page > 1 {
+		q.Page = min(page, MaxPage)
+	}
+	if size, err := strconv.Atoi(values.Get(ParamPageSize)); err == nil {
+		for _, s := range sizes {
+			if s == size {
+				q.PageSize = size
+			}
+		}
+	}
+	if c, ok := t.column(values.Get(ParamSort)); ok && c.Sortable {
+		q.Sort = c.Key
+		q.Order = Order(values.Get(ParamOrder))
+	}
+	if q.Order != Desc {
+		q.Order = Asc
+	}
+
+	for _, c := range t.Columns {
+		if !c.Filterable {
+			continue
+		}
+		value := strings.TrimSpace(values.Get(FilterPrefix + c.Key))
+		if value == "" {
+			continue
+		}
+		if c.Options != nil && !hasOption(c.Options, value) {
+			continue
+		}
+		if q.Filters == nil {
+			q.Filters = make(map[string]string)
+		}
+		q.Filters[c.Key] = value
+	}
+	return q
+}
+
+func hasOption(options []Option, value string) bool {
+	for _, o := range options {
+		if o.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Load queries source for the state in values and returns the view to
+// render. A page past the end shows the last page instead.
+func (t *Table[T]) Load(ctx context.Context, source Source[T], values url.Values) (*View, error) {
+	q := t.Query(values)
+	result, err := source.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if last := pageCount(result.Total, q.PageSize); q.Page > last {
+		q.Page = last
+		if result, err = source.Query(ctx, q); err != nil {
+			return nil, err
+		}
+	}
+
+	view := &View{
+		ID:        t.ID,
+		URL:       t.URL,
+		Query:     q,
+		Total:     result.Total,
+		PageSizes: t.pageSizes(),
+		Empty:     t.Empty,
+	}
+	for _, c := range t.Columns {
+		view.Headings = append(view.Headings, Heading{
+			Key:        c.Key,
+			Label:      c.Label,
+			Class:      c.Class,
+			Sortable:   c.Sortable,
+			Filterable: c.Filterable,
+			Options:    c.Options,
+		})
+	}
+	for _, row := range result.Rows {
+		cells := make([]templ.Component, len(t.Columns))
+		for i, c := range t.Columns {
+			switch {
+			case c.Cell != nil:
+				cells[i] = c.Cell(row)
+			case c.Text != nil:
+				cells[i] = text(c.Text(row))
+			default:
+				cells[i] = templ.NopComponent
+			}
+		}
+		view.Rows = append(view.Rows, cells)
+	}
+	return view, nil
+}
+
+// Heading is a column of a View
+type Heading struct {
+	Key        string
+	Label      string
+	Class      string
+	Sortable   bool
+	Filterable bool
+	Options    []Option
+}
+
+// View is a loaded table, ready to render with Component
+type View struct {
+	ID        string
+	URL       string
+	Headings  []Heading
+	Rows      [][]templ.Component
+	Query     Query
+	Total     int
+	PageSizes []int
+	Empty     string
+}
+
+// ResultsID is the id of the element the controls swap
+func (v *View) ResultsID() string {
+	return v.ID + "-results"
+}
+
+// Pages is the number of pages, at least 1
+func (v *View) Pages() int {
+	return pageCount(v.Total, v.Query.PageSize)
+}
+
+func pageCount(total, size int) int {
+	if total <= size {
+		return 1
+	}
+	return (total + size - 1) / size
+}
+
+// First is the number of the first row shown, counting from 1
+func (v *View) First() int {
+	if v.Total == 0 {
+		return 0
+	}
+	return v.Query.Offset() + 1
+}
+
+// Last is the number of the last row shown
+func (v *View) Last() int {
+	return v.Query.Offset() + len(v.Rows)
+}
+
+// link returns the URL of the table in state q
+func (v *View) link(q Query) string {
+	return v.URL + "?" + q.Values().Encode()
+}
+
+// PageURL links to page of the current view
+func (v *View) PageURL(page int) string {
+	q := v.Query
+	q.Page = page
+	return v.link(q)
+}
+
+// SortURL links to the first page sorted by the column key, ascending
+// unless it is already
+func (v *View) SortURL(key string) string {
+	q := v.Query
+	q.Page = 1
+	q.Order = Asc
+	if q.Sort == key && v.Query.Order == Asc {
+		q.Order = Desc
+	}
+	q.Sort = key
+	return v.link(q)
+}
+
+// AriaSort is the aria-sort value of the column key
+func (v *View) AriaSort(key string) string {
+	if v.Query.Sort != key {
+		return "none"
+	}
+	if v.Query.Order == Desc {
+		return "descending"
+	}
+	return "ascending"
+}
+
+// PageNumbers returns the pages linked from the pagination controls: the
+// first, the last and those around the current one. Gaps are 0; a gap of
+// one page shows the page instead.
+func (v *View) PageNumbers() []int {
+	pages := v.Pages()
+	var numbers []int
+	for p := 1; p <= pages; p++ {
+		if p > 1 && p < pages && (p < v.Query.Page-2 || p > v.Query.Page+2) {
+			continue
+		}
+		if len(numbers) > 0 {
+			switch previous := numbers[len(numbers)-1]; {
+			case p-previous == 2:
+				numbers = append(numbers, p-1)
+			case p-previous > 2:
+				numbers = append(numbers, 0)
+			}
+		}
+		numbers = append(numbers, p)
+	}
+	return numbers
+}
+
+// Refresh reports whether r was made by the controls of the table, which
+// swap in its results alone
+func (v *View) Refresh(r *http.Request) bool {
+	return htmx.IsHTMX(r) && !htmx.HistoryRestore(r) && htmx.Target(r) == v.ResultsID()
+}
+
+// Render answers r with the results of the table when its controls made
+// the request, and with page otherwise. page is the document containing
+// Component(v).
+func Render(w http.ResponseWriter, r *http.Request, v *View, page templ.Component) error {
+	w.Header().Add("Vary", htmx.HeaderRequest)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if v.Refresh(r) {
+		return Results(v).Render(r.Context(), w)
+	}
+	return page.Render(r.Context(), w)
+}
@@ -0,0 +1,193 @@
+package datatable
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+type item struct {
+	ID   int
+	Name string
+	Kind string
+}
+
+// items is a Source over a slice, filtering and sorting like a database
+// would
+func items(rows []item) Source[item] {
+	return SourceFunc[item](func(ctx context.Context, q Query) (Result[item], error) {
+		var matched []item
+		for _, row := range rows {
+			if name := q.Filter("name"); name != "" && !strings.Contains(row.Name, name) {
+				continue
+			}
+			if kind := q.Filter("kind"); kind != "" && row.Kind != kind {
+				continue
+			}
+			matched = append(matched, row)
+		}
+		if q.Sort == "name" {
+			sort.SliceStable(matched, func(i, j int) bool {
+				if q.Order == Desc {
+					return matched[i].Name > matched[j].Name
+				}
+				return matched[i].Name < matched[j].Name
+			})
+		}
+		end := min(q.Offset()+q.PageSize, len(matched))
+		return Result[item]{Rows: matched[min(q.Offset(), end):end], Total: len(matched)}, nil
+	})
+}
+
+func testTable() *Table[item] {
+	return &Table[item]{
+		ID:  "items",
+		URL: "/items",
+		Columns: []Column[item]{
+			{Key: "id", Label: "ID", Text: func(i item) string { return fmt.Sprint(i.ID) }},
+			{Key: "name", Label: "名前", Text: func(i item) string { return i.Name }, Sortable: true, Filterable: true},
+			{Key: "kind", Label: "種類", Text: func(i item) string { return i.Kind }, Filterable: true, Options: []Option{{"a", "A"}, {"b", "B"}}},
+		},
+		PageSizes: []int{2, 5},
+	}
+}
+
+func testItems() []item {
+	var rows []item
+	for i, name := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+		rows = append(rows, item{ID: i + 1, Name: name, Kind: []string{"a", "b"}[i%2]})
+	}
+	return rows
+}
+
+func TestTable_Query(t *testing.T) {
+	table := testTable()
+	tests := []struct {
+		query string
+		want  Query
+	}{
+		{"", Query{Page: 1, PageSize: 2, Order: Asc}},
+		{"page=3&page_size=5&sort=name&order=desc", Query{Page: 3, PageSize: 5, Sort: "name", Order: Desc}},
+		{"page=-1&page_size=1000&sort=id&order=sideways", Query{Page: 1, PageSize: 2, Order: Asc}},
+		{"page=1000000000000000000", Query{Page: MaxPage, PageSize: 2, Order: Asc}},
+		{"filter_name=+al+&filter_kind=b&filter_id=1", Query{Page: 1, PageSize: 2, Order: Asc, Filters: map[string]string{"name": "al", "kind": "b"}}},
+		{"filter_kind=z&filter_name=", Query{Page: 1, PageSize: 2, Order: Asc}},
+	}
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		got := table.Query(values)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("Query(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTable_Load(t *testing.T) {
+	table := testTable()
+	source := items(testItems())
+
+	v, err := table.Load(context.Background(), source, url.Values{"sort": {"name"}, "order": {"desc"}, "page": {"2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Total != 5 || v.Pages() != 3 || v.First() != 3 || v.Last() != 4 {
+		t.Errorf("total %d, pages %d, rows %d-%d", v.Total, v.Pages(), v.First(), v.Last())
+	}
+	if got := cellText(t, v.Rows[0][1]); got != "charlie" {
+		t.Errorf("first row of page 2 = %s", got)
+	}
+	if got := v.SortURL("name"); got != "/items?order=asc&page_size=2&sort=name" {
+		t.Errorf("SortURL = %s", got)
+	}
+	if got := v.PageURL(3); got != "/items?order=desc&page=3&page_size=2&sort=name" {
+		t.Errorf("PageURL = %s", got)
+	}
+	if got := v.AriaSort("name"); got != "descending" {
+		t.Errorf("AriaSort = %s", got)
+	}
+
+	t.Run("page past the end shows the last page", func(t *testing.T) {
+		v, err := table.Load(context.Background(), source, url.Values{"page": {"9"}, "filter_kind": {"a"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Query.Page != 2 || len(v.Rows) != 1 {
+			t.Errorf("page %d with %d rows", v.Query.Page, len(v.Rows))
+		}
+	})
+}
+
+func TestView_PageNumbers(t *testing.T) {
+	tests := []struct {
+		page, total int
+		want        []int
+	}{
+		{1, 0, []int{1}},
+		{1, 10, []int{1, 2, 3, 4, 5}},
+		{5, 20, []int{1, 2, 3, 4, 5, 6, 7, 0, 10}},
+		{6, 24, []int{1, 0, 4, 5, 6, 7, 8, 0, 12}},
+		{10, 20, []int{1, 0, 8, 9, 10}},
+	}
+	for _, tt := range tests {
+		v := &View{Query: Query{Page: tt.page, PageSize: 2}, Total: tt.total}
+		if got := v.PageNumbers(); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("page %d of %d rows: %v, want %v", tt.page, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	table := testTable()
+	rows := append(testItems(), item{ID: 6, Name: `<script>alert(1)</script>`, Kind: "a"})
+	v, err := table.Load(context.Background(), items(rows), url.Values{"filter_name": {"<script>"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		io.WriteString(w, "<!DOCTYPE html>")
+		return Component(v).Render(ctx, w)
+	})
+
+	rr := httptest.NewRecorder()
+	if err := Render(rr, httptest.NewRequest("GET", "/items", nil), v, page); err != nil {
+		t.Fatal(err)
+	}
+	body := rr.Body.String()
+	if !strings.HasPrefix(body, "<!DOCTYPE html>") || !strings.Contains(body, `name="filter_name" value="&lt;script&gt;"`) {
+		t.Errorf("page missing the filters: %s", body)
+	}
+	if strings.Contains(body, "<script>") || !strings.Contains(body, "&lt;script&gt;alert(1)&lt;/script&gt;") {
+		t.Errorf("cell not escaped: %s", body)
+	}
+	if rr.Header().Get("Vary") != "HX-Request" {
+		t.Errorf("Vary = %q", rr.Header().Get("Vary"))
+	}
+
+	req := httptest.NewRequest("GET", "/items?filter_name=%3Cscript%3E", nil)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Target", v.ResultsID())
+	rr = httptest.NewRecorder()
+	if err := Render(rr, req, v, page); err != nil {
+		t.Fatal(err)
+	}
+	if body := rr.Body.String(); !strings.HasPrefix(body, `<div id="items-results"`) || strings.Contains(body, "<form") {
+		t.Errorf("refresh should render the results alone: %s", body)
+	}
+}
+
+// cellText renders a cell
+func cellText(t *testing.T, c templ.Component) string {
+	t.Helper()
+	var b strings.Builder
+	if err := c.Render(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,5 @@
+module shared
+
+go 1.24.3
+
+require github.com/a-h/templ v0.3.887
@@ -0,0 +1,211 @@
+package htmx
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	if IsHTMX(r) || Boosted(r) || Target(r) != "" {
+		t.Fatal("plain request reported as htmx")
+	}
+
+	r.Header.Set(HeaderRequest, "true")
+	r.Header.Set(HeaderBoosted, "true")
+	r.Header.Set(HeaderTarget, "todo-list")
+	r.Header.Set(HeaderTrigger, "search")
+	r.Header.Set(HeaderCurrentURL, "http://localhost/?filter=active")
+	if !IsHTMX(r) || !Boosted(r) || Target(r) != "todo-list" || Trigger(r) != "search" || CurrentURL(r) != "http://localhost/?filter=active" {
+		t.Errorf("headers not read: %v", r.Header)
+	}
+}
+
+func TestTriggerEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(w http.ResponseWriter) error
+		want  string
+	}{
+		{
+			name:  "single event",
+			apply: func(w http.ResponseWriter) error { TriggerEvent(w, "todoAdded"); return nil },
+			want:  "todoAdded",
+		},
+		{
+			name: "events are combined",
+			apply: func(w http.ResponseWriter) error {
+				TriggerEvent(w, "todoAdded")
+				TriggerEvent(w, "statsChanged")
+				TriggerEvent(w, "todoAdded")
+				return nil
+			},
+			want: "todoAdded, statsChanged",
+		},
+		{
+			name: "detail switches to JSON",
+			apply: func(w http.ResponseWriter) error {
+				TriggerEvent(w, "todoAdded")
+				return TriggerEventWithDetail(w, "showMessage", map[string]string{"level": "info", "text": `"quoted" <b>`})
+			},
+			want: `{"todoAdded":null,"showMessage":{"level":"info","text":"\"quoted\" \u003cb\u003e"}}`,
+		},
+		{
+			name: "names after JSON stay JSON",
+			apply: func(w http.ResponseWriter) error {
+				if err := TriggerEventWithDetail(w, "count", 3); err != nil {
+					return err
+				}
+				TriggerEvent(w, "done")
+				return nil
+			},
+			want: `{"count":3,"done":null}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			if err := tt.apply(rr); err != nil {
+				t.Fatalf("error: %v", err)
+			}
+			if got := rr.Header().Get(HeaderTrigger); got != tt.want {
+				t.Errorf("HX-Trigger = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	if err := TriggerEventWithDetail(rr, "bad", func() {}); err == nil {
+		t.Error("unencodable detail should fail")
+	}
+	if err := TriggerAfterSettle(rr, "settled", nil); err != nil || rr.Header().Get(HeaderTriggerAfterSettle) != "settled" {
+		t.Errorf("after settle = %q, %v", rr.Header().Get(HeaderTriggerAfterSettle), err)
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	rr := httptest.NewRecorder()
+	PushURL(rr, "/?filter=active")
+	Retarget(rr, "#error-message")
+	Reswap(rr, SwapInnerHTML)
+	Refresh(rr)
+
+	want := map[string]string{
+		HeaderPushURL:  "/?filter=active",
+		HeaderRetarget: "#error-message",
+		HeaderReswap:   "innerHTML",
+		HeaderRefresh:  "true",
+	}
+	for key, value := range want {
+		if got := rr.Header().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
+
+func text(s string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	})
+}
+
+func TestOOBWriter(t *testing.T) {
+	var b strings.Builder
+	err := NewOOBWriter(text("<li>item</li>")).
+		Swap(SwapInnerHTML, "#stats", text("<span>3</span>")).
+		Swap(SwapBeforeEnd, `#log[data-x="1"]`, text("<p>log</p>")).
+		Add(text(`<div id="flash" hx-swap-oob="true">saved</div>`)).
+		Render(context.Background(), &b)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	want := `<li>item</li>` +
+		`<div hx-swap-oob="innerHTML:#stats"><span>3</span></div>` +
+		`<div hx-swap-oob="beforeend:#log[data-x=&#34;1&#34;]"><p>log</p></div>` +
+		`<div id="flash" hx-swap-oob="true">saved</div>`
+	if b.String() != want {
+		t.Errorf("got  %s\nwant %s", b.String(), want)
+	}
+
+	b.Reset()
+	if err := NewOOBWriter(nil).Swap(SwapInnerHTML, "#stats", text("0")).Render(context.Background(), &b); err != nil || b.String() != `<div hx-swap-oob="innerHTML:#stats">0</div>` {
+		t.Errorf("OOB only = %q, %v", b.String(), err)
+	}
+}
+
+func TestRenderPage(t *testing.T) {
+	layout := func(title string) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			io.WriteString(w, "<html><title>"+title+"</title><main>")
+			if err := templ.GetChildren(ctx).Render(ctx, w); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "</main></html>")
+			return err
+		})
+	}
+	page := Page{Title: "A & B", Content: text("<p>content</p>"), Layout: layout, Target: "#content"}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		body    string
+		header  map[string]string
+	}{
+		{"plain", nil, `<html><title>A & B</title><main><p>content</p></main></html>`,
+			map[string]string{HeaderPushURL: "", HeaderRetarget: ""}},
+		{"history restore", map[string]string{HeaderRequest: "true", HeaderHistoryRestore: "true"}, `<html><title>A & B</title><main><p>content</p></main></html>`,
+			map[string]string{HeaderPushURL: ""}},
+		{"htmx", map[string]string{HeaderRequest: "true"}, `<title>A &amp; B</title><p>content</p>`,
+			map[string]string{HeaderPushURL: "/page?tab=2", HeaderRetarget: ""}},
+		{"boosted", map[string]string{HeaderRequest: "true", HeaderBoosted: "true"}, `<title>A &amp; B</title><p>content</p>`,
+			map[string]string{HeaderPushURL: "/page?tab=2", HeaderRetarget: "#content", HeaderReswap: SwapBoosted}},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/page?tab=2", nil)
+		for key, value := range tt.headers {
+			r.Header.Set(key, value)
+		}
+		rr := httptest.NewRecorder()
+		if err := RenderPage(rr, r, http.StatusOK, page); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if rr.Body.String() != tt.body {
+			t.Errorf("%s: body = %s", tt.name, rr.Body.String())
+		}
+		for key, want := range tt.header {
+			if got := rr.Header().Get(key); got != want {
+				t.Errorf("%s: %s = %q, want %q", tt.name, key, got, want)
+			}
+		}
+		if rr.Header().Get("Vary") != HeaderRequest {
+			t.Errorf("%s: Vary = %q", tt.name, rr.Header().Get("Vary"))
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/page", nil)
+	r.Header.Set(HeaderRequest, "true")
+	rr := httptest.NewRecorder()
+	RenderPage(rr, r, http.StatusUnprocessableEntity, page)
+	if rr.Code != http.StatusUnprocessableEntity || rr.Header().Get(HeaderPushURL) != "" {
+		t.Errorf("POST: status %d, pushed %q", rr.Code, rr.Header().Get(HeaderPushURL))
+	}
+
+	replacing := page
+	replacing.Replace = true
+	r = httptest.NewRequest("GET", "/search?q=go", nil)
+	r.Header.Set(HeaderRequest, "true")
+	rr = httptest.NewRecorder()
+	RenderPage(rr, r, http.StatusOK, replacing)
+	if rr.Header().Get(HeaderReplaceURL) != "/search?q=go" || rr.Header().Get(HeaderPushURL) != "" {
+		t.Errorf("replacing page: replaced %q, pushed %q", rr.Header().Get(HeaderReplaceURL), rr.Header().Get(HeaderPushURL))
+	}
+}
@@ -0,0 +1,81 @@
+package htmx
+
+import (
+	"context"
+	"html"
+	"io"
+
+	"github.com/a-h/templ"
+)
+
+// OOBWriter combines a main fragment with out-of-band fragments into one
+// response. htmx swaps the main fragment into the request's target and
+// each out-of-band fragment into the element it names.
+//
+// OOBWriter implements templ.Component, so it renders wherever a component
+// does.
+type OOBWriter struct {
+	main templ.Component
+	oob  []oobFragment
+}
+
+type oobFragment struct {
+	swap      string
+	selector  string
+	component templ.Component
+}
+
+// NewOOBWriter creates a writer with main as the regular response. main may
+// be nil when the response consists of out-of-band fragments only.
+func NewOOBWriter(main templ.Component) *OOBWriter {
+	return &OOBWriter{main: main}
+}
+
+// Swap adds component to be swapped into the element matching selector
+// using swap, e.g. Swap(SwapInnerHTML, "#stats", Stats(stats)).
+//
+// The component is wrapped in a div carrying hx-swap-oob, so it must not be
+// table content such as a <tr>; render hx-swap-oob on the element itself
+// and use Add for those.
+func (o *OOBWriter) Swap(swap, selector string, component templ.Component) *OOBWriter {
+	o.oob = append(o.oob, oobFragment{swap: swap, selector: selector, component: component})
+	return o
+}
+
+// Add adds a component that already carries its own hx-swap-oob attribute
+func (o *OOBWriter) Add(component templ.Component) *OOBWriter {
+	o.oob = append(o.oob, oobFragment{component: component})
+	return o
+}
+
+// Render writes the main fragment followed by the out-of-band fragments
+func (o *OOBWriter) Render(ctx context.Context, w io.Writer) error {
+	if o.main != nil {
+		if err := o.main.Render(ctx, w); err != nil {
+			return err
+		}
+	}
+
+	for _, fragment := range o.oob {
+		if fragment.selector == "" {
+			if err := fragment.component.Render(ctx, w); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// For swap styles other than outerHTML htmx swaps the wrapper's
+		// children, so the wrapper itself never reaches the page
+		value := html.EscapeString(fragment.swap + ":" + fragment.selector)
+		if _, err := io.WriteString(w, `<div hx-swap-oob="`+value+`">`); err != nil {
+			return err
+		}
+		if err := fragment.component.Render(ctx, w); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, `</div>`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
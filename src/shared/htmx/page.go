@@ -0,0 +1,70 @@
+package htmx
+
+import (
+	"html"
+	"io"
+	"net/http"
+
+	"github.com/a-h/templ"
+)
+
+// SwapBoosted swaps boosted navigation into the layout. Settling at once
+// runs the scripts of the content before libraries watching the DOM for
+// new elements, such as Alpine.js, initialize it.
+const SwapBoosted = SwapInnerHTML + " show:window:top settle:0ms"
+
+// Page is what a route renders: its content and the layout that turns the
+// content into a complete document
+type Page struct {
+	Title   string
+	Content templ.Component
+	// Layout renders the document titled title around its children, e.g.
+	// a templ layout like Base(title string) that renders { children... }
+	Layout func(title string) templ.Component
+	// Target selects the element of the layout holding the content.
+	// Boosted navigation swaps the content into it; without a target it
+	// replaces the whole body.
+	Target string
+	// Replace replaces the URL of the current history entry instead of
+	// pushing a new one, for content that follows every keystroke such as
+	// search results
+	Replace bool
+}
+
+// RenderPage renders page in the form the request asks for. Plain requests
+// and history restores get the complete document, so every URL can be
+// opened directly. htmx requests get only the content and its title, and
+// GET requests push their URL so the back button returns to them, or
+// replace the current one when the page asks for it.
+func RenderPage(w http.ResponseWriter, r *http.Request, status int, page Page) error {
+	header := w.Header()
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	// The same URL answers with a document or a fragment
+	header.Add("Vary", HeaderRequest)
+
+	if !IsHTMX(r) || HistoryRestore(r) {
+		w.WriteHeader(status)
+		ctx := templ.WithChildren(r.Context(), page.Content)
+		return page.Layout(page.Title).Render(ctx, w)
+	}
+
+	if r.Method == http.MethodGet {
+		if page.Replace {
+			ReplaceURL(w, r.URL.RequestURI())
+		} else {
+			PushURL(w, r.URL.RequestURI())
+		}
+	}
+	if Boosted(r) && page.Target != "" {
+		Retarget(w, page.Target)
+		Reswap(w, SwapBoosted)
+	}
+	w.WriteHeader(status)
+	// htmx sets the document title from a title element in the response
+	if page.Title != "" {
+		if _, err := io.WriteString(w, "<title>"+html.EscapeString(page.Title)+"</title>"); err != nil {
+			return err
+		}
+	}
+	return page.Content.Render(r.Context(), w)
+}
@@ -0,0 +1,58 @@
+// Package htmx provides server-side helpers for htmx requests and responses:
+// reading the HX-* request headers, setting the HX-* response headers and
+// combining several templ fragments into one out-of-band swap response.
+package htmx
+
+import "net/http"
+
+// Request headers sent by htmx
+const (
+	HeaderRequest        = "HX-Request"
+	HeaderBoosted        = "HX-Boosted"
+	HeaderCurrentURL     = "HX-Current-URL"
+	HeaderHistoryRestore = "HX-History-Restore-Request"
+	HeaderPrompt         = "HX-Prompt"
+	HeaderTarget         = "HX-Target"
+	HeaderTrigger        = "HX-Trigger"
+	HeaderTriggerName    = "HX-Trigger-Name"
+)
+
+// IsHTMX reports whether r was made by htmx
+func IsHTMX(r *http.Request) bool {
+	return r.Header.Get(HeaderRequest) == "true"
+}
+
+// Boosted reports whether r was made by an element using hx-boost
+func Boosted(r *http.Request) bool {
+	return r.Header.Get(HeaderBoosted) == "true"
+}
+
+// HistoryRestore reports whether r restores history after a cache miss
+func HistoryRestore(r *http.Request) bool {
+	return r.Header.Get(HeaderHistoryRestore) == "true"
+}
+
+// Target returns the id of the target element, or "" if it has no id
+func Target(r *http.Request) string {
+	return r.Header.Get(HeaderTarget)
+}
+
+// Trigger returns the id of the triggering element, or "" if it has no id
+func Trigger(r *http.Request) string {
+	return r.Header.Get(HeaderTrigger)
+}
+
+// TriggerName returns the name of the triggering element
+func TriggerName(r *http.Request) string {
+	return r.Header.Get(HeaderTriggerName)
+}
+
+// CurrentURL returns the URL of the page the request was made from
+func CurrentURL(r *http.Request) string {
+	return r.Header.Get(HeaderCurrentURL)
+}
+
+// Prompt returns the user's response to hx-prompt
+func Prompt(r *http.Request) string {
+	return r.Header.Get(HeaderPrompt)
+}
@@ -0,0 +1,213 @@
+package htmx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Response headers understood by htmx
+const (
+	HeaderLocation           = "HX-Location"
+	HeaderPushURL            = "HX-Push-Url"
+	HeaderRedirect           = "HX-Redirect"
+	HeaderRefresh            = "HX-Refresh"
+	HeaderReplaceURL         = "HX-Replace-Url"
+	HeaderReswap             = "HX-Reswap"
+	HeaderRetarget           = "HX-Retarget"
+	HeaderReselect           = "HX-Reselect"
+	HeaderTriggerAfterSwap   = "HX-Trigger-After-Swap"
+	HeaderTriggerAfterSettle = "HX-Trigger-After-Settle"
+)
+
+// Swap styles for hx-swap, HX-Reswap and hx-swap-oob
+const (
+	SwapInnerHTML   = "innerHTML"
+	SwapOuterHTML   = "outerHTML"
+	SwapBeforeBegin = "beforebegin"
+	SwapAfterBegin  = "afterbegin"
+	SwapBeforeEnd   = "beforeend"
+	SwapAfterEnd    = "afterend"
+	SwapDelete      = "delete"
+	SwapNone        = "none"
+)
+
+// TriggerEvent triggers a client-side event as soon as the response is
+// received. Events from repeated calls are combined.
+func TriggerEvent(w http.ResponseWriter, name string) {
+	if err := addTrigger(w.Header(), HeaderTrigger, name, nil); err != nil {
+		// The header was set by hand to invalid JSON; replace it
+		w.Header().Set(HeaderTrigger, name)
+	}
+}
+
+// TriggerEventWithDetail triggers a client-side event whose event.detail
+// is detail encoded as JSON
+func TriggerEventWithDetail(w http.ResponseWriter, name string, detail any) error {
+	return addTrigger(w.Header(), HeaderTrigger, name, detail)
+}
+
+// TriggerAfterSwap triggers a client-side event after the swap step.
+// detail may be nil.
+func TriggerAfterSwap(w http.ResponseWriter, name string, detail any) error {
+	return addTrigger(w.Header(), HeaderTriggerAfterSwap, name, detail)
+}
+
+// TriggerAfterSettle triggers a client-side event after the settle step.
+// detail may be nil.
+func TriggerAfterSettle(w http.ResponseWriter, name string, detail any) error {
+	return addTrigger(w.Header(), HeaderTriggerAfterSettle, name, detail)
+}
+
+// addTrigger merges an event into a trigger header. Events without detail
+// are sent as a comma separated list of names; once any event has detail
+// the header becomes a JSON object keyed by event name.
+func addTrigger(header http.Header, key, name string, detail any) error {
+	events, err := parseTriggers(header.Get(key))
+	if err != nil {
+		return err
+	}
+
+	var raw json.RawMessage
+	if detail != nil {
+		if raw, err = json.Marshal(detail); err != nil {
+			return fmt.Errorf("failed to encode detail of event %q: %w", name, err)
+		}
+	}
+
+	replaced := false
+	for i := range events {
+		if events[i].name == name {
+			events[i].detail = raw
+			replaced = true
+		}
+	}
+	if !replaced {
+		events = append(events, triggerEvent{name: name, detail: raw})
+	}
+
+	header.Set(key, formatTriggers(events))
+	return nil
+}
+
+type triggerEvent struct {
+	name   string
+	detail json.RawMessage
+}
+
+func parseTriggers(value string) ([]triggerEvent, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(value, "{") {
+		var events []triggerEvent
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				events = append(events, triggerEvent{name: name})
+			}
+		}
+		return events, nil
+	}
+
+	// Keep the header's order so repeated calls are deterministic
+	decoder := json.NewDecoder(strings.NewReader(value))
+	if _, err := decoder.Token(); err != nil {
+		return nil, fmt.Errorf("failed to parse trigger header: %w", err)
+	}
+	var events []triggerEvent
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trigger header: %w", err)
+		}
+		var detail json.RawMessage
+		if err := decoder.Decode(&detail); err != nil {
+			return nil, fmt.Errorf("failed to parse trigger header: %w", err)
+		}
+		if string(detail) == "null" {
+			detail = nil
+		}
+		events = append(events, triggerEvent{name: token.(string), detail: detail})
+	}
+	return events, nil
+}
+
+func formatTriggers(events []triggerEvent) string {
+	withDetail := false
+	for _, event := range events {
+		if event.detail != nil {
+			withDetail = true
+			break
+		}
+	}
+
+	if !withDetail {
+		names := make([]string, len(events))
+		for i, event := range events {
+			names[i] = event.name
+		}
+		return strings.Join(names, ", ")
+	}
+
+	var b strings.Builder
+	b.WriteString("{")
+	for i, event := range events {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		name, _ := json.Marshal(event.name)
+		b.Write(name)
+		b.WriteString(":")
+		if event.detail == nil {
+			b.WriteString("null")
+		} else {
+			b.Write(event.detail)
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// PushURL pushes url into the browser history
+func PushURL(w http.ResponseWriter, url string) {
+	w.Header().Set(HeaderPushURL, url)
+}
+
+// ReplaceURL replaces the current URL in the browser location bar
+func ReplaceURL(w http.ResponseWriter, url string) {
+	w.Header().Set(HeaderReplaceURL, url)
+}
+
+// Redirect makes the browser do a full page load of url
+func Redirect(w http.ResponseWriter, url string) {
+	w.Header().Set(HeaderRedirect, url)
+}
+
+// Location navigates to url without a full page reload, like hx-boost
+func Location(w http.ResponseWriter, url string) {
+	w.Header().Set(HeaderLocation, url)
+}
+
+// Refresh makes the browser reload the current page
+func Refresh(w http.ResponseWriter) {
+	w.Header().Set(HeaderRefresh, "true")
+}
+
+// Reswap overrides the swap style of the request, e.g. SwapInnerHTML
+func Reswap(w http.ResponseWriter, swap string) {
+	w.Header().Set(HeaderReswap, swap)
+}
+
+// Retarget swaps the response into the element matching selector instead
+// of the request's target
+func Retarget(w http.ResponseWriter, selector string) {
+	w.Header().Set(HeaderRetarget, selector)
+}
+
+// Reselect swaps only the part of the response matching selector
+func Reselect(w http.ResponseWriter, selector string) {
+	w.Header().Set(HeaderReselect, selector)
+}